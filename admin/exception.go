@@ -0,0 +1,39 @@
+package admin
+
+import (
+	"fmt"
+
+	. "github.com/gopi-frame/contract/exception"
+	"github.com/gopi-frame/exception"
+	"github.com/gopi-frame/logger"
+)
+
+type UnsupportedChannelException struct {
+	Throwable
+}
+
+func NewUnsupportedChannelException(channel string, feature string) *UnsupportedChannelException {
+	return &UnsupportedChannelException{
+		Throwable: exception.New(fmt.Sprintf("channel [%s] does not exist or does not support %s", channel, feature)),
+	}
+}
+
+type InvalidLevelException struct {
+	Throwable
+}
+
+func NewInvalidLevelException(level logger.Level) *InvalidLevelException {
+	return &InvalidLevelException{
+		Throwable: exception.New(fmt.Sprintf("invalid level [%s]", level)),
+	}
+}
+
+type LevelMissingException struct {
+	Throwable
+}
+
+func NewLevelMissingException() *LevelMissingException {
+	return &LevelMissingException{
+		Throwable: exception.New("level is missing"),
+	}
+}
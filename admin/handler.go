@@ -0,0 +1,299 @@
+// Package admin provides an [http.Handler] to view and change the levels of a [logger.LoggerManager] at runtime.
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gopi-frame/logger"
+)
+
+// ChannelLevel is the level state of a channel.
+// The levels of a channel which has not been opened yet are not reported, the channel is not opened to read them.
+type ChannelLevel struct {
+	Channel   string                  `json:"channel"`
+	Opened    bool                    `json:"opened"`
+	Level     *logger.Level           `json:"level,omitempty"`
+	ExpiresAt *time.Time              `json:"expiresAt,omitempty"`
+	Levels    map[string]logger.Level `json:"levels,omitempty"`
+}
+
+// NameLevel is the level state of a logger name in a channel.
+type NameLevel struct {
+	Channel   string       `json:"channel"`
+	Name      string       `json:"name"`
+	Level     logger.Level `json:"level"`
+	ExpiresAt *time.Time   `json:"expiresAt,omitempty"`
+}
+
+// LevelRequest is the body of a PUT request.
+// The level is required, one of [logger.Levels].
+// If TTL is given, the level reverts to the previous one after it expires.
+type LevelRequest struct {
+	Level logger.Level `json:"level"`
+	TTL   string       `json:"ttl,omitempty"`
+}
+
+// Handler is an [http.Handler] to view and change the levels of a [logger.LoggerManager].
+//
+// Routes are relative to the path the handler is mounted on:
+//
+//	GET    /                  lists all channels with their levels
+//	GET    /{channel}         returns the level of the channel
+//	PUT    /{channel}         changes the level of the channel
+//	GET    /{channel}/{name}  returns the effective level of a logger name
+//	PUT    /{channel}/{name}  changes the level of a logger name or pattern, e.g. "billing.*"
+//	DELETE /{channel}/{name}  removes the level of a logger name or pattern
+//
+// Use [http.StripPrefix] to mount the handler on a sub path.
+type Handler struct {
+	manager *logger.LoggerManager
+	mux     *http.ServeMux
+	now     func() time.Time // for testing
+
+	mu      sync.Mutex
+	pending map[target]*revert
+}
+
+type target struct {
+	channel string
+	name    string
+}
+
+// revert restores the level of a target when its TTL expires.
+type revert struct {
+	timer     *time.Timer
+	expiresAt time.Time
+	restore   func()
+}
+
+// NewHandler creates a new admin handler for the given manager.
+func NewHandler(manager *logger.LoggerManager) *Handler {
+	h := &Handler{
+		manager: manager,
+		mux:     http.NewServeMux(),
+		now:     time.Now,
+		pending: make(map[target]*revert),
+	}
+	h.mux.HandleFunc("GET /{$}", h.listChannels)
+	h.mux.HandleFunc("GET /{channel}", h.getChannel)
+	h.mux.HandleFunc("PUT /{channel}", h.putChannel)
+	h.mux.HandleFunc("GET /{channel}/{name}", h.getName)
+	h.mux.HandleFunc("PUT /{channel}/{name}", h.putName)
+	h.mux.HandleFunc("DELETE /{channel}/{name}", h.deleteName)
+	return h
+}
+
+// ServeHTTP implements [http.Handler].
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler) listChannels(w http.ResponseWriter, _ *http.Request) {
+	channels := h.manager.GetChannels()
+	names := make([]string, 0, len(channels))
+	for name := range channels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	list := make([]ChannelLevel, 0, len(names))
+	for _, name := range names {
+		list = append(list, h.channelLevel(name))
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (h *Handler) getChannel(w http.ResponseWriter, r *http.Request) {
+	channel := r.PathValue("channel")
+	if !h.manager.HasChannel(channel) {
+		writeError(w, http.StatusNotFound, logger.NewNotConfiguredChannelException(channel))
+		return
+	}
+	writeJSON(w, http.StatusOK, h.channelLevel(channel))
+}
+
+func (h *Handler) putChannel(w http.ResponseWriter, r *http.Request) {
+	channel := r.PathValue("channel")
+	level := h.manager.GetChannelLevel(channel)
+	if level == nil {
+		writeError(w, http.StatusNotFound, NewUnsupportedChannelException(channel, "runtime level"))
+		return
+	}
+	req, ttl, err := decodeLevelRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	previous := level.Level()
+	level.SetLevel(req.Level)
+	h.schedule(target{channel: channel}, ttl, func() {
+		level.SetLevel(previous)
+	})
+	writeJSON(w, http.StatusOK, h.channelLevel(channel))
+}
+
+func (h *Handler) getName(w http.ResponseWriter, r *http.Request) {
+	channel, name := r.PathValue("channel"), r.PathValue("name")
+	levels := h.manager.GetChannelNameLevels(channel)
+	if levels == nil {
+		writeError(w, http.StatusNotFound, NewUnsupportedChannelException(channel, "levels by logger name"))
+		return
+	}
+	level, ok := levels.Match(name)
+	if !ok {
+		atomicLevel := h.manager.GetChannelLevel(channel)
+		if atomicLevel == nil {
+			writeError(w, http.StatusNotFound, NewUnsupportedChannelException(channel, "runtime level"))
+			return
+		}
+		level = atomicLevel.Level()
+	}
+	writeJSON(w, http.StatusOK, h.nameLevel(channel, name, level))
+}
+
+func (h *Handler) putName(w http.ResponseWriter, r *http.Request) {
+	channel, name := r.PathValue("channel"), r.PathValue("name")
+	levels := h.manager.GetChannelNameLevels(channel)
+	if levels == nil {
+		writeError(w, http.StatusNotFound, NewUnsupportedChannelException(channel, "levels by logger name"))
+		return
+	}
+	req, ttl, err := decodeLevelRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	previous, existed := levels.Levels()[name]
+	levels.SetLevel(name, req.Level)
+	h.schedule(target{channel: channel, name: name}, ttl, func() {
+		if existed {
+			levels.SetLevel(name, previous)
+		} else {
+			levels.RemoveLevel(name)
+		}
+	})
+	writeJSON(w, http.StatusOK, h.nameLevel(channel, name, req.Level))
+}
+
+func (h *Handler) deleteName(w http.ResponseWriter, r *http.Request) {
+	channel, name := r.PathValue("channel"), r.PathValue("name")
+	levels := h.manager.GetChannelNameLevels(channel)
+	if levels == nil {
+		writeError(w, http.StatusNotFound, NewUnsupportedChannelException(channel, "levels by logger name"))
+		return
+	}
+	h.cancel(target{channel: channel, name: name})
+	levels.RemoveLevel(name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// schedule replaces the pending revert of t.
+// If ttl is zero, the change is permanent and the pending revert is dropped,
+// otherwise the level restored on expiry is the one before the first pending change.
+func (h *Handler) schedule(t target, ttl time.Duration, restore func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if p, ok := h.pending[t]; ok {
+		p.timer.Stop()
+		delete(h.pending, t)
+		restore = p.restore
+	}
+	if ttl <= 0 {
+		return
+	}
+	p := &revert{expiresAt: h.now().Add(ttl), restore: restore}
+	p.timer = time.AfterFunc(ttl, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if h.pending[t] != p {
+			return
+		}
+		delete(h.pending, t)
+		p.restore()
+	})
+	h.pending[t] = p
+}
+
+func (h *Handler) cancel(t target) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if p, ok := h.pending[t]; ok {
+		p.timer.Stop()
+		delete(h.pending, t)
+	}
+}
+
+func (h *Handler) expiresAt(t target) *time.Time {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if p, ok := h.pending[t]; ok {
+		expiresAt := p.expiresAt
+		return &expiresAt
+	}
+	return nil
+}
+
+func (h *Handler) channelLevel(channel string) ChannelLevel {
+	state := ChannelLevel{Channel: channel, Opened: true}
+	if l := h.manager.GetChannel(channel); !logger.Opened(l) {
+		state.Opened = false
+		return state
+	}
+	if level := h.manager.GetChannelLevel(channel); level != nil {
+		lvl := level.Level()
+		state.Level = &lvl
+		state.ExpiresAt = h.expiresAt(target{channel: channel})
+	}
+	if levels := h.manager.GetChannelNameLevels(channel); levels != nil {
+		state.Levels = levels.Levels()
+	}
+	return state
+}
+
+func (h *Handler) nameLevel(channel, name string, level logger.Level) NameLevel {
+	return NameLevel{
+		Channel:   channel,
+		Name:      name,
+		Level:     level,
+		ExpiresAt: h.expiresAt(target{channel: channel, name: name}),
+	}
+}
+
+func decodeLevelRequest(r *http.Request) (*LevelRequest, time.Duration, error) {
+	var body struct {
+		Level *logger.Level `json:"level"`
+		TTL   string        `json:"ttl"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return nil, 0, err
+	}
+	if body.Level == nil {
+		return nil, 0, NewLevelMissingException()
+	}
+	if !slices.Contains(logger.Levels(), *body.Level) {
+		return nil, 0, NewInvalidLevelException(*body.Level)
+	}
+	req := LevelRequest{Level: *body.Level, TTL: body.TTL}
+	if req.TTL == "" {
+		return &req, 0, nil
+	}
+	ttl, err := time.ParseDuration(req.TTL)
+	if err != nil {
+		return nil, 0, err
+	}
+	return &req, ttl, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,169 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	loggercontract "github.com/gopi-frame/contract/logger"
+	"github.com/gopi-frame/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockLevel struct {
+	level logger.Level
+}
+
+func (m *mockLevel) Level() logger.Level         { return m.level }
+func (m *mockLevel) SetLevel(level logger.Level) { m.level = level }
+
+type mockLogger struct {
+	loggercontract.Logger
+	level  *mockLevel
+	levels logger.NameLevels
+}
+
+func newMockLogger(level logger.Level) *mockLogger {
+	identity := func(level logger.Level) logger.Level { return level }
+	return &mockLogger{
+		level: &mockLevel{level: level},
+		levels: logger.NewNameLevels(logger.NewLevelRules(map[string]logger.Level{}), nil,
+			func(level logger.Level) (logger.Level, bool) { return level, true }, identity),
+	}
+}
+
+func (m *mockLogger) WithContext(context.Context) loggercontract.Logger { return m }
+func (m *mockLogger) AtomicLevel() logger.AtomicLevel                   { return m.level }
+func (m *mockLogger) NameLevels() logger.NameLevels                     { return m.levels }
+
+func request(t *testing.T, h http.Handler, method, path, body string, result any) int {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if result != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), result); err != nil {
+			assert.FailNow(t, err.Error(), rec.Body.String())
+		}
+	}
+	return rec.Code
+}
+
+func TestHandler(t *testing.T) {
+	app := newMockLogger(logger.LevelInfo)
+	m := logger.NewLoggerManager()
+	m.SetDefault("app")
+	m.SetChannel("app", app)
+	m.SetChannel("audit", newMockLogger(logger.LevelWarn))
+	h := NewHandler(m)
+
+	t.Run("list channels", func(t *testing.T) {
+		var list []ChannelLevel
+		assert.Equal(t, http.StatusOK, request(t, h, http.MethodGet, "/", "", &list))
+		if !assert.Len(t, list, 2) {
+			assert.FailNow(t, "unexpected channels")
+		}
+		assert.Equal(t, "app", list[0].Channel)
+		assert.True(t, list[0].Opened)
+		assert.Equal(t, logger.LevelInfo, *list[0].Level)
+		assert.Equal(t, "audit", list[1].Channel)
+		assert.Equal(t, logger.LevelWarn, *list[1].Level)
+	})
+
+	t.Run("channel", func(t *testing.T) {
+		var state ChannelLevel
+		assert.Equal(t, http.StatusOK, request(t, h, http.MethodPut, "/app", `{"level":"debug"}`, &state))
+		assert.Equal(t, logger.LevelDebug, *state.Level)
+		assert.Nil(t, state.ExpiresAt)
+		assert.Equal(t, logger.LevelDebug, app.level.Level())
+
+		assert.Equal(t, http.StatusOK, request(t, h, http.MethodGet, "/app", "", &state))
+		assert.Equal(t, logger.LevelDebug, *state.Level)
+
+		assert.Equal(t, http.StatusNotFound, request(t, h, http.MethodGet, "/missing", "", nil))
+		assert.Equal(t, http.StatusNotFound, request(t, h, http.MethodPut, "/missing", `{"level":"debug"}`, nil))
+		assert.Equal(t, http.StatusBadRequest, request(t, h, http.MethodPut, "/app", `{"level":"verbose"}`, nil))
+		assert.Equal(t, http.StatusBadRequest, request(t, h, http.MethodPut, "/app", `{"level":"info","ttl":"soon"}`, nil))
+		assert.Equal(t, http.StatusBadRequest, request(t, h, http.MethodPut, "/app", `{}`, nil))
+		assert.Equal(t, http.StatusBadRequest, request(t, h, http.MethodPut, "/app", `{"ttl":"5m"}`, nil))
+		assert.Equal(t, http.StatusBadRequest, request(t, h, http.MethodPut, "/app", `{"level":7}`, nil))
+		assert.Equal(t, http.StatusBadRequest, request(t, h, http.MethodPut, "/app/gorm", `{"level":-1}`, nil))
+		assert.Equal(t, logger.LevelDebug, app.level.Level())
+		assert.Empty(t, app.levels.Levels())
+		assert.Equal(t, http.StatusOK, request(t, h, http.MethodPut, "/app", `{"level":"1"}`, &state))
+		assert.Equal(t, logger.LevelInfo, *state.Level)
+		app.level.SetLevel(logger.LevelDebug)
+	})
+
+	t.Run("channel with ttl", func(t *testing.T) {
+		app.level.SetLevel(logger.LevelWarn)
+		var state ChannelLevel
+		assert.Equal(t, http.StatusOK, request(t, h, http.MethodPut, "/app", `{"level":"debug","ttl":"100ms"}`, &state))
+		assert.NotNil(t, state.ExpiresAt)
+		assert.Equal(t, http.StatusOK, request(t, h, http.MethodPut, "/app", `{"level":"info","ttl":"100ms"}`, &state))
+		assert.Equal(t, logger.LevelInfo, app.level.Level())
+		assert.Eventually(t, func() bool {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			return app.level.Level() == logger.LevelWarn
+		}, time.Second, 10*time.Millisecond)
+		var reverted ChannelLevel
+		assert.Equal(t, http.StatusOK, request(t, h, http.MethodGet, "/app", "", &reverted))
+		assert.Equal(t, logger.LevelWarn, *reverted.Level)
+		assert.Nil(t, reverted.ExpiresAt)
+	})
+
+	t.Run("name", func(t *testing.T) {
+		var state NameLevel
+		assert.Equal(t, http.StatusOK, request(t, h, http.MethodPut, "/app/billing.*", `{"level":"debug"}`, &state))
+		assert.Equal(t, logger.LevelDebug, state.Level)
+		assert.Equal(t, http.StatusOK, request(t, h, http.MethodGet, "/app/billing.invoice", "", &state))
+		assert.Equal(t, logger.LevelDebug, state.Level)
+		assert.Equal(t, http.StatusOK, request(t, h, http.MethodGet, "/app/http", "", &state))
+		assert.Equal(t, logger.LevelWarn, state.Level)
+
+		var channel ChannelLevel
+		assert.Equal(t, http.StatusOK, request(t, h, http.MethodGet, "/app", "", &channel))
+		assert.Equal(t, map[string]logger.Level{"billing.*": logger.LevelDebug}, channel.Levels)
+
+		assert.Equal(t, http.StatusNoContent, request(t, h, http.MethodDelete, "/app/billing.*", "", nil))
+		assert.Empty(t, app.levels.Levels())
+		assert.Equal(t, http.StatusNotFound, request(t, h, http.MethodDelete, "/missing/billing.*", "", nil))
+	})
+
+	t.Run("name with ttl", func(t *testing.T) {
+		app.levels.SetLevel("gorm", logger.LevelError)
+		assert.Equal(t, http.StatusOK, request(t, h, http.MethodPut, "/app/gorm", `{"level":"debug","ttl":"50ms"}`, nil))
+		assert.Equal(t, http.StatusOK, request(t, h, http.MethodPut, "/app/http", `{"level":"debug","ttl":"50ms"}`, nil))
+		assert.Eventually(t, func() bool {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			return len(h.pending) == 0
+		}, time.Second, 10*time.Millisecond)
+		assert.Equal(t, map[string]logger.Level{"gorm": logger.LevelError}, app.levels.Levels())
+	})
+
+	t.Run("unopened channel", func(t *testing.T) {
+		m := logger.NewLoggerManager()
+		m.SetChannel("app", newMockLogger(logger.LevelInfo))
+		// a channel with an unknown driver panics when it is opened
+		lazy := logger.NewDeferLogger("missing", nil)
+		m.SetChannel("lazy", lazy)
+		m.SetChannel("stack", logger.NewStackLogger(m.GetChannel("app"), lazy))
+		h := NewHandler(m)
+		var list []ChannelLevel
+		assert.Equal(t, http.StatusOK, request(t, h, http.MethodGet, "/", "", &list))
+		if assert.Len(t, list, 3) {
+			assert.True(t, list[0].Opened)
+			assert.Equal(t, ChannelLevel{Channel: "lazy"}, list[1])
+			assert.Equal(t, ChannelLevel{Channel: "stack"}, list[2])
+		}
+		var state ChannelLevel
+		assert.Equal(t, http.StatusOK, request(t, h, http.MethodGet, "/lazy", "", &state))
+		assert.False(t, state.Opened)
+		assert.False(t, lazy.Opened())
+	})
+}
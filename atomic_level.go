@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"github.com/gopi-frame/contract/logger"
+)
+
+// AtomicLevel is a level which can be changed at runtime.
+// A change takes effect immediately on every logger sharing the level.
+type AtomicLevel interface {
+	// Level returns the current level.
+	Level() Level
+
+	// SetLevel changes the level.
+	SetLevel(level Level)
+}
+
+// AtomicLevelLogger is a logger whose level can be changed at runtime.
+type AtomicLevelLogger interface {
+	logger.Logger
+
+	// AtomicLevel returns the level shared by the logger and the loggers derived from it
+	// by WithContext, With and Named.
+	AtomicLevel() AtomicLevel
+}
+
+// GetAtomicLevel returns the atomic level of l.
+// If l does not implement [AtomicLevelLogger], it returns nil.
+func GetAtomicLevel(l logger.Logger) AtomicLevel {
+	if al, ok := l.(AtomicLevelLogger); ok {
+		return al.AtomicLevel()
+	}
+	return nil
+}
+
+// stackLevel is the atomic level of a [StackLogger].
+// It changes the level of all channels, and reports the lowest level of them.
+type stackLevel []AtomicLevel
+
+func (s stackLevel) Level() Level {
+	level := LevelFatal
+	for _, l := range s {
+		if lvl := l.Level(); lvl < level {
+			level = lvl
+		}
+	}
+	return level
+}
+
+func (s stackLevel) SetLevel(level Level) {
+	for _, l := range s {
+		l.SetLevel(level)
+	}
+}
@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/gopi-frame/env"
+)
+
+// ManagerConfig is the declarative configuration of a [LoggerManager].
+type ManagerConfig struct {
+	Default  string                   `json:"default" yaml:"default" toml:"default"`
+	Channels map[string]ChannelConfig `json:"channels" yaml:"channels" toml:"channels"`
+}
+
+// ChannelConfig is the configuration of a single channel.
+type ChannelConfig struct {
+	Driver  string         `json:"driver" yaml:"driver" toml:"driver"`
+	Options map[string]any `json:"options" yaml:"options" toml:"options"`
+}
+
+func newDecoder(result any) (*mapstructure.Decoder, error) {
+	return mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           result,
+		WeaklyTypedInput: true,
+		MatchName: func(mapKey, fieldName string) bool {
+			return strings.EqualFold(mapKey, fieldName) || strings.EqualFold(fieldName, strings.ReplaceAll(mapKey, "_", ""))
+		},
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			env.ExpandStringWithEnvHookFunc(),
+			env.ExpandSliceWithEnvHookFunc(),
+			env.ExpandStringKeyMapWithEnvHookFunc(),
+			mapstructure.StringToBasicTypeHookFunc(),
+		),
+	})
+}
+
+// UnmarshalManagerConfig decodes the manager configuration.
+// Every channel is decoded and validated independently,
+// the options are checked by the drivers which implement [Validator],
+// the problems of all channels are joined into the returned error.
+func UnmarshalManagerConfig(config map[string]any) (*ManagerConfig, error) {
+	var raw struct {
+		Default  string
+		Channels map[string]any
+	}
+	decoder, err := newDecoder(&raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := decoder.Decode(config); err != nil {
+		return nil, err
+	}
+	cfg := &ManagerConfig{
+		Default:  raw.Default,
+		Channels: make(map[string]ChannelConfig, len(raw.Channels)),
+	}
+	var errs []error
+	for _, name := range sortedKeys(raw.Channels) {
+		channel, err := unmarshalChannelConfig(name, raw.Channels[name])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		cfg.Channels[name] = *channel
+	}
+	if cfg.Default != "" {
+		if _, ok := raw.Channels[cfg.Default]; !ok {
+			errs = append(errs, NewNotConfiguredChannelException(cfg.Default))
+		}
+	} else if len(raw.Channels) > 0 {
+		errs = append(errs, NewMissingDefaultChannelException())
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func unmarshalChannelConfig(name string, config any) (*ChannelConfig, error) {
+	if config == nil {
+		return nil, NewInvalidChannelException(name, "configuration is empty")
+	}
+	var channel ChannelConfig
+	decoder, err := newDecoder(&channel)
+	if err != nil {
+		return nil, err
+	}
+	if err := decoder.Decode(config); err != nil {
+		return nil, NewInvalidChannelException(name, err.Error())
+	}
+	if channel.Driver == "" {
+		return nil, NewInvalidChannelException(name, "driver is missing")
+	}
+	drivers.RLock()
+	driver, registered := drivers.Get(channel.Driver)
+	drivers.RUnlock()
+	if !registered {
+		return nil, NewInvalidChannelException(name, NewUnknownDriverException(channel.Driver).Error())
+	}
+	if validator, ok := driver.(Validator); ok {
+		if err := validator.Validate(channel.Options); err != nil {
+			return nil, NewInvalidChannelException(name, err.Error())
+		}
+	}
+	return &channel, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
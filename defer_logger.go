@@ -2,15 +2,23 @@ package logger
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
 
 	"github.com/gopi-frame/contract/logger"
 )
 
 // DeferLogger defer logger
+//
+// The underlying logger is opened once on the first use, even if it is used concurrently.
 type DeferLogger struct {
 	driver string
 	config map[string]any
+	mu     sync.Mutex
+	opened atomic.Bool
 
+	// Logger is the underlying logger, nil until it is opened.
+	// It is safe to read once [DeferLogger.Opened] returns true.
 	Logger logger.Logger
 }
 
@@ -22,15 +30,43 @@ func NewDeferLogger(driver string, config map[string]any) *DeferLogger {
 	}
 }
 
+// Opened reports whether the underlying logger has been opened.
+func (l *DeferLogger) Opened() bool {
+	return l.opened.Load()
+}
+
+// Opened reports whether l has been opened, without opening it.
+// A [DeferLogger] is opened on its first use, and a [StackLogger] is opened when all its channels are.
+// Other loggers are always opened.
+func Opened(l logger.Logger) bool {
+	switch l := l.(type) {
+	case *DeferLogger:
+		return l.Opened()
+	case *StackLogger:
+		for _, channel := range l.channels {
+			if !Opened(channel) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 func (l *DeferLogger) deferInit() {
-	if l.Logger != nil {
+	if l.opened.Load() {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.opened.Load() {
 		return
 	}
-	var err error
-	if l.Logger, err = Open(l.driver, l.config); err != nil {
+	opened, err := Open(l.driver, l.config)
+	if err != nil {
 		panic(err)
 	}
-	return
+	l.Logger = opened
+	l.opened.Store(true)
 }
 
 func (l *DeferLogger) WithLevel(level logger.Level) logger.Logger {
@@ -43,6 +79,67 @@ func (l *DeferLogger) WithContext(ctx context.Context) logger.Logger {
 	return l.Logger.WithContext(ctx)
 }
 
+func (l *DeferLogger) With(fields ...Field) logger.Logger {
+	l.deferInit()
+	return With(l.Logger, fields...)
+}
+
+func (l *DeferLogger) WithFields(fields map[string]any) logger.Logger {
+	l.deferInit()
+	return With(l.Logger, Fields(fields)...)
+}
+
+func (l *DeferLogger) Named(name string) logger.Logger {
+	l.deferInit()
+	return Named(l.Logger, name)
+}
+
+func (l *DeferLogger) AtomicLevel() AtomicLevel {
+	l.deferInit()
+	return GetAtomicLevel(l.Logger)
+}
+
+func (l *DeferLogger) NameLevels() NameLevels {
+	l.deferInit()
+	return GetNameLevels(l.Logger)
+}
+
+// Reopen reopens the handlers of the underlying logger.
+// It does nothing if the logger has not been opened yet.
+func (l *DeferLogger) Reopen() error {
+	if !l.opened.Load() {
+		return nil
+	}
+	return Reopen(l.Logger)
+}
+
+// Sync flushes the handlers of the underlying logger.
+// It does nothing if the logger has not been opened yet.
+func (l *DeferLogger) Sync() error {
+	if !l.opened.Load() {
+		return nil
+	}
+	return Sync(l.Logger)
+}
+
+// Close closes the handlers of the underlying logger.
+// It does nothing if the logger has not been opened yet.
+func (l *DeferLogger) Close() error {
+	if !l.opened.Load() {
+		return nil
+	}
+	return Close(l.Logger)
+}
+
+// Shutdown shuts down the underlying logger, waiting until ctx is done.
+// It does nothing if the logger has not been opened yet.
+func (l *DeferLogger) Shutdown(ctx context.Context) error {
+	if !l.opened.Load() {
+		return nil
+	}
+	return Shutdown(ctx, l.Logger)
+}
+
 func (l *DeferLogger) Debug(message string) {
 	l.deferInit()
 	l.Logger.Debug(message)
@@ -13,6 +13,12 @@ import (
 
 var drivers = kv.NewMap[string, logger.Driver]()
 
+// Validator is implemented by the drivers which can check their options without opening a logger.
+// [UnmarshalManagerConfig] reports the errors of every channel whose driver is a Validator.
+type Validator interface {
+	Validate(options map[string]any) error
+}
+
 // Register registers a new logger driver.
 // If a driver with the same name already exists, it panics.
 func Register(driverName string, driver logger.Driver) {
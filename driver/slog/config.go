@@ -12,13 +12,15 @@ import (
 
 // Config is the configuration for the [Logger].
 type Config struct {
-	Level        Level          `json:"level" yaml:"level" toml:"level"`
-	Fields       map[string]any `json:"fields" yaml:"fields" toml:"fields"`
-	Encoder      string         `json:"encoder" yaml:"encoder" toml:"encoder"`
-	AddSource    bool           `json:"addSource" yaml:"addSource" toml:"addSource"`
-	PanicOnFatal bool           `json:"panicOnFatal" yaml:"panicOnFatal" toml:"panicOnFatal"`
-	Handler      string         `json:"handler" yaml:"handler" toml:"handler"`
-	HandlerWith  map[string]any `json:"handlerWith" yaml:"handlerWith" toml:"handlerWith"`
+	Level        Level            `json:"level" yaml:"level" toml:"level"`
+	Fields       map[string]any   `json:"fields" yaml:"fields" toml:"fields"`
+	Encoder      string           `json:"encoder" yaml:"encoder" toml:"encoder"`
+	AddSource    bool             `json:"addSource" yaml:"addSource" toml:"addSource"`
+	PanicOnFatal bool             `json:"panicOnFatal" yaml:"panicOnFatal" toml:"panicOnFatal"`
+	NameKey      string           `json:"nameKey" yaml:"nameKey" toml:"nameKey"`
+	Handler      string           `json:"handler" yaml:"handler" toml:"handler"`
+	HandlerWith  map[string]any   `json:"handlerWith" yaml:"handlerWith" toml:"handlerWith"`
+	Levels       map[string]Level `json:"levels" yaml:"levels" toml:"levels"`
 }
 
 // NewConfig creates a new [Config] instance with default values.
@@ -27,6 +29,7 @@ func NewConfig() *Config {
 		Level:   Level{slog.LevelDebug},
 		Fields:  make(map[string]any),
 		Encoder: EncoderJSON,
+		NameKey: DefaultNameKey,
 	}
 }
 
@@ -60,13 +63,16 @@ func (c *Config) SlogHandler() (slog.Handler, error) {
 	} else {
 		w = os.Stdout
 	}
+	writer := logger.NewSharedHandler(w)
 	if c.Encoder == EncoderText {
-		h = slog.NewTextHandler(w, opts)
+		h = slog.NewTextHandler(writer, opts)
 	} else {
-		h = slog.NewJSONHandler(w, opts)
+		h = slog.NewJSONHandler(writer, opts)
 	}
 	return &handler{
 		handler: h,
+		nameKey: c.NameKey,
+		writer:  writer,
 	}, nil
 }
 
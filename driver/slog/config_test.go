@@ -32,6 +32,19 @@ func TestPanicOnFatal(t *testing.T) {
 	assert.True(t, cfg.PanicOnFatal)
 }
 
+func TestNameKey(t *testing.T) {
+	cfg := NewConfig()
+	assert.Equal(t, DefaultNameKey, cfg.NameKey)
+	if err := cfg.Apply(WithNameKey("logger")); err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	assert.Equal(t, "logger", cfg.NameKey)
+	if err := cfg.Apply(WithNameKey("")); err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	assert.Equal(t, "logger", cfg.NameKey)
+}
+
 func TestUnmarshalOptions(t *testing.T) {
 	var options = map[string]any{
 		"level": "panic",
@@ -42,3 +55,9 @@ func TestUnmarshalOptions(t *testing.T) {
 	}
 	assert.Equal(t, LevelPanic, cfg.Level.Level)
 }
+
+func TestDriver_Validate(t *testing.T) {
+	assert.NoError(t, Driver{}.Validate(map[string]any{"level": "info"}))
+	assert.Error(t, Driver{}.Validate(map[string]any{"level": "verbose"}))
+	assert.ErrorContains(t, Driver{}.Validate(map[string]any{"handler": "unknown"}), "unknown handler [unknown]")
+}
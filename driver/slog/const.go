@@ -4,3 +4,8 @@ const (
 	EncoderJSON = "json"
 	EncoderText = "text"
 )
+
+const (
+	// DefaultNameKey is the default key of the logger name, which is the same as the zap driver.
+	DefaultNameKey = "name"
+)
@@ -26,3 +26,15 @@ func (d Driver) Open(options map[string]any) (loggercontract.Logger, error) {
 	}
 	return NewLogger(cfg)
 }
+
+// Validate checks the options and the config of the handler without opening a logger.
+func (d Driver) Validate(options map[string]any) error {
+	cfg, err := UnmarshalOptions(options)
+	if err != nil {
+		return err
+	}
+	if cfg.Handler != "" {
+		return logger.ValidateHandler(cfg.Handler, cfg.HandlerWith)
+	}
+	return nil
+}
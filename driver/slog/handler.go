@@ -3,6 +3,8 @@ package slog
 import (
 	"context"
 	"log/slog"
+
+	"github.com/gopi-frame/logger"
 )
 
 var levelKey = struct {
@@ -13,6 +15,22 @@ var levelKey = struct {
 
 type handler struct {
 	handler slog.Handler
+	nameKey string
+	name    string
+	// writer is the handler created from [Config.Handler], shared by all derived handlers.
+	writer *logger.SharedHandler
+}
+
+func (h *handler) named(name string) *handler {
+	if h.name != "" {
+		name = h.name + "." + name
+	}
+	return &handler{
+		handler: h.handler,
+		nameKey: h.nameKey,
+		name:    name,
+		writer:  h.writer,
+	}
 }
 
 func (h *handler) Enabled(ctx context.Context, level slog.Level) bool {
@@ -20,21 +38,31 @@ func (h *handler) Enabled(ctx context.Context, level slog.Level) bool {
 	if dynamicLevel == nil {
 		return h.handler.Enabled(ctx, level)
 	}
-	return level >= dynamicLevel.(slog.Level)
+	return level >= dynamicLevel.(slog.Leveler).Level()
 }
 
 func (h *handler) Handle(ctx context.Context, record slog.Record) error {
+	if h.name != "" && h.nameKey != "" {
+		record = record.Clone()
+		record.AddAttrs(slog.String(h.nameKey, h.name))
+	}
 	return h.handler.Handle(ctx, record)
 }
 
 func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
 	return &handler{
 		handler: h.handler.WithAttrs(attrs),
+		nameKey: h.nameKey,
+		name:    h.name,
+		writer:  h.writer,
 	}
 }
 
 func (h *handler) WithGroup(name string) slog.Handler {
 	return &handler{
 		handler: h.handler.WithGroup(name),
+		nameKey: h.nameKey,
+		name:    h.name,
+		writer:  h.writer,
 	}
 }
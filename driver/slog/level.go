@@ -5,6 +5,8 @@ import (
 	"log/slog"
 	"strconv"
 	"strings"
+
+	"github.com/gopi-frame/logger"
 )
 
 // Extra logger levels
@@ -60,3 +62,64 @@ func (l *Level) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	}
 	return l.UnmarshalText([]byte(s))
 }
+
+// nameLeveler resolves the level of a named logger from the level rules,
+// it falls back to the level of the logger if no rule matches the name or the level changed after the rule,
+// see [logger.NameLevel].
+type nameLeveler struct {
+	rule     *logger.NameLevel[slog.Level]
+	fallback slog.Leveler
+}
+
+func (l *nameLeveler) Level() slog.Level {
+	if level, ok := l.rule.Level(); ok {
+		return level
+	}
+	return l.fallback.Level()
+}
+
+// atomicLevel adapts [slog.LevelVar] to [logger.AtomicLevel],
+// it is shared by all loggers derived by WithContext, With and Named.
+type atomicLevel struct {
+	level slog.LevelVar
+	stamp logger.LevelStamp
+}
+
+func newAtomicLevel(level slog.Level) *atomicLevel {
+	a := new(atomicLevel)
+	a.level.Set(level)
+	return a
+}
+
+func (a *atomicLevel) Level() logger.Level {
+	return fromSlogLevel(a.level.Level())
+}
+
+func (a *atomicLevel) SetLevel(level logger.Level) {
+	if lvl, ok := toSlogLevel(level); ok {
+		a.level.Set(lvl)
+		a.stamp.Touch()
+	}
+}
+
+func toSlogLevel(level logger.Level) (slog.Level, bool) {
+	lvl, ok := levelMap[level]
+	return lvl, ok
+}
+
+func fromSlogLevel(level slog.Level) logger.Level {
+	switch {
+	case level < slog.LevelInfo:
+		return logger.LevelDebug
+	case level < slog.LevelWarn:
+		return logger.LevelInfo
+	case level < slog.LevelError:
+		return logger.LevelWarn
+	case level < LevelPanic:
+		return logger.LevelError
+	case level < LevelFatal:
+		return logger.LevelPanic
+	default:
+		return logger.LevelFatal
+	}
+}
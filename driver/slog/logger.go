@@ -23,8 +23,14 @@ var levelMap = map[loggercontract.Level]slog.Level{
 type Logger struct {
 	*slog.Logger
 
-	ctx          context.Context
-	level        slog.Leveler
+	ctx context.Context
+	// level is the level of the logger when no level rule matches its name or it changed after the rule,
+	// it is shared by all loggers derived by WithContext, With and Named.
+	level *atomicLevel
+	// leveler is the effective level of the logger, which is carried by ctx.
+	leveler slog.Leveler
+	// levels is the level rule table shared by all loggers derived from the same root.
+	levels       *logger.LevelRules[slog.Level]
 	panicOnFatal bool
 }
 
@@ -45,36 +51,139 @@ func NewLogger(cfg *Config) (*Logger, error) {
 		}
 		l = l.With(args...)
 	}
+	levels := make(map[string]slog.Level, len(cfg.Levels))
+	for pattern, level := range cfg.Levels {
+		levels[pattern] = level.Level
+	}
+	rules := logger.NewLevelRules(levels)
+	level := newAtomicLevel(cfg.Level.Level)
+	leveler := &nameLeveler{rule: logger.NewNameLevel(rules, "", &level.stamp), fallback: &level.level}
 	return &Logger{
 		Logger:       l,
-		level:        cfg.Level.Level,
-		ctx:          context.WithValue(context.Background(), levelKey, cfg.Level.Level),
+		level:        level,
+		leveler:      leveler,
+		levels:       rules,
+		ctx:          context.WithValue(context.Background(), levelKey, leveler),
 		panicOnFatal: cfg.PanicOnFatal,
 	}, nil
 }
 
+// WithLevel returns a new logger with the specified level.
+// The returned logger owns a new atomic level, so it is not affected by the level changes of l.
+// The level rules are not applied to the returned logger, but to its named children.
 func (l *Logger) WithLevel(level loggercontract.Level) loggercontract.Logger {
-	var lvl = l.level
+	var lvl = newAtomicLevel(l.level.level.Level())
 	if value, ok := levelMap[level]; ok {
-		lvl = value
+		lvl.level.Set(value)
 	}
 	return &Logger{
 		Logger:       l.Logger,
 		level:        lvl,
-		ctx:          context.WithValue(l.ctx, levelKey, lvl),
+		leveler:      &lvl.level,
+		levels:       l.levels,
+		ctx:          context.WithValue(l.ctx, levelKey, &lvl.level),
 		panicOnFatal: l.panicOnFatal,
 	}
 }
 
+// AtomicLevel returns the level shared by the logger and the loggers derived from it.
+func (l *Logger) AtomicLevel() logger.AtomicLevel {
+	return l.level
+}
+
+// NameLevels returns the level rule table by logger name shared by all loggers derived from the same root.
+func (l *Logger) NameLevels() logger.NameLevels {
+	return logger.NewNameLevels(l.levels, &l.level.stamp, toSlogLevel, fromSlogLevel)
+}
+
 func (l *Logger) WithContext(ctx context.Context) loggercontract.Logger {
 	return &Logger{
 		Logger:       l.Logger,
 		level:        l.level,
-		ctx:          context.WithValue(ctx, levelKey, l.level),
+		leveler:      l.leveler,
+		levels:       l.levels,
+		ctx:          context.WithValue(ctx, levelKey, l.leveler),
 		panicOnFatal: l.panicOnFatal,
 	}
 }
 
+// With returns a new logger with the given fields attached.
+// The fields are encoded as native slog attributes.
+func (l *Logger) With(fields ...logger.Field) loggercontract.Logger {
+	args := make([]any, 0, len(fields))
+	for _, field := range fields {
+		args = append(args, slog.Any(field.Key, field.Value))
+	}
+	return &Logger{
+		Logger:       l.Logger.With(args...),
+		level:        l.level,
+		leveler:      l.leveler,
+		levels:       l.levels,
+		ctx:          l.ctx,
+		panicOnFatal: l.panicOnFatal,
+	}
+}
+
+// WithFields returns a new logger with the given fields attached.
+func (l *Logger) WithFields(fields map[string]any) loggercontract.Logger {
+	return l.With(logger.Fields(fields)...)
+}
+
+// Named returns a new logger with the given name appended to the logger's name.
+// The name is emitted under [Config.NameKey], names are joined by dots.
+// The level of the returned logger is resolved from [Config.Levels] by its full name.
+func (l *Logger) Named(name string) loggercontract.Logger {
+	h, ok := l.Logger.Handler().(*handler)
+	if name == "" || !ok {
+		return l
+	}
+	h = h.named(name)
+	leveler := &nameLeveler{rule: logger.NewNameLevel(l.levels, h.name, &l.level.stamp), fallback: &l.level.level}
+	return &Logger{
+		Logger:       slog.New(h),
+		level:        l.level,
+		leveler:      leveler,
+		levels:       l.levels,
+		ctx:          context.WithValue(l.ctx, levelKey, leveler),
+		panicOnFatal: l.panicOnFatal,
+	}
+}
+
+// Reopen reopens the handler created from [Config.Handler] if it implements [logger.Reopener].
+func (l *Logger) Reopen() error {
+	if h, ok := l.Logger.Handler().(*handler); ok {
+		return h.writer.Reopen()
+	}
+	return nil
+}
+
+// Sync flushes the handler created from [Config.Handler].
+func (l *Logger) Sync() error {
+	if h, ok := l.Logger.Handler().(*handler); ok {
+		return h.writer.Sync()
+	}
+	return nil
+}
+
+// Close flushes and closes the handler created from [Config.Handler].
+// The handler is shared by all loggers derived from the same root, so they are all closed.
+// The standard output is not closed.
+func (l *Logger) Close() error {
+	if h, ok := l.Logger.Handler().(*handler); ok {
+		return h.writer.Close()
+	}
+	return nil
+}
+
+// Shutdown flushes and closes the handler created from [Config.Handler] as Close does,
+// waiting for buffered and in-flight writes until ctx is done.
+func (l *Logger) Shutdown(ctx context.Context) error {
+	if h, ok := l.Logger.Handler().(*handler); ok {
+		return h.writer.Shutdown(ctx)
+	}
+	return nil
+}
+
 // Debug logs a message at [slog.LevelDebug].
 func (l *Logger) Debug(message string) {
 	var values []any
@@ -40,8 +40,8 @@ func TestLogger(t *testing.T) {
 
 	t.Run("with handler", func(t *testing.T) {
 		var buffer = new(bufferHandler)
-		logger.RegisterHandler("buffer", func(config map[string]any) io.WriteCloser {
-			return buffer
+		logger.RegisterHandler("buffer", func(config map[string]any) (io.WriteCloser, error) {
+			return buffer, nil
 		})
 		var options = map[string]any{
 			"level":        "info",
@@ -232,5 +232,220 @@ func TestLogger(t *testing.T) {
 			l.Debug("debug")
 			assert.Equal(t, "", buffer.String())
 		})
+
+		t.Run("with fields", func(t *testing.T) {
+			buffer.Reset()
+			fl := l.(logger.FieldLogger).With(logger.Any("user", "alice"), logger.Any("id", 1))
+			fl.(logger.FieldLogger).WithFields(map[string]any{"order": "A1"}).Warn("warn")
+			var data map[string]any
+			if err := json.Unmarshal(buffer.Bytes(), &data); err != nil {
+				assert.FailNow(t, err.Error())
+			}
+			assert.Equal(t, "warn", data[slog.MessageKey])
+			assert.Equal(t, "alice", data["user"])
+			assert.Equal(t, float64(1), data["id"])
+			assert.Equal(t, "A1", data["order"])
+			assert.Nil(t, data["context"])
+
+			buffer.Reset()
+			fl.WithLevel(logger.LevelDebug).Debug("debug")
+			data = nil
+			if err := json.Unmarshal(buffer.Bytes(), &data); err != nil {
+				assert.FailNow(t, err.Error())
+			}
+			assert.Equal(t, "debug", data[slog.MessageKey])
+			assert.Equal(t, "alice", data["user"])
+			assert.Nil(t, data["order"])
+
+			buffer.Reset()
+			l.Warn("warn")
+			data = nil
+			if err := json.Unmarshal(buffer.Bytes(), &data); err != nil {
+				assert.FailNow(t, err.Error())
+			}
+			assert.Nil(t, data["user"])
+		})
+
+		t.Run("named", func(t *testing.T) {
+			buffer.Reset()
+			named := l.(logger.NamedLogger).Named("billing").(logger.NamedLogger).Named("invoice")
+			named.(logger.FieldLogger).With(logger.Any("user", "alice")).Warn("warn")
+			var data map[string]any
+			if err := json.Unmarshal(buffer.Bytes(), &data); err != nil {
+				assert.FailNow(t, err.Error())
+			}
+			assert.Equal(t, "billing.invoice", data[DefaultNameKey])
+			assert.Equal(t, "alice", data["user"])
+
+			buffer.Reset()
+			named.WithLevel(logger.LevelDebug).Debug("debug")
+			data = nil
+			if err := json.Unmarshal(buffer.Bytes(), &data); err != nil {
+				assert.FailNow(t, err.Error())
+			}
+			assert.Equal(t, "billing.invoice", data[DefaultNameKey])
+
+			buffer.Reset()
+			l.Warn("warn")
+			data = nil
+			if err := json.Unmarshal(buffer.Bytes(), &data); err != nil {
+				assert.FailNow(t, err.Error())
+			}
+			assert.Nil(t, data[DefaultNameKey])
+		})
 	})
 }
+
+func TestLogger_Levels(t *testing.T) {
+	var buffer = new(bufferHandler)
+	logger.RegisterHandler("levelsBuffer", func(config map[string]any) (io.WriteCloser, error) {
+		return buffer, nil
+	})
+	l, err := new(Driver).Open(map[string]any{
+		"level":   "info",
+		"handler": "levelsBuffer",
+		"levels": map[string]any{
+			"*":         "warn",
+			"billing.*": "debug",
+			"gorm":      "error",
+		},
+	})
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	named := func(name string) loggercontract.Logger {
+		return l.(logger.NamedLogger).Named(name)
+	}
+
+	l.Info("info")
+	assert.Equal(t, "", buffer.String())
+	l.WithContext(context.Background()).Warn("warn")
+	assert.NotZero(t, buffer.Len())
+
+	buffer.Reset()
+	named("billing").(logger.NamedLogger).Named("invoice").Debug("debug")
+	assert.Contains(t, buffer.String(), `"name":"billing.invoice"`)
+
+	buffer.Reset()
+	named("gorm").Warn("warn")
+	assert.Equal(t, "", buffer.String())
+	named("gorm").Error("error")
+	assert.NotZero(t, buffer.Len())
+
+	buffer.Reset()
+	named("gorm").WithLevel(logger.LevelDebug).Debug("debug")
+	assert.NotZero(t, buffer.Len())
+
+	buffer.Reset()
+	http := named("http")
+	http.Info("info")
+	assert.Equal(t, "", buffer.String())
+	levels := logger.GetNameLevels(l)
+	levels.SetLevel("http", logger.LevelInfo)
+	assert.Equal(t, logger.LevelInfo, levels.Levels()["http"])
+	http.Info("info")
+	assert.NotZero(t, buffer.Len())
+	buffer.Reset()
+	levels.RemoveLevel("http")
+	http.Info("info")
+	assert.Equal(t, "", buffer.String())
+
+	// the atomic level set after the rules overrides them, a rule set later overrides it in turn
+	logger.GetAtomicLevel(l).SetLevel(logger.LevelDebug)
+	_, ok := levels.Match("http")
+	assert.False(t, ok)
+	http.Debug("debug")
+	assert.NotZero(t, buffer.Len())
+	buffer.Reset()
+	named("gorm").Debug("debug")
+	assert.NotZero(t, buffer.Len())
+	buffer.Reset()
+	levels.SetLevel("gorm", logger.LevelError)
+	level, ok := levels.Match("gorm")
+	assert.True(t, ok)
+	assert.Equal(t, logger.LevelError, level)
+	named("gorm").Warn("warn")
+	assert.Equal(t, "", buffer.String())
+	http.Debug("debug")
+	assert.NotZero(t, buffer.Len())
+}
+
+func TestLogger_AtomicLevel(t *testing.T) {
+	var buffer = new(bufferHandler)
+	logger.RegisterHandler("atomicLevelBuffer", func(config map[string]any) (io.WriteCloser, error) {
+		return buffer, nil
+	})
+	cfg := NewConfig()
+	cfg.Handler = "atomicLevelBuffer"
+	if err := cfg.Apply(WithLevel(slog.LevelInfo)); err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	l, err := NewLogger(cfg)
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	child := l.With(logger.Any("key", "value")).(logger.NamedLogger).Named("child").WithContext(context.Background())
+	level := l.AtomicLevel()
+	assert.Equal(t, logger.LevelInfo, level.Level())
+
+	child.Debug("debug")
+	assert.Equal(t, "", buffer.String())
+
+	level.SetLevel(logger.LevelDebug)
+	assert.Equal(t, logger.LevelDebug, level.Level())
+	child.Debug("debug")
+	assert.NotZero(t, buffer.Len())
+
+	buffer.Reset()
+	level.SetLevel(logger.LevelError)
+	l.Warn("warn")
+	child.Warn("warn")
+	assert.Equal(t, "", buffer.String())
+
+	detached := l.WithLevel(logger.LevelInfo)
+	level.SetLevel(logger.LevelFatal)
+	detached.Info("info")
+	assert.NotZero(t, buffer.Len())
+	assert.Equal(t, logger.LevelInfo, logger.GetAtomicLevel(detached).Level())
+}
+
+type closeHandler struct {
+	bufferHandler
+	synced int
+	closed int
+}
+
+func (h *closeHandler) Sync() error {
+	h.synced++
+	return nil
+}
+
+func (h *closeHandler) Close() error {
+	h.closed++
+	return nil
+}
+
+func TestLogger_Close(t *testing.T) {
+	var handler = new(closeHandler)
+	logger.RegisterHandler("closeBuffer", func(config map[string]any) (io.WriteCloser, error) {
+		return handler, nil
+	})
+	cfg := NewConfig()
+	cfg.Handler = "closeBuffer"
+	l, err := NewLogger(cfg)
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	child := l.With(logger.Any("key", "value")).(logger.NamedLogger).Named("child")
+	child.Info("info")
+	assert.NotZero(t, handler.Len())
+
+	assert.NoError(t, logger.Sync(child))
+	assert.Equal(t, 1, handler.synced)
+
+	assert.NoError(t, logger.Close(child))
+	assert.NoError(t, l.Close())
+	assert.NoError(t, logger.Close(l.WithLevel(logger.LevelDebug)))
+	assert.NoError(t, logger.Shutdown(context.Background(), child))
+	assert.Equal(t, 1, handler.closed)
+}
@@ -23,6 +23,18 @@ func WithLevel(level slog.Level) Option {
 	}
 }
 
+// WithLevels sets the level rule table by logger name.
+// For example, {"*": LevelWarn, "billing.*": LevelDebug} logs debug messages of "billing" and its descendants only.
+func WithLevels(levels map[string]slog.Level) Option {
+	return func(cfg *Config) error {
+		cfg.Levels = make(map[string]Level, len(levels))
+		for pattern, level := range levels {
+			cfg.Levels[pattern] = Level{level}
+		}
+		return nil
+	}
+}
+
 // AddSource adds source to the log message.
 func AddSource() Option {
 	return func(cfg *Config) error {
@@ -46,3 +58,15 @@ func PanicOnFatal() Option {
 		return nil
 	}
 }
+
+// WithNameKey sets the key of the logger name.
+// If empty string is given, it does nothing.
+func WithNameKey(nameKey string) Option {
+	return func(cfg *Config) error {
+		if nameKey == "" {
+			return nil
+		}
+		cfg.NameKey = nameKey
+		return nil
+	}
+}
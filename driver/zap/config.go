@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/gopi-frame/env"
 	"github.com/gopi-frame/logger"
+	"io"
 	"os"
 	"reflect"
 	"strings"
@@ -16,17 +17,21 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
+// DefaultWriter is the writer used when no handler is configured.
+var DefaultWriter zapcore.WriteSyncer = os.Stdout
+
 // Config is the configuration for zap logger.
 type Config struct {
-	Level         zapcore.Level         `json:"level" yaml:"level" toml:"level"`
-	Development   bool                  `json:"development" yaml:"development" toml:"development"`
-	Fields        map[string]any        `json:"fields" yaml:"fields" toml:"fields"`
-	Caller        bool                  `json:"caller" yaml:"caller" toml:"caller"`
-	CallerSkip    int                   `json:"callerSkip" yaml:"callerSkip" toml:"callerSkip"`
-	Encoder       string                `json:"encoder" yaml:"encoder" toml:"encoder"`
-	EncoderConfig zapcore.EncoderConfig `json:"encoderConfig" yaml:"encoderConfig" toml:"encoderConfig"`
-	Handler       string                `json:"handler" yaml:"handler" toml:"handler"`
-	HandlerWith   map[string]any        `json:"handlerWith" yaml:"handlerWith" toml:"handlerWith"`
+	Level         zapcore.Level            `json:"level" yaml:"level" toml:"level"`
+	Development   bool                     `json:"development" yaml:"development" toml:"development"`
+	Fields        map[string]any           `json:"fields" yaml:"fields" toml:"fields"`
+	Caller        bool                     `json:"caller" yaml:"caller" toml:"caller"`
+	CallerSkip    int                      `json:"callerSkip" yaml:"callerSkip" toml:"callerSkip"`
+	Encoder       string                   `json:"encoder" yaml:"encoder" toml:"encoder"`
+	EncoderConfig zapcore.EncoderConfig    `json:"encoderConfig" yaml:"encoderConfig" toml:"encoderConfig"`
+	Handler       string                   `json:"handler" yaml:"handler" toml:"handler"`
+	HandlerWith   map[string]any           `json:"handlerWith" yaml:"handlerWith" toml:"handlerWith"`
+	Levels        map[string]zapcore.Level `json:"levels" yaml:"levels" toml:"levels"`
 
 	Hooks         []func(zapcore.Entry) error `json:"-" yaml:"-" toml:"-"`
 	Stacktrace    zapcore.LevelEnabler        `json:"-" yaml:"-" toml:"-"`
@@ -72,16 +77,22 @@ func (cfg *Config) ZapEncoder() (zapcore.Encoder, error) {
 	return encoder, nil
 }
 
+// ZapHandler returns the handler created from [Config.Handler],
+// or [DefaultWriter] if no handler is configured.
+func (cfg *Config) ZapHandler() (io.Writer, error) {
+	if cfg.Handler != "" {
+		return logger.CreateHandler(cfg.Handler, cfg.HandlerWith)
+	}
+	return DefaultWriter, nil
+}
+
 // ZapWriters returns the zap writers.
 func (cfg *Config) ZapWriters() (zapcore.WriteSyncer, error) {
-	if cfg.Handler != "" {
-		handler, err := logger.CreateHandler(cfg.Handler, cfg.HandlerWith)
-		if err != nil {
-			return nil, err
-		}
-		return zapcore.Lock(zapcore.AddSync(handler)), nil
+	handler, err := cfg.ZapHandler()
+	if err != nil {
+		return nil, err
 	}
-	return zapcore.Lock(zapcore.AddSync(os.Stdout)), nil
+	return zapcore.Lock(zapcore.AddSync(handler)), nil
 }
 
 // ZapOptions returns the zap options.
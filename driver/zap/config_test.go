@@ -679,3 +679,10 @@ func TestConfig_Apply(t *testing.T) {
 		assert.Equal(t, zapcore.DefaultClock, config.Clock)
 	})
 }
+
+func TestDriver_Validate(t *testing.T) {
+	driver := new(Driver)
+	assert.NoError(t, driver.Validate(map[string]any{"level": "info"}))
+	assert.Error(t, driver.Validate(map[string]any{"level": "verbose"}))
+	assert.ErrorContains(t, driver.Validate(map[string]any{"handler": "unknown"}), "unknown handler [unknown]")
+}
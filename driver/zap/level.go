@@ -0,0 +1,66 @@
+package zap
+
+import (
+	"github.com/gopi-frame/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// nameLevelEnabler resolves the level of a named logger from the level rules,
+// it falls back to the level of the logger if no rule matches the name or the level changed after the rule,
+// see [logger.NameLevel].
+type nameLevelEnabler struct {
+	rule     *logger.NameLevel[zapcore.Level]
+	fallback zapcore.LevelEnabler
+}
+
+func (e *nameLevelEnabler) Enabled(level zapcore.Level) bool {
+	if lvl, ok := e.rule.Level(); ok {
+		return lvl.Enabled(level)
+	}
+	return e.fallback.Enabled(level)
+}
+
+// atomicLevel adapts [zap.AtomicLevel] to [logger.AtomicLevel],
+// it is shared by all loggers derived by WithContext, With and Named.
+type atomicLevel struct {
+	level zap.AtomicLevel
+	stamp logger.LevelStamp
+}
+
+func newAtomicLevel(level zapcore.Level) *atomicLevel {
+	return &atomicLevel{level: zap.NewAtomicLevelAt(level)}
+}
+
+func (a *atomicLevel) Level() logger.Level {
+	return fromZapLevel(a.level.Level())
+}
+
+func (a *atomicLevel) SetLevel(level logger.Level) {
+	if lvl, ok := toZapLevel(level); ok {
+		a.level.SetLevel(lvl)
+		a.stamp.Touch()
+	}
+}
+
+func toZapLevel(level logger.Level) (zapcore.Level, bool) {
+	lvl, ok := levelMap[level]
+	return lvl, ok
+}
+
+func fromZapLevel(level zapcore.Level) logger.Level {
+	switch level {
+	case zapcore.DebugLevel:
+		return logger.LevelDebug
+	case zapcore.InfoLevel:
+		return logger.LevelInfo
+	case zapcore.WarnLevel:
+		return logger.LevelWarn
+	case zapcore.ErrorLevel:
+		return logger.LevelError
+	case zapcore.DPanicLevel, zapcore.PanicLevel:
+		return logger.LevelPanic
+	default:
+		return logger.LevelFatal
+	}
+}
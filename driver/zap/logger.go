@@ -4,6 +4,7 @@ package zap
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	loggercontract "github.com/gopi-frame/contract/logger"
@@ -31,6 +32,14 @@ type Logger struct {
 	// because [zap.Logger] can only increase the log level, but not decrease it.
 	root *zap.Logger
 
+	// level is the level of the logger when no level rule matches its name or it changed after the rule,
+	// it is shared by all loggers derived by WithContext, With and Named.
+	level *atomicLevel
+	// levels is the level rule table shared by all loggers derived from the same root.
+	levels *logger.LevelRules[zapcore.Level]
+	// handler is the handler created from [Config.Handler], shared by all loggers derived from the same root.
+	handler *logger.SharedHandler
+
 	ctx context.Context
 }
 
@@ -46,34 +55,118 @@ func NewLogger(cfg *Config, opts ...Option) (*Logger, error) {
 	if err != nil {
 		return nil, err
 	}
-	ws, err := cfg.ZapWriters()
+	w, err := cfg.ZapHandler()
 	if err != nil {
 		return nil, err
 	}
 	l := new(Logger)
 	l.ctx = context.Background()
-	core := zapcore.NewCore(encoder, ws, zapcore.DebugLevel)
+	l.handler = logger.NewSharedHandler(w)
+	core := zapcore.NewCore(encoder, zapcore.Lock(l.handler), zapcore.DebugLevel)
 	l.root = zap.New(core, cfg.ZapOptions()...)
-	l.Logger = l.root.WithOptions(zap.IncreaseLevel(cfg.Level))
+	l.level = newAtomicLevel(cfg.Level)
+	l.levels = logger.NewLevelRules(cfg.Levels)
+	l.Logger = l.root.WithOptions(zap.IncreaseLevel(l.nameLevel(l.root.Name())))
 	return l, nil
 }
 
+// nameLevel returns the level enabler of the logger with the given name.
+func (l *Logger) nameLevel(name string) zapcore.LevelEnabler {
+	return &nameLevelEnabler{
+		rule:     logger.NewNameLevel(l.levels, name, &l.level.stamp),
+		fallback: l.level.level,
+	}
+}
+
 // WithLevel returns a new logger with the specified level.
+// The returned logger owns a new atomic level, so it is not affected by the level changes of l.
+// The level rules are not applied to the returned logger, but to its named children.
 func (l *Logger) WithLevel(level loggercontract.Level) loggercontract.Logger {
-	lvl := levelMap[level]
+	lvl := newAtomicLevel(levelMap[level])
 	return &Logger{
-		ctx:    l.ctx,
-		Logger: l.root.WithOptions(zap.IncreaseLevel(lvl)),
-		root:   l.root,
+		ctx:     l.ctx,
+		Logger:  l.root.WithOptions(zap.IncreaseLevel(lvl.level)),
+		root:    l.root,
+		level:   lvl,
+		levels:  l.levels,
+		handler: l.handler,
 	}
 }
 
+// AtomicLevel returns the level shared by the logger and the loggers derived from it.
+func (l *Logger) AtomicLevel() logger.AtomicLevel {
+	return l.level
+}
+
+// NameLevels returns the level rule table by logger name shared by all loggers derived from the same root.
+func (l *Logger) NameLevels() logger.NameLevels {
+	return logger.NewNameLevels(l.levels, &l.level.stamp, toZapLevel, fromZapLevel)
+}
+
+// Reopen reopens the handler created from [Config.Handler] if it implements [logger.Reopener].
+func (l *Logger) Reopen() error {
+	return l.handler.Reopen()
+}
+
+// Close flushes and closes the handler created from [Config.Handler].
+// The handler is shared by all loggers derived from the same root, so they are all closed.
+// [DefaultWriter] is flushed but not closed if it is a standard stream.
+func (l *Logger) Close() error {
+	return errors.Join(l.Logger.Sync(), l.handler.Close())
+}
+
+// Shutdown flushes and closes the handler created from [Config.Handler] as Close does,
+// waiting for buffered and in-flight writes until ctx is done.
+func (l *Logger) Shutdown(ctx context.Context) error {
+	return l.handler.Shutdown(ctx)
+}
+
 // WithContext returns a new logger with the specified context.
 func (l *Logger) WithContext(ctx context.Context) loggercontract.Logger {
 	return &Logger{
-		ctx:    ctx,
-		Logger: l.Logger,
-		root:   l.root,
+		ctx:     ctx,
+		Logger:  l.Logger,
+		root:    l.root,
+		level:   l.level,
+		levels:  l.levels,
+		handler: l.handler,
+	}
+}
+
+// With returns a new logger with the given fields attached.
+// The fields are encoded as native zap fields.
+func (l *Logger) With(fields ...logger.Field) loggercontract.Logger {
+	zapFields := make([]zap.Field, 0, len(fields))
+	for _, field := range fields {
+		zapFields = append(zapFields, zap.Any(field.Key, field.Value))
+	}
+	return &Logger{
+		ctx:     l.ctx,
+		Logger:  l.Logger.With(zapFields...),
+		root:    l.root.With(zapFields...),
+		level:   l.level,
+		levels:  l.levels,
+		handler: l.handler,
+	}
+}
+
+// WithFields returns a new logger with the given fields attached.
+func (l *Logger) WithFields(fields map[string]any) loggercontract.Logger {
+	return l.With(logger.Fields(fields)...)
+}
+
+// Named returns a new logger with the given name appended to the logger's name.
+// The name is emitted under [zapcore.EncoderConfig.NameKey],
+// and the level of the returned logger is resolved from [Config.Levels] by its full name.
+func (l *Logger) Named(name string) loggercontract.Logger {
+	root := l.root.Named(name)
+	return &Logger{
+		ctx:     l.ctx,
+		Logger:  root.WithOptions(zap.IncreaseLevel(l.nameLevel(root.Name()))),
+		root:    root,
+		level:   l.level,
+		levels:  l.levels,
+		handler: l.handler,
 	}
 }
 
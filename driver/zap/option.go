@@ -26,6 +26,15 @@ func Level(level zapcore.Level) Option {
 	}
 }
 
+// Levels sets the level rule table by logger name.
+// For example, {"*": WarnLevel, "billing.*": DebugLevel} logs debug messages of "billing" and its descendants only.
+func Levels(levels map[string]zapcore.Level) Option {
+	return func(cfg *Config) error {
+		cfg.Levels = levels
+		return nil
+	}
+}
+
 // Encoder sets the log encoder.
 // AvailableAt encoders: [EncoderJSON], [EncoderText].
 // If empty string is given, it does nothing.
@@ -36,3 +36,23 @@ func NewNotConfiguredChannelException(channel string) *NotConfiguredChannelExcep
 		Throwable: exception.New(fmt.Sprintf("channel [%s] not configured", channel)),
 	}
 }
+
+type MissingDefaultChannelException struct {
+	Throwable
+}
+
+func NewMissingDefaultChannelException() *MissingDefaultChannelException {
+	return &MissingDefaultChannelException{
+		Throwable: exception.New("default channel is missing"),
+	}
+}
+
+type InvalidChannelException struct {
+	Throwable
+}
+
+func NewInvalidChannelException(channel string, reason string) *InvalidChannelException {
+	return &InvalidChannelException{
+		Throwable: exception.New(fmt.Sprintf("channel [%s] is invalid: %s", channel, reason)),
+	}
+}
@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"github.com/gopi-frame/contract/logger"
+)
+
+// Field is a structured key/value pair attached to the log records.
+type Field struct {
+	Key   string
+	Value any
+}
+
+// Any creates a new [Field] with the given key and value.
+func Any(key string, value any) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Fields converts the given map to a list of [Field] sorted by key.
+func Fields(fields map[string]any) []Field {
+	list := make([]Field, 0, len(fields))
+	for _, key := range sortedKeys(fields) {
+		list = append(list, Field{Key: key, Value: fields[key]})
+	}
+	return list
+}
+
+// FieldLogger is a logger which encodes structured fields natively.
+type FieldLogger interface {
+	logger.Logger
+
+	// With returns a child logger with the given fields attached.
+	With(fields ...Field) logger.Logger
+
+	// WithFields returns a child logger with the given fields attached.
+	WithFields(fields map[string]any) logger.Logger
+}
+
+// With returns a child logger of l with the given fields attached.
+// If l does not implement [FieldLogger], l is returned unchanged.
+func With(l logger.Logger, fields ...Field) logger.Logger {
+	if fl, ok := l.(FieldLogger); ok {
+		return fl.With(fields...)
+	}
+	return l
+}
@@ -9,6 +9,8 @@ import (
 
 var handlers = kv.NewMap[string, func(config map[string]any) (io.WriteCloser, error)]()
 
+var validators = kv.NewMap[string, func(config map[string]any) error]()
+
 func RegisterHandler(handlerName string, creator func(config map[string]any) (io.WriteCloser, error)) {
 	handlers.Lock()
 	defer handlers.Unlock()
@@ -18,6 +20,17 @@ func RegisterHandler(handlerName string, creator func(config map[string]any) (io
 	handlers.Set(handlerName, creator)
 }
 
+// RegisterHandlerValidator registers the function which checks the config of a handler without creating it.
+// If a validator for the same handler already exists, it panics.
+func RegisterHandlerValidator(handlerName string, validator func(config map[string]any) error) {
+	validators.Lock()
+	defer validators.Unlock()
+	if validators.ContainsKey(handlerName) {
+		panic(exception.NewArgumentException("handlerName", handlerName, fmt.Sprintf("duplicate handler validator \"%s\"", handlerName)))
+	}
+	validators.Set(handlerName, validator)
+}
+
 func CreateHandler(handlerName string, config map[string]any) (io.WriteCloser, error) {
 	handlers.RLock()
 	handler, ok := handlers.Get(handlerName)
@@ -27,3 +40,21 @@ func CreateHandler(handlerName string, config map[string]any) (io.WriteCloser, e
 	}
 	return nil, NewUnknownHandlerException(handlerName)
 }
+
+// ValidateHandler checks that the handler is registered and, if it has a validator, that its config is valid.
+// No file is opened and no connection is made.
+func ValidateHandler(handlerName string, config map[string]any) error {
+	handlers.RLock()
+	registered := handlers.ContainsKey(handlerName)
+	handlers.RUnlock()
+	if !registered {
+		return NewUnknownHandlerException(handlerName)
+	}
+	validators.RLock()
+	validator, ok := validators.Get(handlerName)
+	validators.RUnlock()
+	if ok {
+		return validator(config)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package async
+
+import (
+	"fmt"
+
+	. "github.com/gopi-frame/contract/exception"
+	"github.com/gopi-frame/exception"
+)
+
+type HandlerMissingException struct {
+	Throwable
+}
+
+func NewHandlerMissingException() *HandlerMissingException {
+	return &HandlerMissingException{
+		Throwable: exception.New("handler is missing in configuration"),
+	}
+}
+
+type InvalidOverflowPolicyException struct {
+	Throwable
+}
+
+func NewInvalidOverflowPolicyException(policy string) *InvalidOverflowPolicyException {
+	return &InvalidOverflowPolicyException{
+		Throwable: exception.New(fmt.Sprintf("invalid overflow policy [%s]", policy)),
+	}
+}
+
+type ClosedHandlerException struct {
+	Throwable
+}
+
+func NewClosedHandlerException() *ClosedHandlerException {
+	return &ClosedHandlerException{
+		Throwable: exception.New("handler is closed"),
+	}
+}
@@ -0,0 +1,375 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/gopi-frame/env"
+	"github.com/gopi-frame/logger"
+)
+
+var handlerName = "async"
+
+//goland:noinspection GoBoolExpressions
+func init() {
+	if handlerName != "" {
+		logger.RegisterHandler(handlerName, func(config map[string]any) (io.WriteCloser, error) {
+			return NewAsyncHandlerFromConfig(config)
+		})
+		logger.RegisterHandlerValidator(handlerName, func(config map[string]any) error {
+			handlerName, handlerWith, _, err := parseConfig(config)
+			if err != nil {
+				return err
+			}
+			return logger.ValidateHandler(handlerName, handlerWith)
+		})
+	}
+}
+
+// DefaultQueueSize is the default number of records the queue can hold.
+const DefaultQueueSize = 1024
+
+// DefaultLevelKey is the default key of the level in the records, see [WithLevelKey].
+const DefaultLevelKey = "level"
+
+// AsyncHandler writes records to a wrapped handler in a background goroutine through a bounded queue.
+// When the queue is full, the overflow policy decides whether Write blocks or a record is dropped.
+type AsyncHandler struct {
+	handler   io.Writer
+	queue     chan entry
+	queueSize int
+
+	policy       OverflowPolicy
+	dropLevel    logger.Level
+	levelKey     string
+	errorHandler func(error)
+
+	dropped atomic.Uint64
+	failed  atomic.Uint64
+
+	// pending counts the records queued but not yet written or dropped, idle is closed when it is zero.
+	// closed is set by Shutdown under the same lock, so a record counted is written before Shutdown returns.
+	mu      sync.Mutex
+	pending int
+	idle    chan struct{}
+	closed  bool
+
+	// flushes receives the markers of Sync discarded from the queue, see [AsyncHandler.discard].
+	flushes  chan chan struct{}
+	stop     chan struct{}
+	exited   chan struct{}
+	shutdown sync.Once
+}
+
+// entry is a queued record, or a marker of Sync if flushed is not nil,
+// which is closed once the records queued before it are written or dropped.
+type entry struct {
+	record  []byte
+	flushed chan struct{}
+}
+
+// NewAsyncHandler creates a new asynchronous handler wrapping the given handler,
+// and starts its background writer.
+func NewAsyncHandler(handler io.Writer, opts ...Option) *AsyncHandler {
+	h := &AsyncHandler{
+		handler:   handler,
+		queueSize: DefaultQueueSize,
+		policy:    PolicyBlock,
+		dropLevel: logger.LevelWarn,
+		levelKey:  DefaultLevelKey,
+		idle:      make(chan struct{}),
+		flushes:   make(chan chan struct{}),
+		stop:      make(chan struct{}),
+		exited:    make(chan struct{}),
+	}
+	close(h.idle)
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.queue = make(chan entry, h.queueSize)
+	go h.run()
+	return h
+}
+
+// NewAsyncHandlerFromConfig creates a new asynchronous handler from the given configuration.
+//
+// The wrapped handler is created by [logger.CreateHandler] with the "handler" and "handlerWith" keys:
+//
+//	{
+//		"handler": "daily",
+//		"handlerWith": {"filename": "logs/app.log"},
+//		"queueSize": 4096,
+//		"overflowPolicy": "dropBelowLevel",
+//		"dropLevel": "warn",
+//	}
+func NewAsyncHandlerFromConfig(config map[string]any) (*AsyncHandler, error) {
+	handlerName, handlerWith, opts, err := parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	handler, err := logger.CreateHandler(handlerName, handlerWith)
+	if err != nil {
+		return nil, err
+	}
+	return NewAsyncHandler(handler, opts...), nil
+}
+
+// parseConfig decodes the config into the wrapped handler and the options of [NewAsyncHandler].
+func parseConfig(config map[string]any) (string, map[string]any, []Option, error) {
+	var cfg struct {
+		Handler        string
+		HandlerWith    map[string]any
+		QueueSize      int
+		OverflowPolicy string
+		DropLevel      string
+		LevelKey       string
+	}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           &cfg,
+		WeaklyTypedInput: true,
+		MatchName: func(mapKey, fieldName string) bool {
+			return strings.EqualFold(mapKey, fieldName) || strings.EqualFold(fieldName, strings.ReplaceAll(mapKey, "_", ""))
+		},
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			env.ExpandStringWithEnvHookFunc(),
+			env.ExpandStringKeyMapWithEnvHookFunc(),
+			mapstructure.StringToBasicTypeHookFunc(),
+		),
+	})
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if err := decoder.Decode(config); err != nil {
+		return "", nil, nil, err
+	}
+	if cfg.Handler == "" {
+		return "", nil, nil, NewHandlerMissingException()
+	}
+	var opts []Option
+	if cfg.QueueSize > 0 {
+		opts = append(opts, WithQueueSize(cfg.QueueSize))
+	}
+	if cfg.OverflowPolicy != "" {
+		policy, err := ParseOverflowPolicy(cfg.OverflowPolicy)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		opts = append(opts, WithOverflowPolicy(policy))
+	}
+	if cfg.DropLevel != "" {
+		var level logger.Level
+		if err := level.UnmarshalText([]byte(cfg.DropLevel)); err != nil {
+			return "", nil, nil, err
+		}
+		opts = append(opts, WithDropLevel(level))
+	}
+	if cfg.LevelKey != "" {
+		opts = append(opts, WithLevelKey(cfg.LevelKey))
+	}
+	return cfg.Handler, cfg.HandlerWith, opts, nil
+}
+
+// Write queues a copy of p and returns without waiting for it to be written.
+// When the queue is full, the record is handled by the overflow policy,
+// a dropped record is not reported as an error but counted by [AsyncHandler.Dropped].
+func (h *AsyncHandler) Write(p []byte) (int, error) {
+	if !h.add() {
+		return 0, NewClosedHandlerException()
+	}
+	record := entry{record: make([]byte, len(p))}
+	copy(record.record, p)
+	select {
+	case h.queue <- record:
+		return len(p), nil
+	default:
+	}
+	switch h.policy {
+	case PolicyDropNewest:
+		h.drop()
+		return len(p), nil
+	case PolicyDropOldest:
+		for {
+			select {
+			case h.queue <- record:
+				return len(p), nil
+			default:
+			}
+			select {
+			case e := <-h.queue:
+				h.discard(e)
+			default:
+			}
+		}
+	case PolicyDropBelowLevel:
+		if level, ok := levelOf(record.record, h.levelKey); ok && level < h.dropLevel {
+			h.drop()
+			return len(p), nil
+		}
+	}
+	select {
+	case h.queue <- record:
+		return len(p), nil
+	case <-h.stop:
+		h.done()
+		return 0, NewClosedHandlerException()
+	}
+}
+
+func (h *AsyncHandler) run() {
+	defer close(h.exited)
+	for {
+		// stop wins over queued records, which are discarded once Shutdown gave up waiting
+		select {
+		case <-h.stop:
+			return
+		default:
+		}
+		select {
+		case e := <-h.queue:
+			if e.flushed != nil {
+				close(e.flushed)
+				continue
+			}
+			h.write(e.record)
+		case flushed := <-h.flushes:
+			close(flushed)
+		case <-h.stop:
+			return
+		}
+	}
+}
+
+func (h *AsyncHandler) write(record []byte) {
+	defer h.done()
+	if _, err := h.handler.Write(record); err != nil {
+		h.failed.Add(1)
+		if h.errorHandler != nil {
+			h.errorHandler(err)
+		}
+	}
+}
+
+// add counts a record about to be queued, it returns false if the handler is shut down.
+func (h *AsyncHandler) add() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return false
+	}
+	if h.pending == 0 {
+		h.idle = make(chan struct{})
+	}
+	h.pending++
+	return true
+}
+
+func (h *AsyncHandler) done() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.pending--
+	if h.pending == 0 {
+		close(h.idle)
+	}
+}
+
+func (h *AsyncHandler) drop() {
+	h.dropped.Add(1)
+	h.done()
+}
+
+// discard drops an entry taken from the front of the queue.
+// A marker is handed to the background writer instead, the records queued before it are handled
+// once the writer is done with the record it may be writing.
+func (h *AsyncHandler) discard(e entry) {
+	if e.flushed == nil {
+		h.drop()
+		return
+	}
+	go func() {
+		select {
+		case h.flushes <- e.flushed:
+		case <-h.exited:
+		}
+	}()
+}
+
+// wait waits until all queued records are written or dropped, or ctx is done.
+func (h *AsyncHandler) wait(ctx context.Context) error {
+	h.mu.Lock()
+	idle := h.idle
+	h.mu.Unlock()
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Dropped returns the number of records dropped by the overflow policy.
+func (h *AsyncHandler) Dropped() uint64 {
+	return h.dropped.Load()
+}
+
+// Failed returns the number of records the wrapped handler failed to write.
+func (h *AsyncHandler) Failed() uint64 {
+	return h.failed.Load()
+}
+
+// Sync waits until the records queued before it are written, then flushes the wrapped handler.
+// The records written concurrently are not waited for, so Sync returns while other goroutines keep writing.
+func (h *AsyncHandler) Sync() error {
+	flushed := make(chan struct{})
+	select {
+	case h.queue <- entry{flushed: flushed}:
+		select {
+		case <-flushed:
+		case <-h.exited:
+		}
+	case <-h.stop:
+	}
+	return logger.SyncHandler(h.handler)
+}
+
+// Reopen reopens the wrapped handler, see [logger.Reopener].
+func (h *AsyncHandler) Reopen() error {
+	return logger.ReopenHandler(h.handler)
+}
+
+// Shutdown stops accepting records and waits until the queued ones are written or ctx is done,
+// then stops the background writer and closes the wrapped handler.
+// Records still queued when ctx is done are discarded.
+func (h *AsyncHandler) Shutdown(ctx context.Context) error {
+	var err error
+	h.shutdown.Do(func() {
+		h.mu.Lock()
+		h.closed = true
+		h.mu.Unlock()
+		err = h.wait(ctx)
+		close(h.stop)
+		select {
+		case <-h.exited:
+			err = errors.Join(err, logger.SyncHandler(h.handler), logger.CloseHandler(h.handler))
+		case <-ctx.Done():
+			// the wrapped handler is still writing, close it once it returns
+			go func() {
+				<-h.exited
+				_ = logger.CloseHandler(h.handler)
+			}()
+			if err == nil {
+				err = ctx.Err()
+			}
+		}
+	})
+	return err
+}
+
+// Close waits until all queued records are written, then closes the wrapped handler.
+func (h *AsyncHandler) Close() error {
+	return h.Shutdown(context.Background())
+}
@@ -0,0 +1,338 @@
+package async
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gopi-frame/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// gateHandler blocks every write until gate is closed, started receives the records being written.
+type gateHandler struct {
+	mu      sync.Mutex
+	buffer  bytes.Buffer
+	gate    chan struct{}
+	started chan string
+	err     error
+	closed  bool
+}
+
+func newGateHandler() *gateHandler {
+	return &gateHandler{gate: make(chan struct{}), started: make(chan string, 16)}
+}
+
+func (h *gateHandler) Write(p []byte) (int, error) {
+	if h.started != nil {
+		h.started <- string(p)
+	}
+	<-h.gate
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.err != nil {
+		return 0, h.err
+	}
+	return h.buffer.Write(p)
+}
+
+func (h *gateHandler) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	return nil
+}
+
+func (h *gateHandler) String() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.buffer.String()
+}
+
+// fill writes the first record, waits until the background writer is blocked on it, then fills the queue.
+func fill(t *testing.T, h *AsyncHandler, gate *gateHandler, records ...string) {
+	_, err := h.Write([]byte("first\n"))
+	if !assert.NoError(t, err) {
+		assert.FailNow(t, err.Error())
+	}
+	<-gate.started
+	for _, record := range records {
+		_, err := h.Write([]byte(record))
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+	}
+}
+
+func TestAsyncHandler(t *testing.T) {
+	t.Run("write", func(t *testing.T) {
+		gate := newGateHandler()
+		close(gate.gate)
+		h := NewAsyncHandler(gate)
+		buf := []byte("a\n")
+		_, err := h.Write(buf)
+		assert.NoError(t, err)
+		buf[0] = 'b'
+		_, err = h.Write(buf)
+		assert.NoError(t, err)
+		assert.NoError(t, h.Sync())
+		assert.Equal(t, "a\nb\n", gate.String())
+		assert.NoError(t, h.Close())
+		assert.True(t, gate.closed)
+		_, err = h.Write(buf)
+		assert.IsType(t, new(ClosedHandlerException), err)
+	})
+
+	t.Run("drop newest", func(t *testing.T) {
+		gate := newGateHandler()
+		h := NewAsyncHandler(gate, WithQueueSize(2), WithOverflowPolicy(PolicyDropNewest))
+		fill(t, h, gate, "a\n", "b\n", "c\n", "d\n")
+		assert.Equal(t, uint64(2), h.Dropped())
+		close(gate.gate)
+		assert.NoError(t, h.Close())
+		assert.Equal(t, "first\na\nb\n", gate.String())
+	})
+
+	t.Run("drop oldest", func(t *testing.T) {
+		gate := newGateHandler()
+		h := NewAsyncHandler(gate, WithQueueSize(2), WithOverflowPolicy(PolicyDropOldest))
+		fill(t, h, gate, "a\n", "b\n", "c\n", "d\n")
+		assert.Equal(t, uint64(2), h.Dropped())
+		close(gate.gate)
+		assert.NoError(t, h.Close())
+		assert.Equal(t, "first\nc\nd\n", gate.String())
+	})
+
+	t.Run("drop below level", func(t *testing.T) {
+		gate := newGateHandler()
+		h := NewAsyncHandler(gate, WithQueueSize(1), WithOverflowPolicy(PolicyDropBelowLevel), WithDropLevel(logger.LevelWarn))
+		fill(t, h, gate, `{"level":"info","msg":"a"}`+"\n", `{"level":"debug","msg":"b"}`+"\n", "level=INFO msg=c\n")
+		assert.Equal(t, uint64(2), h.Dropped())
+
+		written := make(chan struct{})
+		go func() {
+			defer close(written)
+			_, _ = h.Write([]byte("level=ERROR msg=d\n"))
+		}()
+		select {
+		case <-written:
+			assert.FailNow(t, "write above the drop level should block")
+		case <-time.After(20 * time.Millisecond):
+		}
+		close(gate.gate)
+		<-written
+		assert.NoError(t, h.Close())
+		assert.Equal(t, "first\n"+`{"level":"info","msg":"a"}`+"\nlevel=ERROR msg=d\n", gate.String())
+	})
+
+	t.Run("block", func(t *testing.T) {
+		gate := newGateHandler()
+		h := NewAsyncHandler(gate, WithQueueSize(1))
+		fill(t, h, gate, "a\n")
+		written := make(chan struct{})
+		go func() {
+			defer close(written)
+			_, _ = h.Write([]byte("b\n"))
+		}()
+		select {
+		case <-written:
+			assert.FailNow(t, "write should block")
+		case <-time.After(20 * time.Millisecond):
+		}
+		close(gate.gate)
+		<-written
+		assert.NoError(t, h.Close())
+		assert.Equal(t, "first\na\nb\n", gate.String())
+		assert.Zero(t, h.Dropped())
+	})
+
+	t.Run("sync with concurrent writes", func(t *testing.T) {
+		gate := newGateHandler()
+		gate.started = nil
+		close(gate.gate)
+		h := NewAsyncHandler(gate, WithQueueSize(4))
+		stop := make(chan struct{})
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					select {
+					case <-stop:
+						return
+					default:
+						_, _ = h.Write([]byte("x\n"))
+					}
+				}
+			}()
+		}
+		_, err := h.Write([]byte("before\n"))
+		assert.NoError(t, err)
+		synced := make(chan error)
+		go func() {
+			synced <- h.Sync()
+		}()
+		select {
+		case err := <-synced:
+			assert.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			assert.Fail(t, "sync should not wait for the records written after it")
+		}
+		assert.Contains(t, gate.String(), "before\n")
+		close(stop)
+		wg.Wait()
+		assert.NoError(t, h.Close())
+	})
+
+	t.Run("sync with drop oldest", func(t *testing.T) {
+		gate := newGateHandler()
+		h := NewAsyncHandler(gate, WithQueueSize(2), WithOverflowPolicy(PolicyDropOldest))
+		fill(t, h, gate, "a\n")
+		synced := make(chan error)
+		go func() {
+			synced <- h.Sync()
+		}()
+		assert.Eventually(t, func() bool {
+			return len(h.queue) == 2
+		}, time.Second, time.Millisecond)
+		// the marker of Sync is dropped from the front of the queue while the first record is being written
+		for _, record := range []string{"b\n", "c\n"} {
+			_, err := h.Write([]byte(record))
+			assert.NoError(t, err)
+		}
+		select {
+		case <-synced:
+			assert.FailNow(t, "sync should wait for the record being written")
+		case <-time.After(20 * time.Millisecond):
+		}
+		close(gate.gate)
+		assert.NoError(t, <-synced)
+		assert.True(t, strings.HasPrefix(gate.String(), "first\n"))
+		assert.NoError(t, h.Close())
+		assert.Equal(t, "first\nb\nc\n", gate.String())
+	})
+
+	t.Run("write during shutdown", func(t *testing.T) {
+		gate := newGateHandler()
+		gate.started = nil
+		close(gate.gate)
+		h := NewAsyncHandler(gate, WithQueueSize(1))
+		var written atomic.Int64
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					if _, err := h.Write([]byte("x\n")); err == nil {
+						written.Add(1)
+					}
+				}
+			}()
+		}
+		time.Sleep(time.Millisecond)
+		assert.NoError(t, h.Close())
+		wg.Wait()
+		assert.Equal(t, int(written.Load()), strings.Count(gate.String(), "x\n"))
+	})
+
+	t.Run("shutdown deadline", func(t *testing.T) {
+		gate := newGateHandler()
+		h := NewAsyncHandler(gate)
+		fill(t, h, gate, "a\n")
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, h.Shutdown(ctx), context.DeadlineExceeded)
+		close(gate.gate)
+		assert.Eventually(t, func() bool {
+			gate.mu.Lock()
+			defer gate.mu.Unlock()
+			return gate.closed
+		}, time.Second, 10*time.Millisecond)
+		assert.Equal(t, "first\n", gate.String())
+	})
+
+	t.Run("write errors", func(t *testing.T) {
+		gate := newGateHandler()
+		gate.err = errors.New("disk full")
+		close(gate.gate)
+		var errs []error
+		h := NewAsyncHandler(gate, WithErrorHandler(func(err error) {
+			errs = append(errs, err)
+		}))
+		_, err := h.Write([]byte("a\n"))
+		assert.NoError(t, err)
+		assert.NoError(t, h.Close())
+		assert.Equal(t, uint64(1), h.Failed())
+		assert.Equal(t, []error{gate.err}, errs)
+	})
+}
+
+func TestNewAsyncHandlerFromConfig(t *testing.T) {
+	var buffer = new(gateHandler)
+	buffer.gate = make(chan struct{})
+	buffer.started = make(chan string, 16)
+	close(buffer.gate)
+	logger.RegisterHandler("asyncBuffer", func(config map[string]any) (io.WriteCloser, error) {
+		return buffer, nil
+	})
+
+	t.Run("normal", func(t *testing.T) {
+		h, err := NewAsyncHandlerFromConfig(map[string]any{
+			"handler":         "asyncBuffer",
+			"queue_size":      16,
+			"overflow_policy": "drop_oldest",
+			"dropLevel":       "error",
+		})
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		assert.Equal(t, 16, cap(h.queue))
+		assert.Equal(t, PolicyDropOldest, h.policy)
+		assert.Equal(t, logger.LevelError, h.dropLevel)
+		_, err = h.Write([]byte("test"))
+		assert.NoError(t, err)
+		assert.NoError(t, h.Close())
+		assert.Equal(t, "test", buffer.String())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := NewAsyncHandlerFromConfig(map[string]any{})
+		assert.IsType(t, new(HandlerMissingException), err)
+		_, err = NewAsyncHandlerFromConfig(map[string]any{"handler": "asyncBuffer", "overflowPolicy": "random"})
+		assert.IsType(t, new(InvalidOverflowPolicyException), err)
+		_, err = NewAsyncHandlerFromConfig(map[string]any{"handler": "missing"})
+		assert.IsType(t, new(logger.UnknownHandlerException), err)
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		assert.NoError(t, logger.ValidateHandler(handlerName, map[string]any{"handler": "asyncBuffer"}))
+		assert.IsType(t, new(HandlerMissingException), logger.ValidateHandler(handlerName, map[string]any{}))
+		assert.IsType(t, new(InvalidOverflowPolicyException), logger.ValidateHandler(handlerName, map[string]any{"handler": "asyncBuffer", "overflowPolicy": "random"}))
+		assert.IsType(t, new(logger.UnknownHandlerException), logger.ValidateHandler(handlerName, map[string]any{"handler": "missing"}))
+	})
+}
+
+func TestLevelOf(t *testing.T) {
+	for record, expected := range map[string]logger.Level{
+		`{"time":"now","level":"debug","msg":"a"}`: logger.LevelDebug,
+		`{"level": "WARN"}`:                        logger.LevelWarn,
+		"time=now level=ERROR msg=a":               logger.LevelError,
+		`level="info" msg=a`:                       logger.LevelInfo,
+	} {
+		level, ok := levelOf([]byte(record), "level")
+		assert.True(t, ok, record)
+		assert.Equal(t, expected, level, record)
+	}
+	for _, record := range []string{`{"msg":"a"}`, `{"level":1}`, "sublevel=info", `{"level":"dpanic"}`} {
+		_, ok := levelOf([]byte(record), "level")
+		assert.False(t, ok, record)
+	}
+}
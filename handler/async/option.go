@@ -0,0 +1,42 @@
+package async
+
+import "github.com/gopi-frame/logger"
+
+type Option func(h *AsyncHandler)
+
+// WithQueueSize sets the number of records the queue can hold, it is ignored if size is not positive.
+func WithQueueSize(size int) Option {
+	return func(h *AsyncHandler) {
+		if size > 0 {
+			h.queueSize = size
+		}
+	}
+}
+
+// WithOverflowPolicy sets the policy applied when the queue is full.
+func WithOverflowPolicy(policy OverflowPolicy) Option {
+	return func(h *AsyncHandler) {
+		h.policy = policy
+	}
+}
+
+// WithDropLevel sets the level below which records are dropped by [PolicyDropBelowLevel].
+func WithDropLevel(level logger.Level) Option {
+	return func(h *AsyncHandler) {
+		h.dropLevel = level
+	}
+}
+
+// WithLevelKey sets the key of the level in the records, which is read by [PolicyDropBelowLevel].
+func WithLevelKey(key string) Option {
+	return func(h *AsyncHandler) {
+		h.levelKey = key
+	}
+}
+
+// WithErrorHandler sets the function called with the errors of the wrapped handler.
+func WithErrorHandler(fn func(error)) Option {
+	return func(h *AsyncHandler) {
+		h.errorHandler = fn
+	}
+}
@@ -0,0 +1,44 @@
+package async
+
+import (
+	"strings"
+
+	"github.com/gopi-frame/logger"
+	"github.com/gopi-frame/logger/handler/internal/record"
+)
+
+// OverflowPolicy decides what happens to a record written when the queue is full.
+type OverflowPolicy string
+
+const (
+	// PolicyBlock blocks the writer until the queue has room.
+	PolicyBlock OverflowPolicy = "block"
+	// PolicyDropNewest drops the record being written.
+	PolicyDropNewest OverflowPolicy = "dropNewest"
+	// PolicyDropOldest drops the oldest queued records until the record being written fits.
+	PolicyDropOldest OverflowPolicy = "dropOldest"
+	// PolicyDropBelowLevel drops the record being written if its level is below the drop level,
+	// otherwise it blocks the writer until the queue has room.
+	// Records whose level cannot be read are never dropped.
+	PolicyDropBelowLevel OverflowPolicy = "dropBelowLevel"
+)
+
+var policies = []OverflowPolicy{PolicyBlock, PolicyDropNewest, PolicyDropOldest, PolicyDropBelowLevel}
+
+// ParseOverflowPolicy parses the policy case-insensitively, ignoring underscores and dashes,
+// e.g. "drop_newest" and "DROP-NEWEST" are both [PolicyDropNewest].
+func ParseOverflowPolicy(s string) (OverflowPolicy, error) {
+	normalized := strings.NewReplacer("_", "", "-", "").Replace(s)
+	for _, policy := range policies {
+		if strings.EqualFold(normalized, string(policy)) {
+			return policy, nil
+		}
+	}
+	return "", NewInvalidOverflowPolicyException(s)
+}
+
+// levelOf reads the level of a record encoded as JSON, e.g. {"level":"info"},
+// or as key=value pairs, e.g. level=INFO.
+func levelOf(data []byte, key string) (logger.Level, bool) {
+	return record.Level(data, key)
+}
@@ -0,0 +1,44 @@
+package daily
+
+import "sync"
+
+// background runs jobs one at a time in a goroutine, in the order they are added,
+// the goroutine exits when there are no jobs left.
+type background struct {
+	mu      sync.Mutex
+	jobs    []func()
+	running bool
+	pending sync.WaitGroup
+}
+
+func (b *background) add(job func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending.Add(1)
+	b.jobs = append(b.jobs, job)
+	if !b.running {
+		b.running = true
+		go b.run()
+	}
+}
+
+func (b *background) run() {
+	for {
+		b.mu.Lock()
+		if len(b.jobs) == 0 {
+			b.running = false
+			b.mu.Unlock()
+			return
+		}
+		job := b.jobs[0]
+		b.jobs = b.jobs[1:]
+		b.mu.Unlock()
+		job()
+		b.pending.Done()
+	}
+}
+
+// wait waits until all the added jobs are done.
+func (b *background) wait() {
+	b.pending.Wait()
+}
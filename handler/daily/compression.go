@@ -0,0 +1,269 @@
+package daily
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gopi-frame/logger/handler/internal/flock"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression is the algorithm the rolled files are compressed with.
+type Compression string
+
+const (
+	CompressionNone Compression = "none"
+	CompressionGzip Compression = "gzip"
+	CompressionZstd Compression = "zstd"
+)
+
+// compressedExts are the extensions of the compressed files.
+var compressedExts = []string{".gz", ".zst"}
+
+// compressRetries is the number of times a failed compression is retried.
+const compressRetries = 3
+
+// ParseCompression parses a compression case-insensitively, an empty string is [CompressionNone].
+func ParseCompression(s string) (Compression, error) {
+	switch strings.ToLower(s) {
+	case "", "none":
+		return CompressionNone, nil
+	case "gzip", "gz":
+		return CompressionGzip, nil
+	case "zstd", "zst":
+		return CompressionZstd, nil
+	default:
+		return "", NewInvalidCompressionException(s)
+	}
+}
+
+// ext returns the extension of the files compressed with c.
+func (c Compression) ext() string {
+	switch c {
+	case CompressionGzip:
+		return ".gz"
+	case CompressionZstd:
+		return ".zst"
+	default:
+		return ""
+	}
+}
+
+// writer returns a writer compressing to w, the default level is used if level is 0.
+func (c Compression) writer(w io.Writer, level int) (io.WriteCloser, error) {
+	switch c {
+	case CompressionGzip:
+		if level == 0 {
+			level = gzip.DefaultCompression
+		}
+		return gzip.NewWriterLevel(w, level)
+	case CompressionZstd:
+		if level == 0 {
+			return zstd.NewWriter(w)
+		}
+		return zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+	default:
+		return nil, NewInvalidCompressionException(string(c))
+	}
+}
+
+// reader returns a reader decompressing r.
+func (c Compression) reader(r io.Reader) (io.ReadCloser, error) {
+	switch c {
+	case CompressionGzip:
+		return gzip.NewReader(r)
+	case CompressionZstd:
+		d, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	default:
+		return nil, NewInvalidCompressionException(string(c))
+	}
+}
+
+// isCompressed reports whether the file name has the extension of a compressed file.
+func isCompressed(name string) bool {
+	for _, ext := range compressedExts {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// compressFile compresses the file with retries, unless it has been removed in the meantime.
+func (h *DailyHandler) compressFile(file string) error {
+	var err error
+	delay := h.retryDelay
+	for i := 0; i <= compressRetries; i++ {
+		if i > 0 {
+			time.Sleep(delay)
+			delay *= 2
+		}
+		if err = h.compress(file); err == nil || errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return err
+}
+
+// compress compresses the file to a temporary file, which is renamed when it is complete,
+// then removes the original, so a crash never leaves a partial compressed file beside a removed original.
+func (h *DailyHandler) compress(file string) (err error) {
+	src, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = src.Close()
+	}()
+	if h.lock != nil {
+		// wait for the buffered writes of the other processes, the lock is released when the file is closed
+		if err := flock.Lock(src); err != nil {
+			return err
+		}
+	}
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	target := file + h.compression.ext()
+	// a crash between the rename and the removal below leaves the original beside the complete compressed file,
+	// the content compressed already is skipped, so only the records written since are added
+	compressed, err := h.compressedPrefix(target, src)
+	if err != nil {
+		return err
+	}
+	if compressed > 0 && compressed == info.Size() {
+		return os.Remove(file)
+	}
+	if _, err = src.Seek(compressed, io.SeekStart); err != nil {
+		return err
+	}
+	tmp := target + ".tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = dst.Close()
+			_ = os.Remove(tmp)
+		}
+	}()
+	// a buffered write of another process may recreate a file already compressed,
+	// it is appended to the compressed file as a new gzip member or zstd frame instead of replacing it
+	if err = appendFile(dst, target); err != nil {
+		return err
+	}
+	w, err := h.compression.writer(dst, h.compressionLevel)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(w, src); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	if err = dst.Sync(); err != nil {
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp, target); err != nil {
+		return err
+	}
+	return os.Remove(file)
+}
+
+// appendFile copies the content of the file at name to w, if it exists.
+func appendFile(w io.Writer, name string) error {
+	f, err := os.Open(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// compressedPrefix returns the length of the decompressed content of target if src starts with it, otherwise 0,
+// which is the case of a file recreated by a buffered write after its compression.
+func (h *DailyHandler) compressedPrefix(target string, src io.Reader) (int64, error) {
+	f, err := os.Open(target)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	r, err := h.compression.reader(f)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+	var n int64
+	buf, want := make([]byte, 32*1024), make([]byte, 32*1024)
+	for {
+		m, err := r.Read(buf)
+		if m > 0 {
+			if k, _ := io.ReadFull(src, want[:m]); k < m || !bytes.Equal(buf[:m], want[:m]) {
+				return 0, nil
+			}
+			n += int64(m)
+		}
+		if errors.Is(err, io.EOF) {
+			return n, nil
+		} else if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// compressLeftovers compresses the files found beside their compressed files,
+// left by a crash before the removal of the original or recreated by a buffered write,
+// without compressing the same records twice.
+func (h *DailyHandler) compressLeftovers() error {
+	if h.compression == CompressionNone {
+		return nil
+	}
+	entries, err := os.ReadDir(h.dir)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() || isCompressed(entry.Name()) {
+			continue
+		}
+		if _, _, _, ok := h.parseName(entry.Name()); !ok {
+			continue
+		}
+		file := filepath.Join(h.dir, entry.Name())
+		if _, err := os.Stat(file + h.compression.ext()); err != nil {
+			continue
+		}
+		if err := h.compressFile(file); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,38 @@
+package daily
+
+import (
+	"fmt"
+
+	. "github.com/gopi-frame/contract/exception"
+	"github.com/gopi-frame/exception"
+)
+
+type InvalidPatternException struct {
+	Throwable
+}
+
+func NewInvalidPatternException(pattern string, reason string) *InvalidPatternException {
+	return &InvalidPatternException{
+		Throwable: exception.New(fmt.Sprintf("invalid pattern [%s]: %s", pattern, reason)),
+	}
+}
+
+type InvalidPeriodException struct {
+	Throwable
+}
+
+func NewInvalidPeriodException(period string) *InvalidPeriodException {
+	return &InvalidPeriodException{
+		Throwable: exception.New(fmt.Sprintf("invalid period [%s]", period)),
+	}
+}
+
+type InvalidCompressionException struct {
+	Throwable
+}
+
+func NewInvalidCompressionException(compression string) *InvalidCompressionException {
+	return &InvalidCompressionException{
+		Throwable: exception.New(fmt.Sprintf("invalid compression [%s]", compression)),
+	}
+}
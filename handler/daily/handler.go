@@ -1,15 +1,24 @@
 package daily
 
 import (
-	"compress/gzip"
+	"cmp"
+	"errors"
 	"fmt"
 	"github.com/go-viper/mapstructure/v2"
 	"github.com/gopi-frame/env"
 	"github.com/gopi-frame/logger"
+	"github.com/gopi-frame/logger/handler/internal/filewriter"
+	"github.com/gopi-frame/logger/handler/internal/flock"
+	"github.com/gopi-frame/logger/handler/internal/strftime"
+	"github.com/gopi-frame/logger/handler/internal/symlink"
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -21,41 +30,172 @@ func init() {
 		logger.RegisterHandler(handlerName, func(config map[string]any) (io.WriteCloser, error) {
 			return NewDailyHandlerFromConfig(config)
 		})
+		logger.RegisterHandlerValidator(handlerName, func(config map[string]any) error {
+			filename, opts, err := parseConfig(config)
+			if err != nil {
+				return err
+			}
+			return newDailyHandler(filename, opts...).configure()
+		})
 	}
 }
 
+// DailyHandler appends to a file per period, named like app.2006-01-02.log for the filename app.log.
+// The period is a day by default, see [WithPeriod], and the time part of the names is set by [WithPattern].
+// With [WithMaxSize], the file of a period is rolled when it would exceed the max size,
+// the next files are named like app.2006-01-02.1.log, app.2006-01-02.2.log, etc.
+// By default, the file is opened and closed on every write,
+// with [WithPersistent] it is kept open by a mutex-protected writer, see [filewriter.Writer].
+// With [WithSymlink], a symlink like app.log always points to the current file.
+// With [WithLock], the processes sharing the files coordinate by advisory locks, see [WithLock].
 type DailyHandler struct {
-	filename string
-	mode     os.FileMode
-	dir      string
-	current  string
-	maxAge   int
-	compress bool
-	now      func() time.Time // for testing
+	mu               sync.Mutex
+	filename         string
+	mode             os.FileMode
+	dir              string
+	current          string
+	period           Period
+	source           string
+	pattern          *strftime.Pattern
+	matcher          *regexp.Regexp
+	location         *time.Location
+	stamp            string
+	start            time.Time
+	index            int
+	size             int64
+	maxSize          int64
+	maxAge           int
+	maxBackups       int
+	maxTotal         int64
+	compression      Compression
+	compressionLevel int
+	retryDelay       time.Duration
+	symlink          string
+	persistent       bool
+	options          filewriter.Options
+	writer           *filewriter.Writer
+	lock             *flock.Mutex
+	background       background
+	closed           bool
+	now              func() time.Time // for testing
 }
 
 // NewDailyHandler creates a new daily log handler.
 func NewDailyHandler(filename string, opts ...Option) (*DailyHandler, error) {
-	handler := &DailyHandler{
-		filename: filepath.Base(filename),
-		dir:      filepath.Dir(filename),
-		now:      time.Now,
+	handler := newDailyHandler(filename, opts...)
+	if err := handler.configure(); err != nil {
+		return nil, err
 	}
 	if err := os.MkdirAll(handler.dir, 0755); err != nil {
 		return nil, err
 	}
+	if handler.options.Lock {
+		handler.lock = flock.New(filepath.Join(handler.dir, "."+handler.filename+".lock"))
+	}
+	// with the lock, no compression of another process is running
+	_ = handler.locked(func() error {
+		handler.removeTemporaryFiles()
+		return nil
+	})
+	// the compressions interrupted after the compressed file was complete are finished
+	handler.background.add(func() {
+		_ = handler.locked(handler.compressLeftovers)
+	})
+	if handler.persistent {
+		handler.writer = filewriter.New("", handler.mode, handler.options)
+	}
+	return handler, nil
+}
+
+func newDailyHandler(filename string, opts ...Option) *DailyHandler {
+	handler := &DailyHandler{
+		filename:    filepath.Base(filename),
+		dir:         filepath.Dir(filename),
+		period:      PeriodDaily,
+		location:    time.Local,
+		compression: CompressionNone,
+		retryDelay:  time.Second,
+		now:         time.Now,
+	}
 	for _, opt := range opts {
 		opt(handler)
 	}
-	return handler, nil
+	return handler
+}
+
+// configure checks the period, the pattern and the compression, no file is touched.
+func (h *DailyHandler) configure() error {
+	if _, ok := defaultPatterns[h.period]; !ok {
+		return NewInvalidPeriodException(string(h.period))
+	}
+	if h.source == "" {
+		h.source = defaultPatterns[h.period]
+	}
+	p, err := strftime.Parse(h.source)
+	if err != nil {
+		return NewInvalidPatternException(h.source, err.Error())
+	}
+	if p.Groups() == 0 {
+		return NewInvalidPatternException(h.source, "no time token")
+	}
+	// without a year, the files of every year share the names and maxAge removes all of them
+	if !p.Has("Yy") {
+		return NewInvalidPatternException(h.source, "no year token")
+	}
+	// the start of a period is formatted and parsed back, which fails if the pattern is coarser than the period
+	sample := h.period.start(time.Date(2031, 12, 31, 23, 59, 59, 0, time.UTC))
+	groups := regexp.MustCompile(`^` + p.Regexp() + `$`).FindStringSubmatch(p.Format(sample))
+	if !p.Time(groups[1:], time.UTC).Equal(sample) {
+		return NewInvalidPatternException(h.source, fmt.Sprintf("less precise than the %s period", h.period))
+	}
+	h.pattern = p
+	ext := filepath.Ext(h.filename)
+	prefix := h.filename[:len(h.filename)-len(ext)]
+	h.matcher = regexp.MustCompile(`^` + regexp.QuoteMeta(prefix+".") + `(` + p.Regexp() + `)(?:\.(\d+))?` + regexp.QuoteMeta(ext) + `(?:\.gz|\.zst)?$`)
+	if h.compression, err = ParseCompression(string(h.compression)); err != nil {
+		return err
+	}
+	if h.compression != CompressionNone {
+		w, err := h.compression.writer(io.Discard, h.compressionLevel)
+		if err != nil {
+			return err
+		}
+		_ = w.Close()
+	}
+	return nil
 }
 
 func NewDailyHandlerFromConfig(config map[string]any) (*DailyHandler, error) {
+	filename, opts, err := parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return NewDailyHandler(filename, opts...)
+}
+
+// parseConfig decodes the config into the arguments and the options of [NewDailyHandler].
+func parseConfig(config map[string]any) (string, []Option, error) {
 	var cfg struct {
-		Filename string
-		Mode     uint32
-		MaxAge   int
-		Compress bool
+		Filename         string
+		Mode             uint32
+		MaxSize          int
+		MaxAge           int
+		MaxBackups       int
+		MaxTotalSize     int
+		Compress         bool
+		Compression      string
+		CompressionLevel int
+		Symlink          string
+		Pattern          string
+		Period           string
+		Timezone         string
+		UTC              bool
+		Persistent       bool
+		BufferSize       int
+		FlushInterval    time.Duration
+		FsyncEvery       int
+		FsyncInterval    time.Duration
+		Lock             bool
 	}
 	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
 		Result:           &cfg,
@@ -65,112 +205,371 @@ func NewDailyHandlerFromConfig(config map[string]any) (*DailyHandler, error) {
 		},
 		DecodeHook: mapstructure.ComposeDecodeHookFunc(
 			env.ExpandStringWithEnvHookFunc(),
+			mapstructure.StringToTimeDurationHookFunc(),
 			mapstructure.StringToBasicTypeHookFunc(),
 		),
 	})
 	if err != nil {
-		return nil, err
+		return "", nil, err
 	}
 	if err := decoder.Decode(config); err != nil {
-		return nil, err
+		return "", nil, err
+	}
+	period := PeriodDaily
+	if cfg.Period != "" {
+		if period, err = ParsePeriod(cfg.Period); err != nil {
+			return "", nil, err
+		}
+	}
+	compression := CompressionNone
+	if cfg.Compress {
+		compression = CompressionGzip
 	}
-	return NewDailyHandler(cfg.Filename, WithFileMode(os.FileMode(cfg.Mode)), WithMaxAge(cfg.MaxAge), WithCompress(cfg.Compress))
+	if cfg.Compression != "" {
+		if compression, err = ParseCompression(cfg.Compression); err != nil {
+			return "", nil, err
+		}
+	}
+	location := time.Local
+	if cfg.UTC {
+		location = time.UTC
+	} else if cfg.Timezone != "" {
+		if location, err = time.LoadLocation(cfg.Timezone); err != nil {
+			return "", nil, err
+		}
+	}
+	return cfg.Filename, []Option{
+		WithFileMode(os.FileMode(cfg.Mode)),
+		WithPeriod(period),
+		WithPattern(cfg.Pattern),
+		WithLocation(location),
+		WithMaxSize(cfg.MaxSize),
+		WithMaxAge(cfg.MaxAge),
+		WithMaxBackups(cfg.MaxBackups),
+		WithMaxTotalSize(cfg.MaxTotalSize),
+		WithCompression(compression),
+		WithCompressionLevel(cfg.CompressionLevel),
+		WithSymlink(cfg.Symlink),
+		WithPersistent(cfg.Persistent),
+		WithBufferSize(cfg.BufferSize),
+		WithFlushInterval(cfg.FlushInterval),
+		WithFsyncEvery(cfg.FsyncEvery),
+		WithFsyncInterval(cfg.FsyncInterval),
+		WithLock(cfg.Lock),
+	}, nil
 }
 
-func (h *DailyHandler) openExistingOrNew() (*os.File, error) {
-	filename := filepath.Join(h.dir,
-		fmt.Sprintf("%s.%s%s",
-			filepath.Base(h.filename[:len(h.filename)-len(filepath.Ext(h.filename))]),
-			h.now().Format("2006-01-02"),
-			filepath.Ext(h.filename)))
+// name returns the file name of the given stamp and index, like app.2006-01-02.log or app.2006-01-02.1.log,
+// the stamp is the start of a period formatted by the pattern.
+func (h *DailyHandler) name(stamp string, index int) string {
+	ext := filepath.Ext(h.filename)
+	prefix := h.filename[:len(h.filename)-len(ext)]
+	if index == 0 {
+		return filepath.Join(h.dir, fmt.Sprintf("%s.%s%s", prefix, stamp, ext))
+	}
+	return filepath.Join(h.dir, fmt.Sprintf("%s.%s.%d%s", prefix, stamp, index, ext))
+}
 
-	if h.current != filename {
-		if h.current != "" && h.compress {
-			_ = h.compressFile(h.current)
+// parseName parses the stamp, its time and the index of a file written by the handler,
+// the file may be compressed.
+func (h *DailyHandler) parseName(name string) (stamp string, t time.Time, index int, ok bool) {
+	matches := h.matcher.FindStringSubmatch(name)
+	if matches == nil {
+		return "", time.Time{}, 0, false
+	}
+	groups := h.pattern.Groups()
+	if indexStr := matches[2+groups]; indexStr != "" {
+		var err error
+		if index, err = strconv.Atoi(indexStr); err != nil || index <= 0 {
+			return "", time.Time{}, 0, false
+		}
+	}
+	return matches[1], h.pattern.Time(matches[2:2+groups], h.location), index, true
+}
+
+// lastFile returns the index and size of the file to continue writing for the given stamp.
+// It is the last file of the stamp, or the next one if the last file has been compressed,
+// even if the original is left beside the compressed file.
+func (h *DailyHandler) lastFile(stamp string) (int, int64) {
+	entries, err := os.ReadDir(h.dir)
+	if err != nil {
+		return 0, 0
+	}
+	last, compressed := -1, false
+	for _, entry := range entries {
+		fileStamp, _, index, ok := h.parseName(entry.Name())
+		if !ok || fileStamp != stamp {
+			continue
+		}
+		if index > last {
+			last, compressed = index, isCompressed(entry.Name())
+		} else if index == last && isCompressed(entry.Name()) {
+			compressed = true
+		}
+	}
+	if last < 0 {
+		return 0, 0
+	}
+	if compressed {
+		return last + 1, 0
+	}
+	info, err := os.Stat(h.name(stamp, last))
+	if err != nil {
+		return last, 0
+	}
+	return last, info.Size()
+}
+
+// currentFile returns the file to write n bytes to.
+// The file changes when the period changes, or when the file would exceed the max size,
+// then the previous file is closed if the handler is persistent, the symlink is updated,
+// and the previous file is compressed if enabled and the backups are cleaned in the background.
+func (h *DailyHandler) currentFile(n int) (string, error) {
+	start := h.period.start(h.now().In(h.location))
+	if stamp := h.pattern.Format(start); stamp != h.stamp {
+		h.stamp, h.start = stamp, start
+		h.index, h.size = h.lastFile(stamp)
+	} else if h.lock != nil {
+		// another process may have written to the file, or rolled it
+		if info, err := os.Stat(h.current); err == nil {
+			h.size = max(h.size, info.Size())
+		} else {
+			h.index, h.size = h.lastFile(stamp)
+		}
+	}
+	if h.maxSize > 0 && h.size > 0 && h.size+int64(n) > h.maxSize {
+		h.index++
+		h.size = 0
+		if h.lock != nil {
+			// another process may have rolled the file already
+			if index, size := h.lastFile(h.stamp); index > h.index {
+				h.index, h.size = index, size
+			}
 		}
+	}
+	filename := h.name(h.stamp, h.index)
+	if h.current != filename {
+		previous := h.current
 		h.current = filename
-		if h.maxAge > 0 {
-			go func() {
-				_ = h.cleanOldFiles()
-			}()
+		// with the lock, the previous file is compressed and the backups are cleaned
+		// only by the process switching to a new file, the others switch to an existing one
+		_, err := os.Stat(filename)
+		rolled := h.lock == nil || errors.Is(err, os.ErrNotExist)
+		if h.writer != nil {
+			if err := h.writer.SetFilename(filename); err != nil {
+				return "", err
+			}
+		}
+		if h.symlink != "" {
+			_ = symlink.Update(h.symlink, filename)
+		}
+		// compressions and cleanups run in the background one at a time, in order,
+		// so a cleanup never sees a file being compressed
+		if rolled && previous != "" && h.compression != CompressionNone {
+			h.background.add(func() {
+				_ = h.locked(func() error {
+					return h.compressFile(previous)
+				})
+			})
+		}
+		if rolled && (h.maxAge > 0 || h.maxBackups > 0 || h.maxTotal > 0) {
+			var cutoff time.Time
+			if h.maxAge > 0 {
+				// files are kept for maxAge days before the start of the current period
+				cutoff = h.start.AddDate(0, 0, -h.maxAge)
+			}
+			h.background.add(func() {
+				_ = h.locked(func() error {
+					return h.cleanOldFiles(cutoff, filename)
+				})
+			})
 		}
 	}
-	return os.OpenFile(filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, h.mode)
+	return filename, nil
 }
 
-func (h *DailyHandler) cleanOldFiles() error {
-	if h.maxAge <= 0 {
-		return nil
+func (h *DailyHandler) openExistingOrNew(n int) (*os.File, error) {
+	filename, err := h.currentFile(n)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, h.mode)
+	if err != nil {
+		return nil, err
 	}
+	if h.lock != nil {
+		// the lock is released when the file is closed
+		if err := flock.Lock(file); err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+	}
+	return file, nil
+}
+
+// locked runs f holding the lock shared with the other processes, if the handler is locking.
+func (h *DailyHandler) locked(f func() error) error {
+	if h.lock == nil {
+		return f()
+	}
+	if err := h.lock.Lock(); err != nil {
+		return err
+	}
+	defer func() {
+		_ = h.lock.Unlock()
+	}()
+	return f()
+}
+
+// backup is a file written by the handler before the current one.
+type backup struct {
+	name  string
+	start time.Time
+	index int
+	size  int64
+}
+
+// cleanOldFiles removes the backups, compressed or not, of the periods starting before cutoff if it is not zero,
+// then removes the oldest backups until at most maxBackups are kept,
+// and the total size of the backups and the current file is at most maxTotalSize.
+// Files of the current period and index or later are never removed.
+// The names are parsed by the pattern of the handler, other files are left untouched.
+func (h *DailyHandler) cleanOldFiles(cutoff time.Time, current string) error {
 	entries, err := os.ReadDir(h.dir)
 	if err != nil {
 		return err
 	}
-	cutoff := h.now().AddDate(0, 0, -h.maxAge)
-	base := filepath.Base(h.filename)
-	ext := filepath.Ext(base)
-	prefix := base[:len(base)-len(ext)]
+	_, currentStart, currentIndex, _ := h.parseName(filepath.Base(current))
+	var errs []error
+	var backups []backup
+	var total int64
 	for _, entry := range entries {
 		if entry.IsDir() {
 			continue
 		}
-		name := entry.Name()
-		if !strings.HasPrefix(name, prefix) {
+		_, start, index, ok := h.parseName(entry.Name())
+		if !ok {
 			continue
 		}
-		// Parse date from filename
-		dateStr := strings.TrimPrefix(name, prefix+".")
-		dateStr = strings.TrimSuffix(dateStr, ext)
-		dateStr = strings.TrimSuffix(dateStr, ext+".gz")
-		fileDate, err := time.Parse("2006-01-02", dateStr)
+		info, err := entry.Info()
 		if err != nil {
 			continue
 		}
-		if fileDate.Before(cutoff) {
-			_ = os.Remove(filepath.Join(h.dir, name))
+		// a cleanup may run late, the files written since are never removed
+		if cmp.Or(start.Compare(currentStart), cmp.Compare(index, currentIndex)) >= 0 {
+			total += info.Size()
+			continue
 		}
+		name := filepath.Join(h.dir, entry.Name())
+		if !cutoff.IsZero() && start.Before(cutoff) {
+			if err := os.Remove(name); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		backups = append(backups, backup{name: name, start: start, index: index, size: info.Size()})
+		total += info.Size()
 	}
-	return nil
+	// oldest first
+	slices.SortFunc(backups, func(a, b backup) int {
+		return cmp.Or(a.start.Compare(b.start), cmp.Compare(a.index, b.index))
+	})
+	remaining := len(backups)
+	for _, b := range backups {
+		if !(h.maxBackups > 0 && remaining > h.maxBackups) && !(h.maxTotal > 0 && total > h.maxTotal) {
+			break
+		}
+		if err := os.Remove(b.name); err != nil {
+			errs = append(errs, err)
+		}
+		remaining--
+		total -= b.size
+	}
+	return errors.Join(errs...)
 }
 
-func (h *DailyHandler) compressFile(file string) error {
-	f, err := os.Open(file)
+// removeTemporaryFiles removes the temporary files left by the compressions interrupted by a crash.
+func (h *DailyHandler) removeTemporaryFiles() {
+	entries, err := os.ReadDir(h.dir)
 	if err != nil {
-		return err
+		return
 	}
-	gzFile := file + ".gz"
-	gzf, err := os.Create(gzFile)
-	if err != nil {
-		return err
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".tmp")
+		if !ok || !isCompressed(name) {
+			continue
+		}
+		if _, _, _, ok := h.parseName(name); ok {
+			_ = os.Remove(filepath.Join(h.dir, entry.Name()))
+		}
 	}
-	defer func(gzf *os.File) {
-		_ = gzf.Close()
-	}(gzf)
-	gzw := gzip.NewWriter(gzf)
-	defer func(gzw *gzip.Writer) {
-		_ = gzw.Close()
-	}(gzw)
-	if _, err := io.Copy(gzw, f); err != nil {
-		return err
+}
+
+func (h *DailyHandler) Write(p []byte) (n int, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		// a closed handler neither rolls nor compresses the files anymore
+		return 0, os.ErrClosed
 	}
-	if err := f.Close(); err != nil {
+	err = h.locked(func() error {
+		n, err = h.write(p)
 		return err
-	}
-	return os.Remove(file)
+	})
+	return n, err
 }
 
-func (h *DailyHandler) Write(p []byte) (int, error) {
-	file, err := h.openExistingOrNew()
+func (h *DailyHandler) write(p []byte) (int, error) {
+	if h.writer != nil {
+		if _, err := h.currentFile(len(p)); err != nil {
+			return 0, err
+		}
+		n, err := h.writer.Write(p)
+		h.size += int64(n)
+		return n, err
+	}
+	file, err := h.openExistingOrNew(len(p))
 	if err != nil {
 		return 0, err
 	}
 	n, err := file.Write(p)
+	h.size += int64(n)
 	if err1 := file.Close(); err1 != nil && err == nil {
 		err = err1
 	}
 	return n, err
 }
 
-func (h *DailyHandler) Close() error {
+// Sync flushes the buffer and fsyncs the file of a persistent handler.
+func (h *DailyHandler) Sync() error {
+	if h.writer != nil {
+		return h.writer.Sync()
+	}
 	return nil
 }
+
+// Reopen closes the file of a persistent handler, it is opened again by its name on the next write.
+// Without [WithPersistent], it does nothing, the file is opened by name on every write.
+func (h *DailyHandler) Reopen() error {
+	if h.writer != nil {
+		return h.writer.Reopen()
+	}
+	return nil
+}
+
+// Close waits for the pending compressions and cleanups, and closes the file of a persistent handler
+// and the lock file. Writes after Close fail with [os.ErrClosed].
+func (h *DailyHandler) Close() error {
+	h.mu.Lock()
+	h.closed = true
+	h.mu.Unlock()
+	h.background.wait()
+	var err error
+	if h.writer != nil {
+		err = h.writer.Close()
+	}
+	if h.lock != nil {
+		err = errors.Join(err, h.lock.Close())
+	}
+	return err
+}
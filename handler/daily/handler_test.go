@@ -1,10 +1,17 @@
 package daily
 
 import (
+	"bytes"
+	"compress/gzip"
 	"fmt"
+	"github.com/gopi-frame/logger"
+	"github.com/klauspost/compress/zstd"
 	"github.com/stretchr/testify/assert"
+	"io"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -68,6 +75,7 @@ func TestNewDailyHandlerFromConfig(t *testing.T) {
 			}
 			assert.Equal(t, "test", string(content))
 			if i > 0 {
+				fh.background.wait()
 				_, err := os.Stat(fmt.Sprintf("testdata/test.%s.log.gz", now.Add(-time.Hour*24).Format("2006-01-02")))
 				if !assert.NoError(t, err) {
 					assert.FailNow(t, err.Error())
@@ -119,6 +127,7 @@ func TestNewDailyHandlerFromConfig(t *testing.T) {
 			}
 			assert.Equal(t, "test", string(content))
 			if i > 0 {
+				fh.background.wait()
 				_, err := os.Stat(fmt.Sprintf("testdata/test.%s.log.gz", now.Add(-time.Hour*24).Format("2006-01-02")))
 				if !assert.NoError(t, err) {
 					assert.FailNow(t, err.Error())
@@ -126,6 +135,7 @@ func TestNewDailyHandlerFromConfig(t *testing.T) {
 			}
 			now = now.Add(time.Hour * 24)
 		}
+		fh.background.wait()
 		entries, err := os.ReadDir("testdata")
 		if !assert.NoError(t, err) {
 			assert.FailNow(t, err.Error())
@@ -143,4 +153,504 @@ func TestNewDailyHandlerFromConfig(t *testing.T) {
 		assert.Equal(t, 7, count)
 		_ = os.RemoveAll("testdata")
 	})
+	t.Run("persistent", func(t *testing.T) {
+		fh, err := NewDailyHandlerFromConfig(map[string]any{
+			"filename":    "testdata/test.log",
+			"mode":        0644,
+			"compress":    true,
+			"persistent":  true,
+			"fsyncEvery":  1,
+			"buffer_size": 1024,
+		})
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		now := time.Now()
+		fh.now = func() time.Time {
+			return now
+		}
+		for i := 0; i < 3; i++ {
+			for j := 0; j < 2; j++ {
+				_, err := fh.Write([]byte("test"))
+				if !assert.NoError(t, err) {
+					assert.FailNow(t, err.Error())
+				}
+			}
+			content, err := os.ReadFile(fmt.Sprintf("testdata/test.%s.log", now.Format("2006-01-02")))
+			if !assert.NoError(t, err) {
+				assert.FailNow(t, err.Error())
+			}
+			assert.Equal(t, "testtest", string(content))
+			if i > 0 {
+				fh.background.wait()
+				_, err := os.Stat(fmt.Sprintf("testdata/test.%s.log.gz", now.Add(-time.Hour*24).Format("2006-01-02")))
+				if !assert.NoError(t, err) {
+					assert.FailNow(t, err.Error())
+				}
+			}
+			now = now.Add(time.Hour * 24)
+		}
+		assert.NoError(t, fh.Close())
+		_, err = fh.Write([]byte("test"))
+		assert.ErrorIs(t, err, os.ErrClosed)
+		_ = os.RemoveAll("testdata")
+	})
+	t.Run("with maxSize", func(t *testing.T) {
+		fh, err := NewDailyHandlerFromConfig(map[string]any{
+			"filename": "testdata/test.log",
+			"mode":     0644,
+			"maxSize":  1,
+			"maxAge":   1,
+			"compress": true,
+		})
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		assert.Equal(t, int64(1024*1024), fh.maxSize)
+		fh.maxSize = 10
+		now := time.Now()
+		fh.now = func() time.Time {
+			return now
+		}
+		date := now.Format("2006-01-02")
+		for i := 0; i < 5; i++ {
+			_, err := fh.Write([]byte("test"))
+			if !assert.NoError(t, err) {
+				assert.FailNow(t, err.Error())
+			}
+		}
+		_, err = fh.Write([]byte("a record larger than the max size"))
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		fh.background.wait()
+		for name, content := range map[string]string{
+			"test." + date + ".log.gz":   "testtest",
+			"test." + date + ".1.log.gz": "testtest",
+			"test." + date + ".2.log.gz": "test",
+			"test." + date + ".3.log":    "a record larger than the max size",
+		} {
+			assert.Equal(t, content, readFile(t, "testdata/"+name), name)
+		}
+
+		// continue with the last file of the day
+		fh, err = NewDailyHandler("testdata/test.log", WithMaxAge(1), WithCompress(true))
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		fh.now = func() time.Time {
+			return now
+		}
+		_, err = fh.Write([]byte("!"))
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		assert.Equal(t, "a record larger than the max size!", readFile(t, "testdata/test."+date+".3.log"))
+
+		// clean the files of all indexes
+		now = now.AddDate(0, 0, 2)
+		_, err = fh.Write([]byte("test"))
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		assert.NoError(t, fh.Close())
+		entries, err := os.ReadDir("testdata")
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		assert.Equal(t, []string{"test." + now.Format("2006-01-02") + ".log"}, names)
+		_ = os.RemoveAll("testdata")
+	})
+
+	t.Run("with maxBackups and maxTotalSize", func(t *testing.T) {
+		fh, err := NewDailyHandlerFromConfig(map[string]any{
+			"filename":     "testdata/test.log",
+			"mode":         0644,
+			"maxBackups":   2,
+			"maxTotalSize": 1,
+			"compress":     true,
+		})
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		assert.Equal(t, 2, fh.maxBackups)
+		assert.Equal(t, int64(1024*1024), fh.maxTotal)
+		fh.maxSize = 10
+		fh.maxTotal = 0
+		now := time.Now()
+		fh.now = func() time.Time {
+			return now
+		}
+		date := now.Format("2006-01-02")
+		for i := 0; i < 4; i++ {
+			_, err := fh.Write([]byte("testtest"))
+			if !assert.NoError(t, err) {
+				assert.FailNow(t, err.Error())
+			}
+		}
+		// a compressed backup of an older day is the oldest
+		now = now.AddDate(0, 0, 1)
+		_, err = fh.Write([]byte("testtest"))
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		fh.background.wait()
+		names := func() []string {
+			entries, err := os.ReadDir("testdata")
+			if !assert.NoError(t, err) {
+				assert.FailNow(t, err.Error())
+			}
+			var names []string
+			for _, entry := range entries {
+				names = append(names, entry.Name())
+			}
+			return names
+		}
+		assert.Equal(t, []string{
+			"test." + date + ".2.log.gz",
+			"test." + date + ".3.log.gz",
+			"test." + now.Format("2006-01-02") + ".log",
+		}, names())
+
+		// the current file counts in the total size
+		fh.compression = CompressionNone
+		fh.maxBackups = 0
+		fh.maxTotal = 20
+		for i := 0; i < 3; i++ {
+			_, err := fh.Write([]byte("testtest"))
+			if !assert.NoError(t, err) {
+				assert.FailNow(t, err.Error())
+			}
+		}
+		assert.NoError(t, fh.Close())
+		assert.Equal(t, []string{
+			"test." + now.Format("2006-01-02") + ".2.log",
+			"test." + now.Format("2006-01-02") + ".3.log",
+		}, names())
+		_ = os.RemoveAll("testdata")
+	})
+
+	t.Run("with compression", func(t *testing.T) {
+		fh, err := NewDailyHandlerFromConfig(map[string]any{
+			"filename":          "testdata/test.log",
+			"mode":              0644,
+			"compression":       "zstd",
+			"compression_level": 19,
+		})
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		assert.Equal(t, CompressionZstd, fh.compression)
+		assert.Equal(t, 19, fh.compressionLevel)
+		assert.NoError(t, fh.Close())
+		// left by a crash during a compression
+		if err := os.WriteFile("testdata/test.2000-01-01.log.zst.tmp", []byte("partial"), 0644); !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		fh, err = NewDailyHandler("testdata/test.log", WithFileMode(0644), WithCompression(CompressionZstd))
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		_, err = os.Stat("testdata/test.2000-01-01.log.zst.tmp")
+		assert.ErrorIs(t, err, os.ErrNotExist)
+		now := time.Now()
+		fh.now = func() time.Time {
+			return now
+		}
+		date := now.Format("2006-01-02")
+		for i := 0; i < 2; i++ {
+			_, err := fh.Write([]byte("test"))
+			if !assert.NoError(t, err) {
+				assert.FailNow(t, err.Error())
+			}
+			now = now.AddDate(0, 0, 1)
+		}
+		assert.NoError(t, fh.Close())
+		assert.Equal(t, "test", readFile(t, "testdata/test."+date+".log.zst"))
+		_, err = os.Stat("testdata/test." + date + ".log")
+		assert.ErrorIs(t, err, os.ErrNotExist)
+
+		// a file recreated after its compression is appended to the compressed file
+		if err := os.WriteFile("testdata/test."+date+".log", []byte("late"), 0644); !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		assert.NoError(t, fh.compressFile("testdata/test."+date+".log"))
+		assert.Equal(t, "testlate", readFile(t, "testdata/test."+date+".log.zst"))
+
+		// a failed compression is retried, and leaves no partial file
+		fh.retryDelay = time.Millisecond
+		fh.compression = Compression("unknown")
+		err = fh.compressFile("testdata/test." + now.AddDate(0, 0, -1).Format("2006-01-02") + ".log")
+		assert.IsType(t, new(InvalidCompressionException), err)
+		entries, err := os.ReadDir("testdata")
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		assert.Len(t, entries, 2)
+		_ = os.RemoveAll("testdata")
+	})
+
+	t.Run("compression interrupted by a crash", func(t *testing.T) {
+		fh, err := NewDailyHandler("testdata/test.log", WithFileMode(0644), WithCompression(CompressionGzip))
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		today := "testdata/test." + time.Now().Format("2006-01-02") + ".log"
+		yesterday := "testdata/test." + time.Now().AddDate(0, 0, -1).Format("2006-01-02") + ".log"
+		for _, file := range []string{today, yesterday} {
+			if err := os.WriteFile(file, []byte("record\n"), 0644); !assert.NoError(t, err) {
+				assert.FailNow(t, err.Error())
+			}
+			assert.NoError(t, fh.compressFile(file))
+		}
+		assert.NoError(t, fh.Close())
+		// the originals are left beside the complete compressed files by a crash before their removal,
+		// and another record has been written to the one of yesterday in the meantime
+		assert.NoError(t, os.WriteFile(today, []byte("record\n"), 0644))
+		assert.NoError(t, os.WriteFile(yesterday, []byte("record\nmore\n"), 0644))
+
+		fh, err = NewDailyHandler("testdata/test.log", WithFileMode(0644), WithCompression(CompressionGzip))
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		// the compressed file of today is not written to again
+		_, err = fh.Write([]byte("test"))
+		assert.NoError(t, err)
+		assert.NoError(t, fh.Close())
+		assert.Equal(t, "test", readFile(t, strings.TrimSuffix(today, ".log")+".1.log"))
+		assert.Equal(t, "record\n", readFile(t, today+".gz"))
+		assert.Equal(t, "record\nmore\n", readFile(t, yesterday+".gz"))
+		assert.NoFileExists(t, today)
+		assert.NoFileExists(t, yesterday)
+		_ = os.RemoveAll("testdata")
+	})
+
+	t.Run("with lock", func(t *testing.T) {
+		// two handlers on the same files behave like two processes
+		var handlers []*DailyHandler
+		for i := 0; i < 2; i++ {
+			fh, err := NewDailyHandlerFromConfig(map[string]any{
+				"filename":   "testdata/test.log",
+				"mode":       0644,
+				"compress":   true,
+				"lock":       true,
+				"persistent": i == 1,
+				"bufferSize": 8192,
+			})
+			if !assert.NoError(t, err) {
+				assert.FailNow(t, err.Error())
+			}
+			fh.maxSize = 20000
+			handlers = append(handlers, fh)
+		}
+		var wg sync.WaitGroup
+		for i, fh := range handlers {
+			for j := 0; j < 2; j++ {
+				wg.Add(1)
+				go func(letter byte) {
+					defer wg.Done()
+					// larger than PIPE_BUF
+					record := append(bytes.Repeat([]byte{letter}, 5000), '\n')
+					for k := 0; k < 20; k++ {
+						if _, err := fh.Write(record); !assert.NoError(t, err) {
+							return
+						}
+					}
+				}(byte('a' + i*2 + j))
+			}
+		}
+		wg.Wait()
+		for _, fh := range handlers {
+			assert.NoError(t, fh.Close())
+		}
+		entries, err := os.ReadDir("testdata")
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		lines := 0
+		for _, entry := range entries {
+			if entry.Name() == ".test.log.lock" {
+				continue
+			}
+			for _, line := range strings.Split(strings.TrimSuffix(readFile(t, "testdata/"+entry.Name()), "\n"), "\n") {
+				assert.Equal(t, strings.Repeat(line[:1], 5000), line, entry.Name())
+				lines++
+			}
+		}
+		assert.Equal(t, 80, lines)
+		_ = os.RemoveAll("testdata")
+	})
+
+	t.Run("with symlink", func(t *testing.T) {
+		fh, err := NewDailyHandlerFromConfig(map[string]any{
+			"filename": "testdata/test.log",
+			"mode":     0644,
+			"symlink":  "testdata/test.log",
+		})
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		now := time.Now()
+		fh.now = func() time.Time {
+			return now
+		}
+		for i := 0; i < 3; i++ {
+			_, err := fh.Write([]byte("test" + strconv.Itoa(i)))
+			if !assert.NoError(t, err) {
+				assert.FailNow(t, err.Error())
+			}
+			target, err := os.Readlink("testdata/test.log")
+			assert.NoError(t, err)
+			assert.Equal(t, "test."+now.Format("2006-01-02")+".log", target)
+			assert.Equal(t, "test"+strconv.Itoa(i), readFile(t, "testdata/test.log"))
+			now = now.AddDate(0, 0, 1)
+		}
+		assert.NoError(t, fh.Close())
+		_ = os.RemoveAll("testdata")
+	})
+
+	t.Run("with period and pattern", func(t *testing.T) {
+		fh, err := NewDailyHandlerFromConfig(map[string]any{
+			"filename": "testdata/test.log",
+			"mode":     0644,
+			"period":   "hourly",
+			"pattern":  "%Y%m%d%H",
+			"utc":      true,
+			"maxAge":   1,
+		})
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		// a file of the same name but another pattern is not cleaned
+		if err := os.WriteFile("testdata/test.2000-01-01.log", []byte("other"), 0644); !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		now := time.Date(2026, 10, 17, 22, 30, 0, 0, time.FixedZone("UTC+8", 8*3600))
+		fh.now = func() time.Time {
+			return now
+		}
+		for i := 0; i < 30; i++ {
+			_, err := fh.Write([]byte("test"))
+			if !assert.NoError(t, err) {
+				assert.FailNow(t, err.Error())
+			}
+			assert.Equal(t, "test", readFile(t, "testdata/test."+now.UTC().Format("2006010215")+".log"))
+			now = now.Add(time.Hour)
+		}
+		assert.NoError(t, fh.Close())
+		entries, err := os.ReadDir("testdata")
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		// the last day is kept before the start of the current hour
+		assert.Equal(t, 26, len(entries))
+		assert.Equal(t, "test.2000-01-01.log", entries[0].Name())
+		assert.Equal(t, "test.2026101719.log", entries[1].Name())
+		_ = os.RemoveAll("testdata")
+	})
+
+	t.Run("with timezone", func(t *testing.T) {
+		fh, err := NewDailyHandlerFromConfig(map[string]any{
+			"filename": "testdata/test.log",
+			"period":   "monthly",
+			"timezone": "UTC",
+		})
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		fh.now = func() time.Time {
+			return time.Date(2026, 11, 1, 2, 0, 0, 0, time.FixedZone("UTC+8", 8*3600))
+		}
+		_, err = fh.Write([]byte("test"))
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		assert.Equal(t, "test", readFile(t, "testdata/test.2026-10.log"))
+		_ = os.RemoveAll("testdata")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := NewDailyHandlerFromConfig(map[string]any{"filename": "testdata/test.log", "period": "yearly"})
+		assert.IsType(t, new(InvalidPeriodException), err)
+		_, err = NewDailyHandlerFromConfig(map[string]any{"filename": "testdata/test.log", "pattern": "%Y-%W"})
+		assert.IsType(t, new(InvalidPatternException), err)
+		_, err = NewDailyHandlerFromConfig(map[string]any{"filename": "testdata/test.log", "pattern": "today"})
+		assert.IsType(t, new(InvalidPatternException), err)
+		_, err = NewDailyHandlerFromConfig(map[string]any{"filename": "testdata/test.log", "pattern": "%m-%d"})
+		assert.ErrorContains(t, err, "no year token")
+		_, err = NewDailyHandlerFromConfig(map[string]any{"filename": "testdata/test.log", "pattern": "%Y%m"})
+		assert.ErrorContains(t, err, "less precise than the daily period")
+		_, err = NewDailyHandlerFromConfig(map[string]any{"filename": "testdata/test.log", "pattern": "%Y-%d"})
+		assert.IsType(t, new(InvalidPatternException), err)
+		_, err = NewDailyHandlerFromConfig(map[string]any{"filename": "testdata/test.log", "period": "hourly", "pattern": "%Y%m%d"})
+		assert.ErrorContains(t, err, "less precise than the hourly period")
+		_, err = NewDailyHandlerFromConfig(map[string]any{"filename": "testdata/test.log", "period": "weekly", "pattern": "%Y%m"})
+		assert.IsType(t, new(InvalidPatternException), err)
+		for _, pattern := range []string{"%y%m%d", "%Y%j", "%Y%m%d%H"} {
+			_, err = NewDailyHandlerFromConfig(map[string]any{"filename": "testdata/test.log", "pattern": pattern})
+			assert.NoError(t, err, pattern)
+		}
+		_, err = NewDailyHandlerFromConfig(map[string]any{"filename": "testdata/test.log", "compression": "lz4"})
+		assert.IsType(t, new(InvalidCompressionException), err)
+		_, err = NewDailyHandlerFromConfig(map[string]any{"filename": "testdata/test.log", "compression": "gzip", "compressionLevel": 42})
+		assert.Error(t, err)
+		_, err = NewDailyHandlerFromConfig(map[string]any{"filename": "testdata/test.log", "timezone": "Nowhere/Nothing"})
+		assert.Error(t, err)
+		_ = os.RemoveAll("testdata")
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		assert.NoError(t, logger.ValidateHandler(handlerName, map[string]any{"filename": "testdata/test.log"}))
+		assert.IsType(t, new(InvalidPeriodException), logger.ValidateHandler(handlerName, map[string]any{"filename": "testdata/test.log", "period": "yearly"}))
+		assert.IsType(t, new(InvalidPatternException), logger.ValidateHandler(handlerName, map[string]any{"filename": "testdata/test.log", "pattern": "today"}))
+		assert.IsType(t, new(InvalidCompressionException), logger.ValidateHandler(handlerName, map[string]any{"filename": "testdata/test.log", "compression": "lz4"}))
+		assert.NoDirExists(t, "testdata")
+	})
+}
+
+func TestPeriod_start(t *testing.T) {
+	for period, expected := range map[Period]time.Time{
+		PeriodMinute:  time.Date(2026, 10, 17, 13, 45, 0, 0, time.UTC),
+		PeriodHourly:  time.Date(2026, 10, 17, 13, 0, 0, 0, time.UTC),
+		PeriodDaily:   time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC),
+		PeriodWeekly:  time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC),
+		PeriodMonthly: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		assert.Equal(t, expected, period.start(time.Date(2026, 10, 17, 13, 45, 30, 0, time.UTC)), period)
+	}
+}
+
+// readFile reads a file, decompressing it if it is compressed with gzip or zstd.
+func readFile(t *testing.T, filename string) string {
+	f, err := os.Open(filename)
+	if !assert.NoError(t, err) {
+		assert.FailNow(t, err.Error())
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	var r io.Reader = f
+	if strings.HasSuffix(filename, ".gz") {
+		gr, err := gzip.NewReader(f)
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		r = gr
+	} else if strings.HasSuffix(filename, ".zst") {
+		zr, err := zstd.NewReader(f)
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		defer zr.Close()
+		r = zr
+	}
+	content, err := io.ReadAll(r)
+	if !assert.NoError(t, err) {
+		assert.FailNow(t, err.Error())
+	}
+	return string(content)
 }
@@ -1,18 +1,96 @@
 package daily
 
-import "os"
+import (
+	"os"
+	"time"
+)
+
+const megabyte = 1024 * 1024
 
 type Option func(h *DailyHandler)
 
+// WithMaxSize sets the max size in megabytes of a file before it is rolled within the day,
+// files are not rolled by size if it is not positive.
+func WithMaxSize(maxSize int) Option {
+	return func(h *DailyHandler) {
+		h.maxSize = int64(maxSize) * megabyte
+	}
+}
+
+// WithPeriod sets the period after which the file is rolled, [PeriodDaily] by default.
+func WithPeriod(period Period) Option {
+	return func(h *DailyHandler) {
+		h.period = period
+	}
+}
+
+// WithPattern sets the strftime-style pattern of the time part in the file names, e.g. %Y%m%d%H,
+// supported tokens are %Y, %y, %m, %d, %j, %H, %M, %S and %%.
+// The default depends on the period, e.g. %Y-%m-%d for [PeriodDaily] and %Y-%m-%d-%H for [PeriodHourly].
+// The pattern must contain a year and be at least as precise as the period, e.g. %Y%m is rejected for [PeriodDaily].
+func WithPattern(pattern string) Option {
+	return func(h *DailyHandler) {
+		h.source = pattern
+	}
+}
+
+// WithLocation sets the location in which the periods start and the file names are formatted,
+// [time.Local] by default.
+func WithLocation(location *time.Location) Option {
+	return func(h *DailyHandler) {
+		if location != nil {
+			h.location = location
+		}
+	}
+}
+
 func WithMaxAge(maxAge int) Option {
 	return func(h *DailyHandler) {
 		h.maxAge = maxAge
 	}
 }
 
+// WithMaxBackups sets the max number of files kept besides the current one, the oldest are removed first,
+// compressed or not. All files are kept if it is not positive.
+func WithMaxBackups(maxBackups int) Option {
+	return func(h *DailyHandler) {
+		h.maxBackups = maxBackups
+	}
+}
+
+// WithMaxTotalSize sets the max total size in megabytes of the files including the current one,
+// the oldest files but the current one are removed first until the total size is under the quota.
+// The size is not limited if it is not positive.
+func WithMaxTotalSize(maxTotalSize int) Option {
+	return func(h *DailyHandler) {
+		h.maxTotal = int64(maxTotalSize) * megabyte
+	}
+}
+
+// WithCompress compresses the rolled files with gzip, it is a shortcut of [WithCompression].
 func WithCompress(compress bool) Option {
 	return func(h *DailyHandler) {
-		h.compress = compress
+		if compress {
+			h.compression = CompressionGzip
+		} else {
+			h.compression = CompressionNone
+		}
+	}
+}
+
+// WithCompression sets the algorithm the rolled files are compressed with in the background,
+// [CompressionNone] by default.
+func WithCompression(compression Compression) Option {
+	return func(h *DailyHandler) {
+		h.compression = compression
+	}
+}
+
+// WithCompressionLevel sets the level of the compression, e.g. 1-9 for gzip and 1-22 for zstd,
+// the default level of the algorithm is used if it is 0.
+func WithCompressionLevel(level int) Option {
+	return func(h *DailyHandler) {
+		h.compressionLevel = level
 	}
 }
 
@@ -21,3 +99,58 @@ func WithFileMode(mode os.FileMode) Option {
 		h.mode = mode
 	}
 }
+
+// WithSymlink maintains a symlink at the given path pointing to the current file,
+// it is replaced atomically when the file is rolled, e.g. logs/app.log -> app.2006-01-02.log.
+func WithSymlink(name string) Option {
+	return func(h *DailyHandler) {
+		h.symlink = name
+	}
+}
+
+// WithPersistent keeps the file of the day open between writes instead of opening it on every write.
+func WithPersistent(persistent bool) Option {
+	return func(h *DailyHandler) {
+		h.persistent = persistent
+	}
+}
+
+// WithBufferSize buffers the writes of a persistent handler, the buffer is flushed every flush interval.
+func WithBufferSize(size int) Option {
+	return func(h *DailyHandler) {
+		h.options.BufferSize = size
+	}
+}
+
+// WithFlushInterval sets the interval to flush the buffer of a persistent handler.
+func WithFlushInterval(interval time.Duration) Option {
+	return func(h *DailyHandler) {
+		h.options.FlushInterval = interval
+	}
+}
+
+// WithFsyncEvery fsyncs the file of a persistent handler every n writes.
+func WithFsyncEvery(n int) Option {
+	return func(h *DailyHandler) {
+		h.options.FsyncEvery = n
+	}
+}
+
+// WithFsyncInterval fsyncs the file of a persistent handler every interval.
+func WithFsyncInterval(interval time.Duration) Option {
+	return func(h *DailyHandler) {
+		h.options.FsyncInterval = interval
+	}
+}
+
+// WithLock coordinates the processes writing to the same files by advisory locks (flock).
+// A lock file like .app.log.lock in the directory is held while a process writes a record, rolls the file,
+// compresses a file or cleans the backups, and only the process rolling to a new file compresses
+// the previous one and cleans the backups. The files are locked during every write as well,
+// so the records are not interleaved, even larger than PIPE_BUF.
+// It does nothing on systems other than Unix.
+func WithLock(lock bool) Option {
+	return func(h *DailyHandler) {
+		h.options.Lock = lock
+	}
+}
@@ -0,0 +1,61 @@
+package daily
+
+import (
+	"strings"
+	"time"
+)
+
+// Period is the period after which the file is rolled.
+type Period string
+
+const (
+	PeriodMinute  Period = "minute"
+	PeriodHourly  Period = "hourly"
+	PeriodDaily   Period = "daily"
+	PeriodWeekly  Period = "weekly"
+	PeriodMonthly Period = "monthly"
+)
+
+// defaultPatterns are the patterns of the periods when no pattern is given.
+var defaultPatterns = map[Period]string{
+	PeriodMinute:  "%Y-%m-%d-%H-%M",
+	PeriodHourly:  "%Y-%m-%d-%H",
+	PeriodDaily:   "%Y-%m-%d",
+	PeriodWeekly:  "%Y-%m-%d",
+	PeriodMonthly: "%Y-%m",
+}
+
+// ParsePeriod parses a period case-insensitively, "hour", "day", "week" and "month" are accepted as well.
+func ParsePeriod(s string) (Period, error) {
+	switch strings.ToLower(s) {
+	case "minute":
+		return PeriodMinute, nil
+	case "hourly", "hour":
+		return PeriodHourly, nil
+	case "daily", "day":
+		return PeriodDaily, nil
+	case "weekly", "week":
+		return PeriodWeekly, nil
+	case "monthly", "month":
+		return PeriodMonthly, nil
+	default:
+		return "", NewInvalidPeriodException(s)
+	}
+}
+
+// start returns the start of the period containing t, a week starts on Monday.
+func (p Period) start(t time.Time) time.Time {
+	switch p {
+	case PeriodMinute:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, t.Location())
+	case PeriodHourly:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	case PeriodWeekly:
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+	case PeriodMonthly:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	}
+}
@@ -0,0 +1,38 @@
+package elasticsearch
+
+import (
+	"fmt"
+
+	. "github.com/gopi-frame/contract/exception"
+	"github.com/gopi-frame/exception"
+)
+
+type InvalidURLException struct {
+	Throwable
+}
+
+func NewInvalidURLException(url string) *InvalidURLException {
+	return &InvalidURLException{
+		Throwable: exception.New(fmt.Sprintf("invalid url [%s]", url)),
+	}
+}
+
+type InvalidIndexException struct {
+	Throwable
+}
+
+func NewInvalidIndexException(index string, reason string) *InvalidIndexException {
+	return &InvalidIndexException{
+		Throwable: exception.New(fmt.Sprintf("invalid index [%s]: %s", index, reason)),
+	}
+}
+
+type BulkException struct {
+	Throwable
+}
+
+func NewBulkException(failed int, status int, reason string) *BulkException {
+	return &BulkException{
+		Throwable: exception.New(fmt.Sprintf("%d documents failed to be indexed, the first with status %d: %s", failed, status, reason)),
+	}
+}
@@ -0,0 +1,328 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/gopi-frame/env"
+	"github.com/gopi-frame/logger"
+	"github.com/gopi-frame/logger/handler/internal/backoff"
+	"github.com/gopi-frame/logger/handler/internal/batch"
+	"github.com/gopi-frame/logger/handler/internal/httpsend"
+	"github.com/gopi-frame/logger/handler/internal/strftime"
+	"github.com/gopi-frame/logger/handler/internal/tlsconfig"
+)
+
+var handlerName = "elasticsearch"
+
+//goland:noinspection GoBoolExpressions
+func init() {
+	if handlerName != "" {
+		logger.RegisterHandler(handlerName, func(config map[string]any) (io.WriteCloser, error) {
+			return NewElasticsearchHandlerFromConfig(config)
+		})
+		logger.RegisterHandlerValidator(handlerName, func(config map[string]any) error {
+			_, _, err := parseConfig(config)
+			return err
+		})
+	}
+}
+
+const (
+	// DefaultIndex is the index when none is given.
+	DefaultIndex = "logs-%Y.%m.%d"
+	// DefaultTimeout is the timeout of a bulk request when none is given.
+	DefaultTimeout = 10 * time.Second
+)
+
+// ElasticsearchHandler indexes the records in batches with the bulk API of Elasticsearch or OpenSearch.
+//
+// Each record is a document of the index named after the time it is written, e.g. logs-app-%Y.%m.%d,
+// a record which is not JSON is indexed as {"message": "..."}.
+// A bulk request failing with a network error, a 429 or a 5xx status is retried with a jittered exponential backoff,
+// then the documents rejected with a 429 or a 5xx status are retried alone, the others are dropped,
+// see [ElasticsearchHandler.Failed].
+type ElasticsearchHandler struct {
+	url       string
+	index     string
+	pattern   *strftime.Pattern
+	location  *time.Location
+	headers   http.Header
+	timeout   time.Duration
+	tlsConfig *tls.Config
+	batch     batch.Options
+	sender    httpsend.Sender
+	batcher   *batch.Batcher
+	now       func() time.Time // for testing
+}
+
+// NewElasticsearchHandler creates a new Elasticsearch handler for the cluster at the given URL, e.g. http://localhost:9200.
+func NewElasticsearchHandler(endpoint string, opts ...Option) (*ElasticsearchHandler, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, NewInvalidURLException(endpoint)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/_bulk"
+	h := &ElasticsearchHandler{
+		url:      u.String(),
+		index:    DefaultIndex,
+		location: time.UTC,
+		headers:  make(http.Header),
+		timeout:  DefaultTimeout,
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	if h.index == "" {
+		return nil, NewInvalidIndexException(h.index, "empty")
+	}
+	if h.pattern, err = strftime.Parse(h.index); err != nil {
+		return nil, NewInvalidIndexException(h.index, err.Error())
+	}
+	if h.sender.Client == nil {
+		h.sender.Client = httpsend.NewClient(h.timeout, h.tlsConfig)
+	}
+	h.batcher = batch.New(h.send, h.batch)
+	return h, nil
+}
+
+func NewElasticsearchHandlerFromConfig(config map[string]any) (*ElasticsearchHandler, error) {
+	url, opts, err := parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return NewElasticsearchHandler(url, opts...)
+}
+
+// parseConfig decodes the config into the arguments and the options of [NewElasticsearchHandler].
+func parseConfig(config map[string]any) (string, []Option, error) {
+	var cfg struct {
+		URL           string
+		Index         string
+		UTC           *bool
+		Username      string
+		Password      string
+		APIKey        string
+		Headers       map[string]string
+		BatchSize     int
+		BatchBytes    int
+		FlushInterval time.Duration
+		MaxInFlight   int
+		MaxRetries    int
+		Backoff       backoff.Backoff
+		Timeout       time.Duration
+		TLS           tlsconfig.Config
+	}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           &cfg,
+		WeaklyTypedInput: true,
+		MatchName: func(mapKey, fieldName string) bool {
+			return strings.EqualFold(mapKey, fieldName) || strings.EqualFold(fieldName, strings.ReplaceAll(mapKey, "_", ""))
+		},
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			env.ExpandStringWithEnvHookFunc(),
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToBasicTypeHookFunc(),
+		),
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	if err := decoder.Decode(config); err != nil {
+		return "", nil, err
+	}
+	opts := []Option{
+		WithHeaders(cfg.Headers),
+		WithBatchSize(cfg.BatchSize),
+		WithBatchBytes(cfg.BatchBytes),
+		WithFlushInterval(cfg.FlushInterval),
+		WithMaxInFlight(cfg.MaxInFlight),
+		WithMaxRetries(cfg.MaxRetries),
+		WithBackoff(cfg.Backoff),
+		WithTimeout(cfg.Timeout),
+	}
+	if cfg.Index != "" {
+		opts = append(opts, WithIndex(cfg.Index))
+	}
+	if cfg.UTC != nil && !*cfg.UTC {
+		opts = append(opts, WithLocation(time.Local))
+	}
+	if cfg.Username != "" || cfg.Password != "" {
+		opts = append(opts, WithBasicAuth(cfg.Username, cfg.Password))
+	}
+	if cfg.APIKey != "" {
+		opts = append(opts, WithAPIKey(cfg.APIKey))
+	}
+	tlsConfig, err := cfg.TLS.Build()
+	if err != nil {
+		return "", nil, err
+	}
+	if tlsConfig != nil {
+		opts = append(opts, WithTLSConfig(tlsConfig))
+	}
+	return cfg.URL, opts, nil
+}
+
+// document is a record to index, with the action line preceding it in the bulk request,
+// and the status and error of its last rejection.
+type document struct {
+	action []byte
+	source []byte
+	status int
+	reason string
+}
+
+// documents converts the records of a batch, each prefixed with its timestamp, to documents.
+func (h *ElasticsearchHandler) documents(records [][]byte) []document {
+	documents := make([]document, 0, len(records))
+	for _, r := range records {
+		t := time.Unix(0, int64(binary.BigEndian.Uint64(r))).In(h.location)
+		action, _ := json.Marshal(map[string]any{"create": map[string]string{"_index": h.pattern.Format(t)}})
+		source := bytes.TrimRight(r[8:], "\r\n")
+		if !json.Valid(source) {
+			source, _ = json.Marshal(map[string]string{"message": string(source)})
+		} else if bytes.ContainsAny(source, "\r\n") {
+			var compacted bytes.Buffer
+			_ = json.Compact(&compacted, source)
+			source = compacted.Bytes()
+		}
+		documents = append(documents, document{action: action, source: source})
+	}
+	return documents
+}
+
+// bulkResponse is the part of the response of the bulk API reporting the failed documents.
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int             `json:"status"`
+		Error  json.RawMessage `json:"error"`
+	} `json:"items"`
+}
+
+// bulk indexes the documents in one bulk request, it returns the documents rejected with a retryable status,
+// and the number and error of the documents failed otherwise.
+func (h *ElasticsearchHandler) bulk(ctx context.Context, documents []document) ([]document, int, error) {
+	var body bytes.Buffer
+	for _, d := range documents {
+		body.Write(d.action)
+		body.WriteByte('\n')
+		body.Write(d.source)
+		body.WriteByte('\n')
+	}
+	data, err := h.sender.Do(ctx, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body.Bytes()))
+		if err != nil {
+			return nil, err
+		}
+		for key, values := range h.headers {
+			req.Header[key] = values
+		}
+		req.Header.Set("Content-Type", "application/x-ndjson")
+		return req, nil
+	})
+	if err != nil {
+		return nil, len(documents), err
+	}
+	var resp bulkResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, len(documents), err
+	}
+	if !resp.Errors {
+		return nil, 0, nil
+	}
+	var retry, rejected []document
+	for i, item := range resp.Items {
+		for _, result := range item {
+			if result.Status < 300 || i >= len(documents) {
+				continue
+			}
+			d := documents[i]
+			d.status, d.reason = result.Status, string(result.Error)
+			if httpsend.Retryable(result.Status) {
+				retry = append(retry, d)
+			} else {
+				rejected = append(rejected, d)
+			}
+		}
+	}
+	if len(rejected) > 0 {
+		return retry, len(rejected), NewBulkException(len(rejected), rejected[0].status, rejected[0].reason)
+	}
+	return retry, 0, nil
+}
+
+// send indexes a batch of records, retrying the documents rejected with a retryable status.
+// The error is a [batch.PartialError] with the number of documents failed.
+func (h *ElasticsearchHandler) send(ctx context.Context, records [][]byte) error {
+	documents := h.documents(records)
+	var failed int
+	var errs []error
+	for attempt := 0; ; attempt++ {
+		retry, n, err := h.bulk(ctx, documents)
+		if err != nil {
+			failed += n
+			errs = append(errs, err)
+		}
+		if len(retry) == 0 {
+			break
+		}
+		if attempt >= h.sender.Retries() || ctx.Err() != nil {
+			failed += len(retry)
+			errs = append(errs, NewBulkException(len(retry), retry[0].status, retry[0].reason))
+			break
+		}
+		select {
+		case <-time.After(h.sender.Backoff.Delay(attempt)):
+		case <-ctx.Done():
+		}
+		documents = retry
+	}
+	if failed == 0 {
+		return nil
+	}
+	return &batch.PartialError{Failed: failed, Err: errors.Join(errs...)}
+}
+
+// Write adds the record to the batch, timestamped with the current time, it is indexed in the background.
+func (h *ElasticsearchHandler) Write(p []byte) (int, error) {
+	r := binary.BigEndian.AppendUint64(make([]byte, 0, 8+len(p)), uint64(h.now().UnixNano()))
+	if _, err := h.batcher.Write(append(r, p...)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Sync indexes the batch and waits until all the bulk requests are done,
+// it returns the error of the last bulk request failed since the last sync.
+func (h *ElasticsearchHandler) Sync() error {
+	return h.batcher.Flush()
+}
+
+// Failed returns the number of records dropped because they failed to be indexed.
+func (h *ElasticsearchHandler) Failed() uint64 {
+	return h.batcher.Failed()
+}
+
+// Shutdown stops accepting records, indexes the batch and waits until all the bulk requests are done or ctx is done,
+// then the bulk requests still running are cancelled.
+func (h *ElasticsearchHandler) Shutdown(ctx context.Context) error {
+	return h.batcher.Shutdown(ctx)
+}
+
+// Close stops accepting records, indexes the batch and waits until all the bulk requests are done,
+// writes after Close fail with [os.ErrClosed].
+func (h *ElasticsearchHandler) Close() error {
+	return h.batcher.Close()
+}
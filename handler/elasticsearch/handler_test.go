@@ -0,0 +1,200 @@
+package elasticsearch
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gopi-frame/logger/handler/internal/backoff"
+	"github.com/gopi-frame/logger/handler/internal/handlertest"
+	"github.com/stretchr/testify/assert"
+)
+
+// bulkRequest is a bulk request received by the test server.
+type bulkRequest struct {
+	header    http.Header
+	indices   []string
+	documents []string
+}
+
+// serve starts an Elasticsearch stand-in answering each document of the bulk requests with the status
+// returned by status, given the number of times the document has been received.
+func serve(t *testing.T, status func(document string, received int) int) (*httptest.Server, *handlertest.Recorder[bulkRequest]) {
+	var mu sync.Mutex
+	requests := new(handlertest.Recorder[bulkRequest])
+	received := make(map[string]int)
+	server := handlertest.Serve(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_bulk" || r.Header.Get("Content-Type") != "application/x-ndjson" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		request := bulkRequest{header: r.Header}
+		var items []map[string]any
+		errors := false
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			var action struct {
+				Create struct {
+					Index string `json:"_index"`
+				} `json:"create"`
+			}
+			if err := json.Unmarshal(scanner.Bytes(), &action); err != nil || !scanner.Scan() {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			document := scanner.Text()
+			request.indices = append(request.indices, action.Create.Index)
+			request.documents = append(request.documents, document)
+			received[document]++
+			code := status(document, received[document])
+			result := map[string]any{"_index": action.Create.Index, "status": code}
+			if code >= 300 {
+				errors = true
+				result["error"] = map[string]any{"type": "error", "reason": fmt.Sprint(code)}
+			}
+			items = append(items, map[string]any{"create": result})
+		}
+		requests.Add(request)
+		_ = json.NewEncoder(w).Encode(map[string]any{"took": 1, "errors": errors, "items": items})
+	})
+	return server, requests
+}
+
+func created(string, int) int {
+	return http.StatusCreated
+}
+
+// hourly returns a clock starting at 2026-10-18 00:30 UTC, advancing an hour on each reading.
+func hourly() func() time.Time {
+	now := time.Date(2026, 10, 17, 23, 30, 0, 0, time.UTC)
+	return func() time.Time {
+		now = now.Add(time.Hour)
+		return now
+	}
+}
+
+func TestElasticsearchHandler(t *testing.T) {
+	t.Run("bulk", func(t *testing.T) {
+		server, requests := serve(t, created)
+		h := handlertest.New(t, NewElasticsearchHandlerFromConfig, map[string]any{
+			"url":    server.URL,
+			"index":  "logs-app-%Y.%m.%d",
+			"apiKey": "a2V5",
+		})
+		h.now = hourly()
+		handlertest.Write(t, h, `{"level":"info","msg":"a"}`+"\n", "plain text\n", "{\n  \"msg\": \"pretty\"\n}\n")
+		assert.NoError(t, h.Close())
+		got := requests.All()
+		if !assert.Len(t, got, 1) {
+			return
+		}
+		assert.Equal(t, "ApiKey a2V5", got[0].header.Get("Authorization"))
+		assert.Equal(t, []string{"logs-app-2026.10.18", "logs-app-2026.10.18", "logs-app-2026.10.18"}, got[0].indices)
+		assert.Equal(t, []string{`{"level":"info","msg":"a"}`, `{"message":"plain text"}`, `{"msg":"pretty"}`}, got[0].documents)
+		assert.Equal(t, uint64(0), h.Failed())
+	})
+
+	t.Run("retry failed documents", func(t *testing.T) {
+		server, requests := serve(t, func(document string, received int) int {
+			switch {
+			case strings.Contains(document, "rejected"):
+				return http.StatusBadRequest
+			case strings.Contains(document, "busy") && received < 3:
+				return http.StatusTooManyRequests
+			default:
+				return http.StatusCreated
+			}
+		})
+		var handled []error
+		h, err := NewElasticsearchHandler(server.URL,
+			WithBasicAuth("elastic", "secret"),
+			WithBackoff(backoff.Backoff{Initial: time.Millisecond}),
+			WithErrorHandler(func(err error) {
+				handled = append(handled, err)
+			}),
+		)
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		handlertest.Write(t, h, `{"msg":"ok"}`, `{"msg":"busy"}`, `{"msg":"rejected"}`)
+		err = h.Sync()
+		var bulkErr *BulkException
+		if assert.ErrorAs(t, err, &bulkErr) {
+			assert.Contains(t, bulkErr.Error(), "1 documents failed to be indexed, the first with status 400")
+		}
+		assert.Len(t, handled, 1)
+		assert.Equal(t, uint64(1), h.Failed())
+		got := requests.All()
+		if assert.Len(t, got, 3) {
+			assert.Equal(t, "Basic ZWxhc3RpYzpzZWNyZXQ=", got[0].header.Get("Authorization"))
+			assert.Len(t, got[0].documents, 3)
+			assert.Equal(t, []string{`{"msg":"busy"}`}, got[1].documents)
+			assert.Equal(t, []string{`{"msg":"busy"}`}, got[2].documents)
+		}
+		assert.NoError(t, h.Close())
+	})
+
+	t.Run("retries exhausted", func(t *testing.T) {
+		server, requests := serve(t, func(string, int) int {
+			return http.StatusServiceUnavailable
+		})
+		h := handlertest.New(t, NewElasticsearchHandlerFromConfig, map[string]any{
+			"url":        server.URL,
+			"maxRetries": 1,
+			"backoff":    map[string]any{"initial": "1ms"},
+		})
+		h.now = hourly()
+		handlertest.Write(t, h, `{"msg":"a"}`, `{"msg":"b"}`)
+		var bulkErr *BulkException
+		assert.ErrorAs(t, h.Sync(), &bulkErr)
+		assert.Equal(t, uint64(2), h.Failed())
+		assert.Len(t, requests.All(), 2)
+		assert.NoError(t, h.Close())
+	})
+
+	t.Run("failed request", func(t *testing.T) {
+		failures := 1
+		var mu sync.Mutex
+		server, requests := serve(t, created)
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			fail := failures > 0
+			failures--
+			mu.Unlock()
+			if fail {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			server.Config.Handler.ServeHTTP(w, r)
+		}))
+		defer proxy.Close()
+		h := handlertest.New(t, NewElasticsearchHandlerFromConfig, map[string]any{
+			"url":     proxy.URL + "/",
+			"backoff": map[string]any{"initial": "1ms"},
+		})
+		h.now = hourly()
+		handlertest.Write(t, h, `{"msg":"a"}`)
+		assert.NoError(t, h.Sync())
+		assert.Len(t, requests.All(), 1)
+		assert.NoError(t, h.Close())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := NewElasticsearchHandlerFromConfig(map[string]any{})
+		assert.IsType(t, new(InvalidURLException), err)
+		_, err = NewElasticsearchHandlerFromConfig(map[string]any{"url": "http://localhost:9200", "index": "logs-%Q"})
+		assert.IsType(t, new(InvalidIndexException), err)
+		_, err = NewElasticsearchHandler("http://localhost:9200", WithIndex("logs-%"))
+		assert.IsType(t, new(InvalidIndexException), err)
+		_, err = NewElasticsearchHandler("http://localhost:9200", WithIndex(""))
+		assert.IsType(t, new(InvalidIndexException), err)
+	})
+}
@@ -0,0 +1,127 @@
+package elasticsearch
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"net/http"
+	"time"
+
+	"github.com/gopi-frame/logger/handler/internal/backoff"
+)
+
+type Option func(h *ElasticsearchHandler)
+
+// WithIndex sets the index of the documents, [DefaultIndex] by default.
+// The strftime-style tokens %Y, %y, %m, %d, %j, %H, %M, %S and %% are replaced by the time the record is written,
+// e.g. logs-app-%Y.%m.%d.
+func WithIndex(index string) Option {
+	return func(h *ElasticsearchHandler) {
+		h.index = index
+	}
+}
+
+// WithLocation sets the location of the time in the index names, [time.UTC] by default.
+func WithLocation(location *time.Location) Option {
+	return func(h *ElasticsearchHandler) {
+		if location != nil {
+			h.location = location
+		}
+	}
+}
+
+// WithBasicAuth authenticates the bulk requests with the given username and password.
+func WithBasicAuth(username, password string) Option {
+	return func(h *ElasticsearchHandler) {
+		h.headers.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(username+":"+password)))
+	}
+}
+
+// WithAPIKey authenticates the bulk requests with the given API key, encoded as returned by the API, i.e. base64.
+func WithAPIKey(key string) Option {
+	return func(h *ElasticsearchHandler) {
+		h.headers.Set("Authorization", "ApiKey "+key)
+	}
+}
+
+// WithHeaders adds the headers to the bulk requests.
+func WithHeaders(headers map[string]string) Option {
+	return func(h *ElasticsearchHandler) {
+		for key, value := range headers {
+			h.headers.Add(key, value)
+		}
+	}
+}
+
+// WithBatchSize sets the max number of documents of a bulk request, see [batch.DefaultMaxCount] for the default.
+func WithBatchSize(size int) Option {
+	return func(h *ElasticsearchHandler) {
+		h.batch.MaxCount = size
+	}
+}
+
+// WithBatchBytes sets the max size of the records of a bulk request, see [batch.DefaultMaxBytes] for the default.
+func WithBatchBytes(size int) Option {
+	return func(h *ElasticsearchHandler) {
+		h.batch.MaxBytes = size
+	}
+}
+
+// WithFlushInterval sets the max time a record waits before it is indexed, see [batch.DefaultInterval] for the default.
+func WithFlushInterval(interval time.Duration) Option {
+	return func(h *ElasticsearchHandler) {
+		h.batch.Interval = interval
+	}
+}
+
+// WithMaxInFlight sets the max number of bulk requests sent concurrently, 1 by default.
+func WithMaxInFlight(n int) Option {
+	return func(h *ElasticsearchHandler) {
+		h.batch.MaxInFlight = n
+	}
+}
+
+// WithErrorHandler sets the function called with the errors of the documents failed to be indexed.
+func WithErrorHandler(handler func(error)) Option {
+	return func(h *ElasticsearchHandler) {
+		h.batch.ErrorHandler = handler
+	}
+}
+
+// WithMaxRetries sets the number of retries of a bulk request failing with a network error, a 429 or a 5xx status,
+// and of the documents rejected with a 429 or a 5xx status,
+// see [httpsend.DefaultMaxRetries] for the default, a negative number disables the retries.
+func WithMaxRetries(n int) Option {
+	return func(h *ElasticsearchHandler) {
+		h.sender.MaxRetries = n
+	}
+}
+
+// WithBackoff sets the backoff between the retries, see [backoff.Backoff] for the defaults.
+func WithBackoff(b backoff.Backoff) Option {
+	return func(h *ElasticsearchHandler) {
+		h.sender.Backoff = b
+	}
+}
+
+// WithTimeout sets the timeout of a bulk request, it is ignored if it is not positive or a client is given.
+func WithTimeout(timeout time.Duration) Option {
+	return func(h *ElasticsearchHandler) {
+		if timeout > 0 {
+			h.timeout = timeout
+		}
+	}
+}
+
+// WithTLSConfig sets the TLS configuration of the bulk requests, it is ignored if a client is given.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(h *ElasticsearchHandler) {
+		h.tlsConfig = config
+	}
+}
+
+// WithClient sets the client sending the bulk requests.
+func WithClient(client *http.Client) Option {
+	return func(h *ElasticsearchHandler) {
+		h.sender.Client = client
+	}
+}
@@ -4,10 +4,13 @@ import (
 	"github.com/go-viper/mapstructure/v2"
 	"github.com/gopi-frame/env"
 	"github.com/gopi-frame/logger"
+	"github.com/gopi-frame/logger/handler/internal/filewriter"
+	"github.com/gopi-frame/logger/handler/internal/flock"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 var handlerName = "file"
@@ -18,28 +21,61 @@ func init() {
 		logger.RegisterHandler(handlerName, func(config map[string]any) (io.WriteCloser, error) {
 			return NewFileHandlerFromConfig(config)
 		})
+		logger.RegisterHandlerValidator(handlerName, func(config map[string]any) error {
+			_, _, _, err := parseConfig(config)
+			return err
+		})
 	}
 }
 
+// FileHandler appends to a file.
+// By default, the file is opened and closed on every write,
+// with [WithPersistent] it is kept open by a mutex-protected writer, see [filewriter.Writer].
+// With [WithLock], the processes sharing the file hold an advisory lock on it while writing.
 type FileHandler struct {
-	filename string
-	mode     os.FileMode
+	filename   string
+	mode       os.FileMode
+	persistent bool
+	options    filewriter.Options
+	writer     *filewriter.Writer
 }
 
-func NewFileHandler(filename string, mode os.FileMode) (*FileHandler, error) {
+func NewFileHandler(filename string, mode os.FileMode, opts ...Option) (*FileHandler, error) {
 	if err := os.MkdirAll(filepath.Dir(filename), os.ModePerm); err != nil {
 		return nil, err
 	}
-	return &FileHandler{
+	h := &FileHandler{
 		filename: filename,
 		mode:     mode,
-	}, nil
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	if h.persistent {
+		h.writer = filewriter.New(filename, mode, h.options)
+	}
+	return h, nil
 }
 
 func NewFileHandlerFromConfig(config map[string]any) (*FileHandler, error) {
+	filename, mode, opts, err := parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return NewFileHandler(filename, mode, opts...)
+}
+
+// parseConfig decodes the config into the arguments and the options of [NewFileHandler].
+func parseConfig(config map[string]any) (string, os.FileMode, []Option, error) {
 	var cfg struct {
-		Filename string
-		Mode     uint32
+		Filename      string
+		Mode          uint32
+		Persistent    bool
+		BufferSize    int
+		FlushInterval time.Duration
+		FsyncEvery    int
+		FsyncInterval time.Duration
+		Lock          bool
 	}
 	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
 		Result:           &cfg,
@@ -49,23 +85,41 @@ func NewFileHandlerFromConfig(config map[string]any) (*FileHandler, error) {
 		},
 		DecodeHook: mapstructure.ComposeDecodeHookFunc(
 			env.ExpandStringWithEnvHookFunc(),
+			mapstructure.StringToTimeDurationHookFunc(),
 			mapstructure.StringToBasicTypeHookFunc(),
 		),
 	})
 	if err != nil {
-		panic(err)
+		return "", 0, nil, err
 	}
 	if err := decoder.Decode(config); err != nil {
-		panic(err)
+		return "", 0, nil, err
 	}
-	return NewFileHandler(cfg.Filename, os.FileMode(cfg.Mode))
+	return cfg.Filename, os.FileMode(cfg.Mode), []Option{
+		WithPersistent(cfg.Persistent),
+		WithBufferSize(cfg.BufferSize),
+		WithFlushInterval(cfg.FlushInterval),
+		WithFsyncEvery(cfg.FsyncEvery),
+		WithFsyncInterval(cfg.FsyncInterval),
+		WithLock(cfg.Lock),
+	}, nil
 }
 
 func (h *FileHandler) Write(p []byte) (int, error) {
+	if h.writer != nil {
+		return h.writer.Write(p)
+	}
 	file, err := os.OpenFile(h.filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, h.mode)
 	if err != nil {
 		return 0, err
 	}
+	if h.options.Lock {
+		// the lock is released when the file is closed
+		if err := flock.Lock(file); err != nil {
+			_ = file.Close()
+			return 0, err
+		}
+	}
 	n, err := file.Write(p)
 	if err1 := file.Close(); err1 != nil && err == nil {
 		err = err1
@@ -73,6 +127,26 @@ func (h *FileHandler) Write(p []byte) (int, error) {
 	return n, err
 }
 
+// Sync flushes the buffer and fsyncs the file of a persistent handler.
+func (h *FileHandler) Sync() error {
+	if h.writer != nil {
+		return h.writer.Sync()
+	}
+	return nil
+}
+
+// Reopen closes the file of a persistent handler, it is opened again by its name on the next write.
+// Without [WithPersistent], it does nothing, the file is opened by name on every write.
+func (h *FileHandler) Reopen() error {
+	if h.writer != nil {
+		return h.writer.Reopen()
+	}
+	return nil
+}
+
 func (h *FileHandler) Close() error {
+	if h.writer != nil {
+		return h.writer.Close()
+	}
 	return nil
 }
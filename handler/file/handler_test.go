@@ -1,9 +1,12 @@
 package file
 
 import (
+	"bytes"
 	"github.com/gopi-frame/env"
 	"github.com/stretchr/testify/assert"
 	"os"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -46,3 +49,78 @@ func TestNewFileHandlerFromConfig(t *testing.T) {
 		assert.Equal(t, "test", string(content))
 	}
 }
+
+func TestFileHandler_Persistent(t *testing.T) {
+	fh, err := NewFileHandlerFromConfig(map[string]any{
+		"filename":       "testdata/persistent.log",
+		"mode":           0644,
+		"persistent":     true,
+		"buffer_size":    1024,
+		"flush_interval": "1h",
+	})
+	if !assert.NoError(t, err) {
+		assert.FailNow(t, err.Error())
+	}
+	defer func() {
+		_ = os.Remove("testdata/persistent.log")
+	}()
+	_, err = fh.Write([]byte("test"))
+	if !assert.NoError(t, err) {
+		assert.FailNow(t, err.Error())
+	}
+	content, err := os.ReadFile("testdata/persistent.log")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "", string(content))
+	}
+	if !assert.NoError(t, fh.Close()) {
+		assert.FailNow(t, err.Error())
+	}
+	content, err = os.ReadFile("testdata/persistent.log")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "test", string(content))
+	}
+}
+
+func TestFileHandler_Lock(t *testing.T) {
+	// two handlers on the same file behave like two processes
+	var handlers []*FileHandler
+	for _, persistent := range []bool{false, true} {
+		fh, err := NewFileHandler("testdata/lock.log", 0644, WithLock(true), WithPersistent(persistent), WithBufferSize(8192))
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		handlers = append(handlers, fh)
+	}
+	defer func() {
+		_ = os.Remove("testdata/lock.log")
+	}()
+	var wg sync.WaitGroup
+	for i, fh := range handlers {
+		for j := 0; j < 2; j++ {
+			wg.Add(1)
+			go func(fh *FileHandler, letter byte) {
+				defer wg.Done()
+				// larger than PIPE_BUF
+				record := append(bytes.Repeat([]byte{letter}, 5000), '\n')
+				for k := 0; k < 20; k++ {
+					if _, err := fh.Write(record); !assert.NoError(t, err) {
+						return
+					}
+				}
+			}(fh, byte('a'+i*2+j))
+		}
+	}
+	wg.Wait()
+	for _, fh := range handlers {
+		assert.NoError(t, fh.Close())
+	}
+	content, err := os.ReadFile("testdata/lock.log")
+	if !assert.NoError(t, err) {
+		assert.FailNow(t, err.Error())
+	}
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	assert.Len(t, lines, 80)
+	for _, line := range lines {
+		assert.Equal(t, strings.Repeat(line[:1], 5000), line)
+	}
+}
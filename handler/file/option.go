@@ -0,0 +1,49 @@
+package file
+
+import "time"
+
+type Option func(h *FileHandler)
+
+// WithPersistent keeps the file open between writes instead of opening it on every write.
+func WithPersistent(persistent bool) Option {
+	return func(h *FileHandler) {
+		h.persistent = persistent
+	}
+}
+
+// WithBufferSize buffers the writes of a persistent handler, the buffer is flushed every flush interval.
+func WithBufferSize(size int) Option {
+	return func(h *FileHandler) {
+		h.options.BufferSize = size
+	}
+}
+
+// WithFlushInterval sets the interval to flush the buffer of a persistent handler.
+func WithFlushInterval(interval time.Duration) Option {
+	return func(h *FileHandler) {
+		h.options.FlushInterval = interval
+	}
+}
+
+// WithFsyncEvery fsyncs the file of a persistent handler every n writes.
+func WithFsyncEvery(n int) Option {
+	return func(h *FileHandler) {
+		h.options.FsyncEvery = n
+	}
+}
+
+// WithFsyncInterval fsyncs the file of a persistent handler every interval.
+func WithFsyncInterval(interval time.Duration) Option {
+	return func(h *FileHandler) {
+		h.options.FsyncInterval = interval
+	}
+}
+
+// WithLock holds an exclusive advisory lock (flock) on the file during every write,
+// so the records of the processes sharing the file are not interleaved, even larger than PIPE_BUF.
+// It does nothing on systems other than Unix.
+func WithLock(lock bool) Option {
+	return func(h *FileHandler) {
+		h.options.Lock = lock
+	}
+}
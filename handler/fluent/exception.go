@@ -0,0 +1,48 @@
+package fluent
+
+import (
+	"fmt"
+
+	. "github.com/gopi-frame/contract/exception"
+	"github.com/gopi-frame/exception"
+)
+
+type InvalidNetworkException struct {
+	Throwable
+}
+
+func NewInvalidNetworkException(network string) *InvalidNetworkException {
+	return &InvalidNetworkException{
+		Throwable: exception.New(fmt.Sprintf("invalid network [%s]", network)),
+	}
+}
+
+type InvalidModeException struct {
+	Throwable
+}
+
+func NewInvalidModeException(mode string) *InvalidModeException {
+	return &InvalidModeException{
+		Throwable: exception.New(fmt.Sprintf("invalid mode [%s]", mode)),
+	}
+}
+
+type TagMissingException struct {
+	Throwable
+}
+
+func NewTagMissingException() *TagMissingException {
+	return &TagMissingException{
+		Throwable: exception.New("tag is missing"),
+	}
+}
+
+type AckMismatchException struct {
+	Throwable
+}
+
+func NewAckMismatchException(chunk string, ack any) *AckMismatchException {
+	return &AckMismatchException{
+		Throwable: exception.New(fmt.Sprintf("unexpected ack [%v] of chunk [%s]", ack, chunk)),
+	}
+}
@@ -0,0 +1,369 @@
+package fluent
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/gopi-frame/env"
+	"github.com/gopi-frame/logger"
+	"github.com/gopi-frame/logger/handler/internal/backoff"
+	"github.com/gopi-frame/logger/handler/internal/batch"
+	"github.com/gopi-frame/logger/handler/internal/tlsconfig"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+var handlerName = "fluent"
+
+//goland:noinspection GoBoolExpressions
+func init() {
+	if handlerName != "" {
+		logger.RegisterHandler(handlerName, func(config map[string]any) (io.WriteCloser, error) {
+			return NewFluentHandlerFromConfig(config)
+		})
+		logger.RegisterHandlerValidator(handlerName, func(config map[string]any) error {
+			_, _, err := parseConfig(config)
+			return err
+		})
+	}
+}
+
+const (
+	// DefaultAddress is the address of the forward input of Fluentd and Fluent Bit when none is given.
+	DefaultAddress = "127.0.0.1:24224"
+	// DefaultDialTimeout is the timeout to connect when none is given.
+	DefaultDialTimeout = 5 * time.Second
+	// DefaultWriteTimeout is the timeout to write a message when none is given.
+	DefaultWriteTimeout = 5 * time.Second
+	// DefaultAckTimeout is the timeout to receive the ack of a message when none is given.
+	DefaultAckTimeout = 10 * time.Second
+	// DefaultMaxRetries is the number of retries of a message when none is given.
+	DefaultMaxRetries = 3
+)
+
+// Mode is the mode of the forward protocol the batches are sent in.
+type Mode string
+
+const (
+	// ModeForward sends a batch as [tag, [[time, record], ...], option].
+	ModeForward Mode = "forward"
+	// ModePackedForward sends a batch as [tag, bin, option], the binary being the concatenated [time, record] entries,
+	// which the server decodes lazily.
+	ModePackedForward Mode = "packedForward"
+)
+
+// ParseMode parses a mode case-insensitively, ignoring dashes and underscores.
+func ParseMode(s string) (Mode, error) {
+	switch strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(s)) {
+	case "forward":
+		return ModeForward, nil
+	case "packedforward", "packed":
+		return ModePackedForward, nil
+	default:
+		return "", NewInvalidModeException(s)
+	}
+}
+
+// FluentHandler sends the records in batches to Fluentd or Fluent Bit with the forward protocol.
+//
+// Each record is an entry of the tag, timestamped when it is written, a JSON record is sent as a map
+// and another record as {"message": "..."}. A batch is sent in one message,
+// which is acknowledged by the server if ack is enabled.
+// A message failing to be sent, or not acknowledged in time, is sent again on a new connection
+// after an exponential backoff, the records of the messages failing after all the retries are dropped,
+// see [FluentHandler.Failed].
+type FluentHandler struct {
+	network      string
+	address      string
+	tag          string
+	mode         Mode
+	ack          bool
+	ackTimeout   time.Duration
+	tlsConfig    *tls.Config
+	dialTimeout  time.Duration
+	writeTimeout time.Duration
+	maxRetries   int
+	backoff      backoff.Backoff
+	batch        batch.Options
+	batcher      *batch.Batcher
+	mu           sync.Mutex
+	conn         net.Conn
+	now          func() time.Time // for testing
+}
+
+// NewFluentHandler creates a new Fluent handler sending the records with the given tag,
+// to [DefaultAddress] over TCP unless an address is given.
+func NewFluentHandler(tag string, opts ...Option) (*FluentHandler, error) {
+	if tag == "" {
+		return nil, NewTagMissingException()
+	}
+	h := &FluentHandler{
+		network:      "tcp",
+		address:      DefaultAddress,
+		tag:          tag,
+		mode:         ModeForward,
+		ackTimeout:   DefaultAckTimeout,
+		dialTimeout:  DefaultDialTimeout,
+		writeTimeout: DefaultWriteTimeout,
+		maxRetries:   DefaultMaxRetries,
+		now:          time.Now,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	switch h.network {
+	case "tcp", "tcp4", "tcp6", "unix":
+	default:
+		return nil, NewInvalidNetworkException(h.network)
+	}
+	// the messages are sent one at a time on the connection
+	h.batch.MaxInFlight = 1
+	h.batcher = batch.New(h.send, h.batch)
+	return h, nil
+}
+
+func NewFluentHandlerFromConfig(config map[string]any) (*FluentHandler, error) {
+	tag, opts, err := parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return NewFluentHandler(tag, opts...)
+}
+
+// parseConfig decodes the config into the arguments and the options of [NewFluentHandler].
+func parseConfig(config map[string]any) (string, []Option, error) {
+	var cfg struct {
+		Network       string
+		Address       string
+		Tag           string
+		Mode          string
+		Ack           bool
+		AckTimeout    time.Duration
+		TLS           tlsconfig.Config
+		DialTimeout   time.Duration
+		WriteTimeout  time.Duration
+		MaxRetries    *int
+		Backoff       backoff.Backoff
+		BatchSize     int
+		BatchBytes    int
+		FlushInterval time.Duration
+	}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           &cfg,
+		WeaklyTypedInput: true,
+		MatchName: func(mapKey, fieldName string) bool {
+			return strings.EqualFold(mapKey, fieldName) || strings.EqualFold(fieldName, strings.ReplaceAll(mapKey, "_", ""))
+		},
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			env.ExpandStringWithEnvHookFunc(),
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToBasicTypeHookFunc(),
+		),
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	if err := decoder.Decode(config); err != nil {
+		return "", nil, err
+	}
+	opts := []Option{
+		WithAddress(cfg.Network, cfg.Address),
+		WithAck(cfg.Ack),
+		WithAckTimeout(cfg.AckTimeout),
+		WithDialTimeout(cfg.DialTimeout),
+		WithWriteTimeout(cfg.WriteTimeout),
+		WithBackoff(cfg.Backoff),
+		WithBatchSize(cfg.BatchSize),
+		WithBatchBytes(cfg.BatchBytes),
+		WithFlushInterval(cfg.FlushInterval),
+	}
+	if cfg.MaxRetries != nil {
+		opts = append(opts, WithMaxRetries(*cfg.MaxRetries))
+	}
+	if cfg.Mode != "" {
+		mode, err := ParseMode(cfg.Mode)
+		if err != nil {
+			return "", nil, err
+		}
+		opts = append(opts, WithMode(mode))
+	}
+	tlsConfig, err := cfg.TLS.Build()
+	if err != nil {
+		return "", nil, err
+	}
+	if tlsConfig != nil {
+		opts = append(opts, WithTLSConfig(tlsConfig))
+	}
+	return cfg.Tag, opts, nil
+}
+
+// message encodes a batch of records, each prefixed with its timestamp, in a message of the forward protocol.
+func (h *FluentHandler) message(records [][]byte, chunk string) ([]byte, error) {
+	var entries []any
+	var packed []byte
+	for _, r := range records {
+		t := time.Unix(0, int64(binary.BigEndian.Uint64(r)))
+		line := bytes.TrimRight(r[8:], "\r\n")
+		record, err := fromJSON(line)
+		if _, ok := record.(object); err != nil || !ok {
+			record = object{{key: "message", value: string(line)}}
+		}
+		entry := []any{eventTime(t), record}
+		if h.mode == ModePackedForward {
+			b, err := marshal(entry)
+			if err != nil {
+				return nil, err
+			}
+			packed = append(packed, b...)
+		} else {
+			entries = append(entries, entry)
+		}
+	}
+	option := object{{key: "size", value: len(records)}}
+	if chunk != "" {
+		option = append(option, field{key: "chunk", value: chunk})
+	}
+	if h.mode == ModePackedForward {
+		return marshal([]any{h.tag, packed, option})
+	}
+	return marshal([]any{h.tag, entries, option})
+}
+
+// connect opens the connection if it is not open.
+func (h *FluentHandler) connect(ctx context.Context) error {
+	if h.conn != nil {
+		return nil
+	}
+	dialer := &net.Dialer{Timeout: h.dialTimeout}
+	var conn net.Conn
+	var err error
+	if h.tlsConfig != nil {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: h.tlsConfig}).DialContext(ctx, h.network, h.address)
+	} else {
+		conn, err = dialer.DialContext(ctx, h.network, h.address)
+	}
+	if err != nil {
+		return err
+	}
+	h.conn = conn
+	return nil
+}
+
+// disconnect closes the connection, it is opened again on the next message.
+func (h *FluentHandler) disconnect() error {
+	if h.conn == nil {
+		return nil
+	}
+	err := h.conn.Close()
+	h.conn = nil
+	return err
+}
+
+// transmit writes the message on the connection and waits for its ack if enabled.
+func (h *FluentHandler) transmit(ctx context.Context, message []byte, chunk string) error {
+	if err := h.connect(ctx); err != nil {
+		return err
+	}
+	// interrupt the write or the wait for the ack when ctx is done
+	conn := h.conn
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.SetDeadline(time.Now())
+	})
+	defer stop()
+	if h.writeTimeout > 0 {
+		_ = h.conn.SetWriteDeadline(time.Now().Add(h.writeTimeout))
+	}
+	if _, err := h.conn.Write(message); err != nil {
+		return err
+	}
+	if chunk == "" {
+		return nil
+	}
+	_ = h.conn.SetReadDeadline(time.Now().Add(h.ackTimeout))
+	response, err := msgpack.NewDecoder(h.conn).DecodeInterface()
+	if err != nil {
+		return err
+	}
+	if m, ok := response.(map[string]any); !ok || m["ack"] != chunk {
+		return NewAckMismatchException(chunk, response)
+	}
+	return nil
+}
+
+// send sends a batch of records in one message, retrying on a new connection.
+func (h *FluentHandler) send(ctx context.Context, records [][]byte) error {
+	var chunk string
+	if h.ack {
+		id := make([]byte, 16)
+		_, _ = rand.Read(id)
+		chunk = base64.StdEncoding.EncodeToString(id)
+	}
+	message, err := h.message(records, chunk)
+	if err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for attempt := 0; ; attempt++ {
+		err := h.transmit(ctx, message, chunk)
+		if err == nil {
+			return nil
+		}
+		_ = h.disconnect()
+		if attempt >= h.maxRetries || ctx.Err() != nil {
+			return err
+		}
+		select {
+		case <-time.After(h.backoff.Delay(attempt)):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+// Write adds the record to the batch, timestamped with the current time, it is sent in the background.
+func (h *FluentHandler) Write(p []byte) (int, error) {
+	r := binary.BigEndian.AppendUint64(make([]byte, 0, 8+len(p)), uint64(h.now().UnixNano()))
+	if _, err := h.batcher.Write(append(r, p...)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Sync sends the batch and waits until all the messages are sent, and acknowledged if enabled,
+// it returns the error of the last message failed since the last sync.
+func (h *FluentHandler) Sync() error {
+	return h.batcher.Flush()
+}
+
+// Failed returns the number of records dropped because their messages failed.
+func (h *FluentHandler) Failed() uint64 {
+	return h.batcher.Failed()
+}
+
+// Shutdown stops accepting records, sends the batch and waits until all the messages are sent or ctx is done,
+// then the messages still being sent are cancelled and the connection is closed.
+func (h *FluentHandler) Shutdown(ctx context.Context) error {
+	err := h.batcher.Shutdown(ctx)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if closeErr := h.disconnect(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Close stops accepting records, sends the batch and waits until all the messages are sent,
+// then closes the connection. Writes after Close fail with [os.ErrClosed].
+func (h *FluentHandler) Close() error {
+	return h.Shutdown(context.Background())
+}
@@ -0,0 +1,268 @@
+package fluent
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gopi-frame/logger/handler/internal/backoff"
+	"github.com/gopi-frame/logger/handler/internal/handlertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/vmihailenco/msgpack/v5/msgpcode"
+)
+
+// entry is an entry received by the test server.
+type entry struct {
+	tag    string
+	time   time.Time
+	record map[string]any
+}
+
+func init() {
+	// decode the EventTime extension as *eventTime in the test server
+	msgpack.RegisterExtDecoder(0, (*eventTime)(nil), func(d *msgpack.Decoder, v reflect.Value, extLen int) error {
+		b := make([]byte, extLen)
+		if err := d.ReadFull(b); err != nil {
+			return err
+		}
+		*v.Interface().(*eventTime) = eventTime(time.Unix(int64(binary.BigEndian.Uint32(b)), int64(binary.BigEndian.Uint32(b[4:]))))
+		return nil
+	})
+}
+
+// decodeMessage decodes a message of the forward protocol, the entries of a packed forward message are unpacked.
+// The integers of the entries are decoded as int64.
+func decodeMessage(dec *msgpack.Decoder) (string, []any, map[string]any, error) {
+	if _, err := dec.DecodeArrayLen(); err != nil {
+		return "", nil, nil, err
+	}
+	tag, err := dec.DecodeString()
+	if err != nil {
+		return "", nil, nil, err
+	}
+	var list []any
+	if code, _ := dec.PeekCode(); msgpcode.IsBin(code) {
+		packed, err := dec.DecodeBytes()
+		if err != nil {
+			return "", nil, nil, err
+		}
+		entries := msgpack.NewDecoder(bytes.NewReader(packed))
+		entries.UseLooseInterfaceDecoding(true)
+		for {
+			e, err := entries.DecodeInterface()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return "", nil, nil, err
+			}
+			list = append(list, e)
+		}
+	} else {
+		dec.UseLooseInterfaceDecoding(true)
+		defer dec.UseLooseInterfaceDecoding(false)
+		if list, err = dec.DecodeSlice(); err != nil {
+			return "", nil, nil, err
+		}
+	}
+	option, err := dec.DecodeMap()
+	return tag, list, option, err
+}
+
+// serve accepts the connections of the listener and sends the entries of the messages received,
+// the messages with a chunk are acknowledged, except the first ones if drop is positive,
+// which are dropped by closing the connection.
+func serve(listener net.Listener, drop int32) (<-chan entry, *atomic.Int32) {
+	entries := make(chan entry, 100)
+	accepted := new(atomic.Int32)
+	dropped := new(atomic.Int32)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			accepted.Add(1)
+			go func() {
+				defer conn.Close()
+				dec := msgpack.NewDecoder(conn)
+				for {
+					tag, list, option, err := decodeMessage(dec)
+					if err != nil {
+						return
+					}
+					if _, ok := option["chunk"]; ok && dropped.Add(1) <= drop {
+						return
+					}
+					for _, e := range list {
+						e := e.([]any)
+						entries <- entry{tag: tag, time: time.Time(*e[0].(*eventTime)), record: e[1].(map[string]any)}
+					}
+					if chunk, ok := option["chunk"]; ok {
+						ack, _ := marshal(map[string]any{"ack": chunk})
+						_, _ = conn.Write(ack)
+					}
+				}
+			}()
+		}
+	}()
+	return entries, accepted
+}
+
+func listen(t *testing.T, network, address string) net.Listener {
+	listener, err := net.Listen(network, address)
+	if !assert.NoError(t, err) {
+		assert.FailNow(t, err.Error())
+	}
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+	return listener
+}
+
+func clock() time.Time {
+	return time.Date(2026, 10, 17, 12, 0, 0, 123456789, time.UTC)
+}
+
+func TestFluentHandler(t *testing.T) {
+	records := []string{`{"level":"info","msg":"a","count":3,"ratio":0.5,"tags":["x"],"ok":true,"none":null}` + "\n", "plain text\n"}
+	expected := []map[string]any{
+		{"level": "info", "msg": "a", "count": int64(3), "ratio": 0.5, "tags": []any{"x"}, "ok": true, "none": nil},
+		{"message": "plain text"},
+	}
+
+	for _, mode := range []string{"forward", "packed_forward"} {
+		t.Run(mode, func(t *testing.T) {
+			listener := listen(t, "tcp", "127.0.0.1:0")
+			entries, _ := serve(listener, 0)
+			h := handlertest.New(t, NewFluentHandlerFromConfig, map[string]any{
+				"address": listener.Addr().String(),
+				"tag":     "app.logs",
+				"mode":    mode,
+			})
+			h.now = clock
+			handlertest.Write(t, h, records...)
+			assert.NoError(t, h.Sync())
+			received := handlertest.Receive(t, entries, 2)
+			for i, e := range received {
+				assert.Equal(t, "app.logs", e.tag)
+				assert.True(t, h.now().Equal(e.time))
+				assert.Equal(t, expected[i], e.record)
+			}
+			assert.NoError(t, h.Close())
+		})
+	}
+
+	t.Run("ack", func(t *testing.T) {
+		dir, err := os.MkdirTemp("", "fluent")
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		defer os.RemoveAll(dir)
+		listener := listen(t, "unix", filepath.Join(dir, "fluent.sock"))
+		entries, accepted := serve(listener, 1)
+		h, err := NewFluentHandler("app",
+			WithAddress("unix", listener.Addr().String()),
+			WithAck(true),
+			WithBackoff(backoff.Backoff{Initial: time.Millisecond}),
+		)
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		handlertest.Write(t, h, records[0])
+		// the first message is dropped without ack, and sent again on a new connection
+		assert.NoError(t, h.Sync())
+		assert.Equal(t, expected[0], handlertest.Receive(t, entries, 1)[0].record)
+		assert.Equal(t, int32(2), accepted.Load())
+		assert.Equal(t, uint64(0), h.Failed())
+		assert.NoError(t, h.Close())
+	})
+
+	t.Run("ack timeout", func(t *testing.T) {
+		listener := listen(t, "tcp", "127.0.0.1:0")
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				// never acknowledged
+				defer conn.Close()
+			}
+		}()
+		h := handlertest.New(t, NewFluentHandlerFromConfig, map[string]any{
+			"address":    listener.Addr().String(),
+			"tag":        "app",
+			"ack":        true,
+			"ackTimeout": "20ms",
+			"maxRetries": 1,
+			"backoff":    map[string]any{"initial": "1ms"},
+		})
+		h.now = clock
+		handlertest.Write(t, h, records...)
+		err := h.Sync()
+		var netErr net.Error
+		if assert.ErrorAs(t, err, &netErr) {
+			assert.True(t, netErr.Timeout())
+		}
+		assert.Equal(t, uint64(2), h.Failed())
+		assert.NoError(t, h.Close())
+	})
+
+	t.Run("reconnect", func(t *testing.T) {
+		listener := listen(t, "tcp", "127.0.0.1:0")
+		address := listener.Addr().String()
+		_ = listener.Close()
+		h := handlertest.New(t, NewFluentHandlerFromConfig, map[string]any{
+			"address":    address,
+			"tag":        "app",
+			"maxRetries": 0,
+		})
+		h.now = clock
+		handlertest.Write(t, h, records[0])
+		assert.Error(t, h.Sync())
+		assert.Equal(t, uint64(1), h.Failed())
+		listener = listen(t, "tcp", address)
+		entries, _ := serve(listener, 0)
+		handlertest.Write(t, h, records[1])
+		assert.NoError(t, h.Sync())
+		assert.Equal(t, expected[1], handlertest.Receive(t, entries, 1)[0].record)
+		assert.NoError(t, h.Close())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := NewFluentHandlerFromConfig(map[string]any{})
+		assert.IsType(t, new(TagMissingException), err)
+		_, err = NewFluentHandlerFromConfig(map[string]any{"tag": "app", "network": "udp"})
+		assert.IsType(t, new(InvalidNetworkException), err)
+		_, err = NewFluentHandlerFromConfig(map[string]any{"tag": "app", "mode": "message"})
+		assert.IsType(t, new(InvalidModeException), err)
+	})
+}
+
+func TestMsgpack(t *testing.T) {
+	obj, err := fromJSON([]byte(`{"b":1,"a":{"c":[2.5,"d"]}}`))
+	if assert.NoError(t, err) {
+		assert.Equal(t, object{{key: "b", value: int64(1)}, {key: "a", value: object{{key: "c", value: []any{2.5, "d"}}}}}, obj)
+	}
+	b, err := marshal([]any{eventTime(time.Unix(1792238400, 5)), obj})
+	if assert.NoError(t, err) {
+		// the keys keep their order and the time is the EventTime extension
+		assert.Equal(t, []byte{
+			0x92,
+			0xd7, 0x00, 0x6a, 0xd3, 0x63, 0x40, 0x00, 0x00, 0x00, 0x05,
+			0x82, 0xa1, 'b', 0x01, 0xa1, 'a', 0x81, 0xa1, 'c', 0x92, 0xcb, 0x40, 0x04, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xa1, 'd',
+		}, b)
+	}
+
+	// a value MessagePack cannot encode fails instead of panicking
+	_, err = marshal(object{{key: "c", value: make(chan int)}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,119 @@
+package fluent
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// object is a map keeping the order of its keys, decoded from a JSON object.
+type object []field
+
+type field struct {
+	key   string
+	value any
+}
+
+// EncodeMsgpack encodes the object as a map, keeping the order of its keys.
+func (o object) EncodeMsgpack(enc *msgpack.Encoder) error {
+	if err := enc.EncodeMapLen(len(o)); err != nil {
+		return err
+	}
+	for _, f := range o {
+		if err := enc.EncodeString(f.key); err != nil {
+			return err
+		}
+		if err := enc.Encode(f.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// eventTime is the EventTime extension of the forward protocol, a time with nanoseconds.
+type eventTime time.Time
+
+// EncodeMsgpack encodes the time as the extension of type 0, with its seconds and nanoseconds.
+func (t eventTime) EncodeMsgpack(enc *msgpack.Encoder) error {
+	if err := enc.EncodeExtHeader(0, 8); err != nil {
+		return err
+	}
+	b := binary.BigEndian.AppendUint32(make([]byte, 0, 8), uint32(time.Time(t).Unix()))
+	b = binary.BigEndian.AppendUint32(b, uint32(time.Time(t).Nanosecond()))
+	_, err := enc.Writer().Write(b)
+	return err
+}
+
+// marshal encodes v in MessagePack with the integers in their smallest format,
+// it fails if v has a value MessagePack cannot encode, e.g. a channel.
+func marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.UseCompactInts(true)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// fromJSON decodes a JSON value keeping the order of the object keys, the numbers are int64 or float64.
+func fromJSON(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	v, err := decodeJSON(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("json: trailing data")
+	}
+	return v, nil
+}
+
+func decodeJSON(dec *json.Decoder) (any, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := token.(type) {
+	case json.Delim:
+		if t == '[' {
+			array := []any{}
+			for dec.More() {
+				v, err := decodeJSON(dec)
+				if err != nil {
+					return nil, err
+				}
+				array = append(array, v)
+			}
+			_, err := dec.Token()
+			return array, err
+		}
+		obj := object{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, field{key: key.(string), value: v})
+		}
+		_, err := dec.Token()
+		return obj, err
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i, nil
+		}
+		return t.Float64()
+	default:
+		return t, nil
+	}
+}
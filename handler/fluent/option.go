@@ -0,0 +1,116 @@
+package fluent
+
+import (
+	"crypto/tls"
+	"time"
+
+	"github.com/gopi-frame/logger/handler/internal/backoff"
+)
+
+type Option func(h *FluentHandler)
+
+// WithAddress sets the network and the address of the server, the network is "tcp" or "unix",
+// empty values are ignored.
+func WithAddress(network, address string) Option {
+	return func(h *FluentHandler) {
+		if network != "" {
+			h.network = network
+		}
+		if address != "" {
+			h.address = address
+		}
+	}
+}
+
+// WithMode sets the mode of the forward protocol, [ModeForward] by default.
+func WithMode(mode Mode) Option {
+	return func(h *FluentHandler) {
+		h.mode = mode
+	}
+}
+
+// WithAck asks the server to acknowledge every message with its chunk option,
+// a message is sent again if it is not acknowledged in time, so it may be received twice.
+func WithAck(ack bool) Option {
+	return func(h *FluentHandler) {
+		h.ack = ack
+	}
+}
+
+// WithAckTimeout sets the timeout to receive the ack of a message, it is ignored if it is not positive.
+func WithAckTimeout(timeout time.Duration) Option {
+	return func(h *FluentHandler) {
+		if timeout > 0 {
+			h.ackTimeout = timeout
+		}
+	}
+}
+
+// WithTLSConfig connects over TLS with the given configuration, e.g. to the secure forward input.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(h *FluentHandler) {
+		h.tlsConfig = config
+	}
+}
+
+// WithDialTimeout sets the timeout to connect, it is ignored if it is not positive.
+func WithDialTimeout(timeout time.Duration) Option {
+	return func(h *FluentHandler) {
+		if timeout > 0 {
+			h.dialTimeout = timeout
+		}
+	}
+}
+
+// WithWriteTimeout sets the timeout to write a message, it is ignored if it is not positive.
+func WithWriteTimeout(timeout time.Duration) Option {
+	return func(h *FluentHandler) {
+		if timeout > 0 {
+			h.writeTimeout = timeout
+		}
+	}
+}
+
+// WithMaxRetries sets the number of retries of a message, [DefaultMaxRetries] by default,
+// the retries are disabled if it is not positive.
+func WithMaxRetries(n int) Option {
+	return func(h *FluentHandler) {
+		h.maxRetries = n
+	}
+}
+
+// WithBackoff sets the backoff between the retries, see [backoff.Backoff] for the defaults.
+func WithBackoff(b backoff.Backoff) Option {
+	return func(h *FluentHandler) {
+		h.backoff = b
+	}
+}
+
+// WithBatchSize sets the max number of records of a message, see [batch.DefaultMaxCount] for the default.
+func WithBatchSize(size int) Option {
+	return func(h *FluentHandler) {
+		h.batch.MaxCount = size
+	}
+}
+
+// WithBatchBytes sets the max size of the records of a message before encoding,
+// see [batch.DefaultMaxBytes] for the default.
+func WithBatchBytes(size int) Option {
+	return func(h *FluentHandler) {
+		h.batch.MaxBytes = size
+	}
+}
+
+// WithFlushInterval sets the max time a record waits before it is sent, see [batch.DefaultInterval] for the default.
+func WithFlushInterval(interval time.Duration) Option {
+	return func(h *FluentHandler) {
+		h.batch.Interval = interval
+	}
+}
+
+// WithErrorHandler sets the function called with the errors of the messages failed after all the retries.
+func WithErrorHandler(handler func(error)) Option {
+	return func(h *FluentHandler) {
+		h.batch.ErrorHandler = handler
+	}
+}
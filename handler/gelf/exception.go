@@ -0,0 +1,38 @@
+package gelf
+
+import (
+	"fmt"
+
+	. "github.com/gopi-frame/contract/exception"
+	"github.com/gopi-frame/exception"
+)
+
+type InvalidNetworkException struct {
+	Throwable
+}
+
+func NewInvalidNetworkException(network string) *InvalidNetworkException {
+	return &InvalidNetworkException{
+		Throwable: exception.New(fmt.Sprintf("invalid network [%s]", network)),
+	}
+}
+
+type InvalidCompressionException struct {
+	Throwable
+}
+
+func NewInvalidCompressionException(compression string) *InvalidCompressionException {
+	return &InvalidCompressionException{
+		Throwable: exception.New(fmt.Sprintf("invalid compression [%s]", compression)),
+	}
+}
+
+type MessageTooLargeException struct {
+	Throwable
+}
+
+func NewMessageTooLargeException(size int, chunks int) *MessageTooLargeException {
+	return &MessageTooLargeException{
+		Throwable: exception.New(fmt.Sprintf("message of %d bytes needs %d chunks, more than %d", size, chunks, MaxChunks)),
+	}
+}
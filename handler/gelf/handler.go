@@ -0,0 +1,290 @@
+package gelf
+
+import (
+	"crypto/rand"
+	"crypto/tls"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/gopi-frame/env"
+	"github.com/gopi-frame/logger"
+	"github.com/gopi-frame/logger/handler/internal/tlsconfig"
+)
+
+var handlerName = "gelf"
+
+//goland:noinspection GoBoolExpressions
+func init() {
+	if handlerName != "" {
+		logger.RegisterHandler(handlerName, func(config map[string]any) (io.WriteCloser, error) {
+			return NewGELFHandlerFromConfig(config)
+		})
+		logger.RegisterHandlerValidator(handlerName, func(config map[string]any) error {
+			_, _, _, err := parseConfig(config)
+			return err
+		})
+	}
+}
+
+const (
+	// DefaultAddress is the address of the GELF input of Graylog when none is given.
+	DefaultAddress = "127.0.0.1:12201"
+	// DefaultChunkSize is the max size of a UDP datagram when none is given, safe over the internet.
+	DefaultChunkSize = 1420
+	// DefaultTimeout is the timeout to connect and write when none is given.
+	DefaultTimeout = 5 * time.Second
+	// MaxChunks is the max number of chunks of a message.
+	MaxChunks = 128
+)
+
+// chunkHeaderSize is the size of the header of a chunk: the magic bytes, the message id,
+// the sequence number and the sequence count.
+const chunkHeaderSize = 12
+
+// GELFHandler sends every record as a GELF message to Graylog, over UDP or TCP.
+//
+// Over UDP, a message is compressed if enabled, and split into chunks if it is larger than the chunk size.
+// Over TCP, the messages are uncompressed and terminated by a null byte.
+// The connection is opened on the first write, and opened again when a write fails,
+// the failed message is sent once more on the new connection.
+type GELFHandler struct {
+	mu          sync.Mutex
+	network     string
+	address     string
+	host        string
+	messageKey  string
+	levelKey    string
+	compression Compression
+	chunkSize   int
+	tlsConfig   *tls.Config
+	timeout     time.Duration
+	conn        net.Conn
+	closed      bool
+	now         func() time.Time // for testing
+}
+
+// NewGELFHandler creates a new GELF handler for the given network, "udp" or "tcp" or their variants,
+// and address, [DefaultAddress] if it is empty.
+func NewGELFHandler(network, address string, opts ...Option) (*GELFHandler, error) {
+	h := &GELFHandler{
+		network:     network,
+		address:     address,
+		messageKey:  "msg",
+		levelKey:    "level",
+		compression: CompressionNone,
+		chunkSize:   DefaultChunkSize,
+		timeout:     DefaultTimeout,
+		now:         time.Now,
+	}
+	h.host, _ = os.Hostname()
+	switch network {
+	case "udp", "udp4", "udp6", "tcp", "tcp4", "tcp6":
+	default:
+		return nil, NewInvalidNetworkException(network)
+	}
+	if h.address == "" {
+		h.address = DefaultAddress
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	if h.tlsConfig != nil && !h.stream() {
+		return nil, NewInvalidNetworkException(network + " with TLS")
+	}
+	return h, nil
+}
+
+func NewGELFHandlerFromConfig(config map[string]any) (*GELFHandler, error) {
+	network, address, opts, err := parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return NewGELFHandler(network, address, opts...)
+}
+
+// parseConfig decodes the config into the arguments and the options of [NewGELFHandler].
+func parseConfig(config map[string]any) (string, string, []Option, error) {
+	var cfg struct {
+		Network     string
+		Address     string
+		Host        string
+		MessageKey  string
+		LevelKey    string
+		Compression string
+		ChunkSize   int
+		TLS         tlsconfig.Config
+		Timeout     time.Duration
+	}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           &cfg,
+		WeaklyTypedInput: true,
+		MatchName: func(mapKey, fieldName string) bool {
+			return strings.EqualFold(mapKey, fieldName) || strings.EqualFold(fieldName, strings.ReplaceAll(mapKey, "_", ""))
+		},
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			env.ExpandStringWithEnvHookFunc(),
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToBasicTypeHookFunc(),
+		),
+	})
+	if err != nil {
+		return "", "", nil, err
+	}
+	if err := decoder.Decode(config); err != nil {
+		return "", "", nil, err
+	}
+	if cfg.Network == "" {
+		cfg.Network = "udp"
+	}
+	compression, err := ParseCompression(cfg.Compression)
+	if err != nil {
+		return "", "", nil, err
+	}
+	opts := []Option{
+		WithHost(cfg.Host),
+		WithMessageKey(cfg.MessageKey),
+		WithLevelKey(cfg.LevelKey),
+		WithCompression(compression),
+		WithChunkSize(cfg.ChunkSize),
+		WithTimeout(cfg.Timeout),
+	}
+	tlsConfig, err := cfg.TLS.Build()
+	if err != nil {
+		return "", "", nil, err
+	}
+	if tlsConfig != nil {
+		opts = append(opts, WithTLSConfig(tlsConfig))
+	}
+	return cfg.Network, cfg.Address, opts, nil
+}
+
+// stream reports whether the messages are sent over TCP.
+func (h *GELFHandler) stream() bool {
+	return strings.HasPrefix(h.network, "tcp")
+}
+
+// dial connects to the server.
+func (h *GELFHandler) dial() error {
+	dialer := &net.Dialer{Timeout: h.timeout}
+	var conn net.Conn
+	var err error
+	if h.tlsConfig != nil {
+		conn, err = tls.DialWithDialer(dialer, h.network, h.address, h.tlsConfig)
+	} else {
+		conn, err = dialer.Dial(h.network, h.address)
+	}
+	if err != nil {
+		return err
+	}
+	h.conn = conn
+	return nil
+}
+
+// packets returns the packets of the message: the message terminated by a null byte over TCP,
+// or the message compressed over UDP, split into chunks if it is larger than the chunk size.
+func (h *GELFHandler) packets(msg []byte) ([][]byte, error) {
+	if h.stream() {
+		return [][]byte{append(msg, 0)}, nil
+	}
+	msg, err := h.compression.compress(msg)
+	if err != nil {
+		return nil, err
+	}
+	if len(msg) <= h.chunkSize {
+		return [][]byte{msg}, nil
+	}
+	size := h.chunkSize - chunkHeaderSize
+	count := (len(msg) + size - 1) / size
+	if count > MaxChunks {
+		return nil, NewMessageTooLargeException(len(msg), count)
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	packets := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		chunk := make([]byte, 0, h.chunkSize)
+		chunk = append(chunk, 0x1e, 0x0f)
+		chunk = append(chunk, id...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, msg[i*size:min((i+1)*size, len(msg))]...)
+		packets = append(packets, chunk)
+	}
+	return packets, nil
+}
+
+// send sends the packets on the connection, connecting first if needed.
+func (h *GELFHandler) send(packets [][]byte) error {
+	if h.conn == nil {
+		if err := h.dial(); err != nil {
+			return err
+		}
+	}
+	if h.timeout > 0 {
+		if err := h.conn.SetWriteDeadline(time.Now().Add(h.timeout)); err != nil {
+			return err
+		}
+	}
+	for _, packet := range packets {
+		if _, err := h.conn.Write(packet); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write converts the record to a GELF message and sends it.
+func (h *GELFHandler) Write(p []byte) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return 0, net.ErrClosed
+	}
+	msg, err := h.message(p, h.now())
+	if err != nil {
+		return 0, err
+	}
+	packets, err := h.packets(msg)
+	if err != nil {
+		return 0, err
+	}
+	if err := h.send(packets); err != nil {
+		_ = h.disconnect()
+		if err := h.send(packets); err != nil {
+			_ = h.disconnect()
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// disconnect closes the connection, it is opened again on the next write.
+func (h *GELFHandler) disconnect() error {
+	if h.conn == nil {
+		return nil
+	}
+	err := h.conn.Close()
+	h.conn = nil
+	return err
+}
+
+// Reopen closes the connection, it is opened again on the next write.
+func (h *GELFHandler) Reopen() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.disconnect()
+}
+
+// Close closes the connection, writes after Close fail with [net.ErrClosed].
+func (h *GELFHandler) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	return h.disconnect()
+}
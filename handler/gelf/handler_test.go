@@ -0,0 +1,199 @@
+package gelf
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gopi-frame/logger/handler/internal/handlertest"
+	"github.com/stretchr/testify/assert"
+)
+
+// receiveUDP receives the messages sent to the connection, reassembling the chunks and decompressing them.
+func receiveUDP(conn net.PacketConn) (<-chan map[string]any, <-chan int) {
+	messages := make(chan map[string]any, 10)
+	datagrams := make(chan int, 1000)
+	go func() {
+		chunks := make(map[string][][]byte)
+		buf := make([]byte, 65536)
+		for {
+			n, _, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			datagrams <- n
+			data := append([]byte(nil), buf[:n]...)
+			if data[0] == 0x1e && data[1] == 0x0f {
+				id, seq, count := string(data[2:10]), data[10], data[11]
+				if chunks[id] == nil {
+					chunks[id] = make([][]byte, count)
+				}
+				chunks[id][seq] = data[12:]
+				complete := true
+				for _, chunk := range chunks[id] {
+					complete = complete && chunk != nil
+				}
+				if !complete {
+					continue
+				}
+				data = bytes.Join(chunks[id], nil)
+				delete(chunks, id)
+			}
+			var r io.Reader = bytes.NewReader(data)
+			if data[0] == 0x1f && data[1] == 0x8b {
+				r, _ = gzip.NewReader(r)
+			} else if data[0] == 0x78 {
+				r, _ = zlib.NewReader(r)
+			}
+			var msg map[string]any
+			if json.NewDecoder(r).Decode(&msg) == nil {
+				messages <- msg
+			}
+		}
+	}()
+	return messages, datagrams
+}
+
+func clock() time.Time {
+	return time.Date(2026, 10, 17, 12, 0, 0, 250000000, time.UTC)
+}
+
+func TestGELFHandler(t *testing.T) {
+	record := `{"level":"error","msg":"failed","id":7,"http":{"status":500,"path":"/a b"},"tags":["x","y"],"ok":false,"none":null}` + "\n"
+	expected := map[string]any{
+		"version":       "1.1",
+		"host":          "web-1",
+		"short_message": "failed",
+		"timestamp":     1792238400.25,
+		"level":         float64(3),
+		"_id_":          float64(7),
+		"_http_status":  float64(500),
+		"_http_path":    "/a b",
+		"_tags":         `["x","y"]`,
+		"_ok":           "false",
+	}
+
+	for _, compression := range []string{"none", "gzip", "zlib"} {
+		t.Run("udp "+compression, func(t *testing.T) {
+			conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+			if !assert.NoError(t, err) {
+				assert.FailNow(t, err.Error())
+			}
+			defer conn.Close()
+			messages, _ := receiveUDP(conn)
+			h := handlertest.New(t, NewGELFHandlerFromConfig, map[string]any{
+				"address":     conn.LocalAddr().String(),
+				"host":        "web-1",
+				"compression": compression,
+			})
+			h.now = clock
+			handlertest.Write(t, h, record)
+			assert.Equal(t, expected, handlertest.Receive(t, messages, 1)[0])
+			handlertest.Write(t, h, "plain text\n")
+			msg := handlertest.Receive(t, messages, 1)[0]
+			assert.Equal(t, "plain text", msg["short_message"])
+			assert.Equal(t, float64(6), msg["level"])
+			assert.NoError(t, h.Close())
+			_, err = h.Write([]byte(record))
+			assert.ErrorIs(t, err, net.ErrClosed)
+		})
+	}
+
+	t.Run("udp chunks", func(t *testing.T) {
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		defer conn.Close()
+		messages, datagrams := receiveUDP(conn)
+		h := handlertest.New(t, NewGELFHandlerFromConfig, map[string]any{
+			"address":   conn.LocalAddr().String(),
+			"chunkSize": 100,
+		})
+		h.now = clock
+		long := strings.Repeat("x", 1000)
+		handlertest.Write(t, h, `{"msg":"`+long+`"}`)
+		assert.Equal(t, long, handlertest.Receive(t, messages, 1)[0]["short_message"])
+		assert.Greater(t, len(datagrams), 10)
+		for len(datagrams) > 0 {
+			assert.LessOrEqual(t, <-datagrams, 100)
+		}
+
+		// more than 128 chunks
+		_, err = h.Write([]byte(strings.Repeat("x", 100*MaxChunks)))
+		assert.IsType(t, new(MessageTooLargeException), err)
+		assert.NoError(t, h.Close())
+	})
+
+	t.Run("tcp", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		defer listener.Close()
+		messages := make(chan map[string]any, 10)
+		accepted := make(chan net.Conn, 10)
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				accepted <- conn
+				go func() {
+					r := bufio.NewReader(conn)
+					for {
+						data, err := r.ReadBytes(0)
+						if err != nil {
+							return
+						}
+						var msg map[string]any
+						if json.Unmarshal(data[:len(data)-1], &msg) == nil {
+							messages <- msg
+						}
+					}
+				}()
+			}
+		}()
+		h := handlertest.New(t, NewGELFHandlerFromConfig, map[string]any{
+			"network":     "tcp",
+			"address":     listener.Addr().String(),
+			"host":        "web-1",
+			"compression": "gzip", // ignored over TCP
+		})
+		h.now = clock
+		handlertest.Write(t, h, record)
+		assert.Equal(t, expected, handlertest.Receive(t, messages, 1)[0])
+
+		// reconnect after the server closed the connection
+		_ = (<-accepted).Close()
+		assert.Eventually(t, func() bool {
+			if _, err := h.Write([]byte(record)); err != nil {
+				return false
+			}
+			select {
+			case msg := <-messages:
+				return assert.Equal(t, expected, msg)
+			case <-time.After(50 * time.Millisecond):
+				return false
+			}
+		}, time.Second, 10*time.Millisecond)
+		assert.NoError(t, h.Close())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := NewGELFHandlerFromConfig(map[string]any{"network": "unix"})
+		assert.IsType(t, new(InvalidNetworkException), err)
+		_, err = NewGELFHandlerFromConfig(map[string]any{"compression": "zstd"})
+		assert.IsType(t, new(InvalidCompressionException), err)
+		_, err = NewGELFHandlerFromConfig(map[string]any{"tls": map[string]any{"enabled": true}})
+		assert.IsType(t, new(InvalidNetworkException), err)
+	})
+}
@@ -0,0 +1,143 @@
+package gelf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gopi-frame/logger"
+)
+
+// Compression is the compression of the messages sent over UDP.
+type Compression string
+
+const (
+	CompressionNone Compression = "none"
+	CompressionGzip Compression = "gzip"
+	CompressionZlib Compression = "zlib"
+)
+
+// ParseCompression parses a compression case-insensitively, an empty string is [CompressionNone].
+func ParseCompression(s string) (Compression, error) {
+	switch strings.ToLower(s) {
+	case "", "none":
+		return CompressionNone, nil
+	case "gzip":
+		return CompressionGzip, nil
+	case "zlib":
+		return CompressionZlib, nil
+	default:
+		return "", NewInvalidCompressionException(s)
+	}
+}
+
+// compress returns the data compressed.
+func (c Compression) compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch c {
+	case CompressionGzip:
+		w = gzip.NewWriter(&buf)
+	case CompressionZlib:
+		w = zlib.NewWriter(&buf)
+	default:
+		return data, nil
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// severityOf returns the syslog severity of a logger level, the level of a GELF message.
+func severityOf(level logger.Level) int {
+	switch level {
+	case logger.LevelDebug:
+		return 7
+	case logger.LevelWarn:
+		return 4
+	case logger.LevelError:
+		return 3
+	case logger.LevelPanic:
+		return 2
+	case logger.LevelFatal:
+		return 1
+	default:
+		return 6
+	}
+}
+
+// message converts a record to a GELF 1.1 message.
+//
+// The short message is the message field of a JSON record, or the whole record otherwise,
+// the level is the syslog severity of its level field, and its other fields are additional fields:
+// the nested objects are flattened, e.g. _http_status, the arrays are encoded as JSON strings,
+// the booleans as strings, and the null values are omitted.
+func (h *GELFHandler) message(record []byte, t time.Time) ([]byte, error) {
+	record = bytes.TrimRight(record, "\r\n")
+	msg := map[string]any{
+		"version":       "1.1",
+		"host":          h.host,
+		"short_message": string(record),
+		"timestamp":     json.Number(strconv.FormatFloat(float64(t.UnixMicro())/1e6, 'f', 6, 64)),
+		"level":         6,
+	}
+	dec := json.NewDecoder(bytes.NewReader(record))
+	dec.UseNumber()
+	var fields map[string]any
+	if dec.Decode(&fields) != nil || dec.More() {
+		return json.Marshal(msg)
+	}
+	if s, ok := fields[h.messageKey].(string); ok && s != "" {
+		msg["short_message"] = s
+		delete(fields, h.messageKey)
+	}
+	if s, ok := fields[h.levelKey].(string); ok {
+		var level logger.Level
+		if level.UnmarshalText([]byte(s)) == nil {
+			msg["level"] = severityOf(level)
+			delete(fields, h.levelKey)
+		}
+	}
+	for key, value := range fields {
+		addField(msg, key, value)
+	}
+	return json.Marshal(msg)
+}
+
+// addField adds an additional field to the message, prefixed with an underscore.
+func addField(msg map[string]any, key string, value any) {
+	switch v := value.(type) {
+	case nil:
+		return
+	case map[string]any:
+		for k, e := range v {
+			addField(msg, key+"_"+k, e)
+		}
+		return
+	case []any:
+		data, _ := json.Marshal(v)
+		value = string(data)
+	case bool:
+		value = strconv.FormatBool(v)
+	}
+	name := []byte("_" + key)
+	for i, c := range name {
+		if c != '_' && c != '.' && c != '-' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			name[i] = '_'
+		}
+	}
+	// _id is reserved
+	if string(name) == "_id" {
+		name = append(name, '_')
+	}
+	msg[string(name)] = value
+}
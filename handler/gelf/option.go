@@ -0,0 +1,68 @@
+package gelf
+
+import (
+	"crypto/tls"
+	"time"
+)
+
+type Option func(h *GELFHandler)
+
+// WithHost sets the host of the messages, the hostname by default.
+func WithHost(host string) Option {
+	return func(h *GELFHandler) {
+		if host != "" {
+			h.host = host
+		}
+	}
+}
+
+// WithMessageKey sets the key of the message in the records, the short message, "msg" by default.
+func WithMessageKey(key string) Option {
+	return func(h *GELFHandler) {
+		if key != "" {
+			h.messageKey = key
+		}
+	}
+}
+
+// WithLevelKey sets the key of the level in the records, which gives the level of the messages, "level" by default.
+func WithLevelKey(key string) Option {
+	return func(h *GELFHandler) {
+		if key != "" {
+			h.levelKey = key
+		}
+	}
+}
+
+// WithCompression sets the compression of the messages sent over UDP, [CompressionNone] by default.
+func WithCompression(compression Compression) Option {
+	return func(h *GELFHandler) {
+		h.compression = compression
+	}
+}
+
+// WithChunkSize sets the max size of a UDP datagram, larger messages are chunked, [DefaultChunkSize] by default,
+// e.g. 8154 on a local network. It is ignored if it is not larger than the chunk header.
+func WithChunkSize(size int) Option {
+	return func(h *GELFHandler) {
+		if size > chunkHeaderSize {
+			h.chunkSize = size
+		}
+	}
+}
+
+// WithTLSConfig connects over TLS with the given configuration, for TCP only.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(h *GELFHandler) {
+		h.tlsConfig = config
+	}
+}
+
+// WithTimeout sets the timeout to connect and write, it is ignored if it is not positive.
+func WithTimeout(timeout time.Duration) Option {
+	return func(h *GELFHandler) {
+		if timeout > 0 {
+			h.timeout = timeout
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package http
+
+import (
+	"fmt"
+
+	. "github.com/gopi-frame/contract/exception"
+	"github.com/gopi-frame/exception"
+)
+
+type InvalidURLException struct {
+	Throwable
+}
+
+func NewInvalidURLException(url string) *InvalidURLException {
+	return &InvalidURLException{
+		Throwable: exception.New(fmt.Sprintf("invalid url [%s]", url)),
+	}
+}
+
+type InvalidFormatException struct {
+	Throwable
+}
+
+func NewInvalidFormatException(format string) *InvalidFormatException {
+	return &InvalidFormatException{
+		Throwable: exception.New(fmt.Sprintf("invalid format [%s]", format)),
+	}
+}
@@ -0,0 +1,283 @@
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/gopi-frame/env"
+	"github.com/gopi-frame/logger"
+	"github.com/gopi-frame/logger/handler/internal/backoff"
+	"github.com/gopi-frame/logger/handler/internal/batch"
+	"github.com/gopi-frame/logger/handler/internal/httpsend"
+	"github.com/gopi-frame/logger/handler/internal/tlsconfig"
+)
+
+var handlerName = "http"
+
+//goland:noinspection GoBoolExpressions
+func init() {
+	if handlerName != "" {
+		logger.RegisterHandler(handlerName, func(config map[string]any) (io.WriteCloser, error) {
+			return NewHTTPHandlerFromConfig(config)
+		})
+		logger.RegisterHandlerValidator(handlerName, func(config map[string]any) error {
+			_, _, err := parseConfig(config)
+			return err
+		})
+	}
+}
+
+const (
+	// DefaultTimeout is the timeout of a request when none is given.
+	DefaultTimeout = 10 * time.Second
+)
+
+// Format is the format of the request bodies.
+type Format string
+
+const (
+	// FormatNDJSON sends the records one per line, as application/x-ndjson.
+	FormatNDJSON Format = "ndjson"
+	// FormatJSON sends the records as a JSON array, as application/json.
+	// The records which are not valid JSON are sent as strings.
+	FormatJSON Format = "json"
+)
+
+// ParseFormat parses a format case-insensitively.
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "ndjson":
+		return FormatNDJSON, nil
+	case "json":
+		return FormatJSON, nil
+	default:
+		return "", NewInvalidFormatException(s)
+	}
+}
+
+// HTTPHandler sends the records in batches to an HTTP endpoint, e.g. a webhook or an ingestion endpoint.
+//
+// The records are grouped into a batch sent in the background when it is full
+// or when the flush interval has elapsed since its first record.
+// A request failing with a network error, a 429 or a 5xx status is retried with a jittered exponential backoff,
+// the records of the requests failing after all the retries are dropped, see [HTTPHandler.Failed].
+type HTTPHandler struct {
+	url       string
+	method    string
+	format    Format
+	headers   http.Header
+	gzip      bool
+	timeout   time.Duration
+	tlsConfig *tls.Config
+	batch     batch.Options
+	sender    httpsend.Sender
+	batcher   *batch.Batcher
+}
+
+// NewHTTPHandler creates a new HTTP handler sending the records to the given URL.
+func NewHTTPHandler(endpoint string, opts ...Option) (*HTTPHandler, error) {
+	if u, err := url.Parse(endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, NewInvalidURLException(endpoint)
+	}
+	h := &HTTPHandler{
+		url:     endpoint,
+		method:  http.MethodPost,
+		format:  FormatNDJSON,
+		headers: make(http.Header),
+		timeout: DefaultTimeout,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	if h.sender.Client == nil {
+		h.sender.Client = httpsend.NewClient(h.timeout, h.tlsConfig)
+	}
+	h.batcher = batch.New(h.send, h.batch)
+	return h, nil
+}
+
+func NewHTTPHandlerFromConfig(config map[string]any) (*HTTPHandler, error) {
+	url, opts, err := parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return NewHTTPHandler(url, opts...)
+}
+
+// parseConfig decodes the config into the arguments and the options of [NewHTTPHandler].
+func parseConfig(config map[string]any) (string, []Option, error) {
+	var cfg struct {
+		URL           string
+		Method        string
+		Format        string
+		Headers       map[string]string
+		Gzip          bool
+		BatchSize     int
+		BatchBytes    int
+		FlushInterval time.Duration
+		MaxInFlight   int
+		MaxRetries    int
+		Backoff       backoff.Backoff
+		Timeout       time.Duration
+		TLS           tlsconfig.Config
+	}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           &cfg,
+		WeaklyTypedInput: true,
+		MatchName: func(mapKey, fieldName string) bool {
+			return strings.EqualFold(mapKey, fieldName) || strings.EqualFold(fieldName, strings.ReplaceAll(mapKey, "_", ""))
+		},
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			env.ExpandStringWithEnvHookFunc(),
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToBasicTypeHookFunc(),
+		),
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	if err := decoder.Decode(config); err != nil {
+		return "", nil, err
+	}
+	opts := []Option{
+		WithMethod(cfg.Method),
+		WithHeaders(cfg.Headers),
+		WithGzip(cfg.Gzip),
+		WithBatchSize(cfg.BatchSize),
+		WithBatchBytes(cfg.BatchBytes),
+		WithFlushInterval(cfg.FlushInterval),
+		WithMaxInFlight(cfg.MaxInFlight),
+		WithMaxRetries(cfg.MaxRetries),
+		WithBackoff(cfg.Backoff),
+		WithTimeout(cfg.Timeout),
+	}
+	if cfg.Format != "" {
+		format, err := ParseFormat(cfg.Format)
+		if err != nil {
+			return "", nil, err
+		}
+		opts = append(opts, WithFormat(format))
+	}
+	tlsConfig, err := cfg.TLS.Build()
+	if err != nil {
+		return "", nil, err
+	}
+	if tlsConfig != nil {
+		opts = append(opts, WithTLSConfig(tlsConfig))
+	}
+	return cfg.URL, opts, nil
+}
+
+// encode encodes the records into the request body, compressed if needed.
+func (h *HTTPHandler) encode(records [][]byte) ([]byte, error) {
+	var body bytes.Buffer
+	var w io.Writer = &body
+	var zw *gzip.Writer
+	if h.gzip {
+		zw = gzip.NewWriter(&body)
+		w = zw
+	}
+	if h.format == FormatJSON {
+		if _, err := w.Write([]byte{'['}); err != nil {
+			return nil, err
+		}
+	}
+	for i, record := range records {
+		record = bytes.TrimRight(record, "\r\n")
+		if h.format == FormatJSON {
+			if i > 0 {
+				if _, err := w.Write([]byte{','}); err != nil {
+					return nil, err
+				}
+			}
+			if !json.Valid(record) {
+				record, _ = json.Marshal(string(record))
+			}
+		}
+		if _, err := w.Write(record); err != nil {
+			return nil, err
+		}
+		if h.format == FormatNDJSON {
+			if _, err := w.Write([]byte{'\n'}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if h.format == FormatJSON {
+		if _, err := w.Write([]byte{']'}); err != nil {
+			return nil, err
+		}
+	}
+	if zw != nil {
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+	}
+	return body.Bytes(), nil
+}
+
+// send sends a batch of records in one request.
+func (h *HTTPHandler) send(ctx context.Context, records [][]byte) error {
+	body, err := h.encode(records)
+	if err != nil {
+		return err
+	}
+	_, err = h.sender.Do(ctx, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, h.method, h.url, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		for key, values := range h.headers {
+			req.Header[key] = values
+		}
+		if req.Header.Get("Content-Type") == "" {
+			if h.format == FormatJSON {
+				req.Header.Set("Content-Type", "application/json")
+			} else {
+				req.Header.Set("Content-Type", "application/x-ndjson")
+			}
+		}
+		if h.gzip {
+			req.Header.Set("Content-Encoding", "gzip")
+		}
+		return req, nil
+	})
+	return err
+}
+
+// Write adds the record to the batch, it is sent in the background.
+func (h *HTTPHandler) Write(p []byte) (int, error) {
+	return h.batcher.Write(p)
+}
+
+// Sync sends the batch and waits until all the requests are done,
+// it returns the error of the last request failed since the last sync.
+func (h *HTTPHandler) Sync() error {
+	return h.batcher.Flush()
+}
+
+// Failed returns the number of records dropped because their requests failed.
+func (h *HTTPHandler) Failed() uint64 {
+	return h.batcher.Failed()
+}
+
+// Shutdown stops accepting records, sends the batch and waits until all the requests are done or ctx is done,
+// then the requests still running are cancelled.
+func (h *HTTPHandler) Shutdown(ctx context.Context) error {
+	return h.batcher.Shutdown(ctx)
+}
+
+// Close stops accepting records, sends the batch and waits until all the requests are done,
+// writes after Close fail with [os.ErrClosed].
+func (h *HTTPHandler) Close() error {
+	return h.batcher.Close()
+}
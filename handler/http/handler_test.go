@@ -0,0 +1,200 @@
+package http
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gopi-frame/logger/handler/internal/handlertest"
+	"github.com/gopi-frame/logger/handler/internal/httpsend"
+	"github.com/stretchr/testify/assert"
+)
+
+// request is a request received by the test server.
+type request struct {
+	method string
+	header http.Header
+	body   string
+}
+
+// serve starts a server recording the requests and answering them with the given statuses in turn,
+// then with 200.
+func serve(t *testing.T, statuses ...int) (*httptest.Server, *handlertest.Recorder[request]) {
+	requests := new(handlertest.Recorder[request])
+	server := handlertest.Serve(t, func(w http.ResponseWriter, r *http.Request) {
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			body = zr
+		}
+		data, _ := io.ReadAll(body)
+		status := http.StatusOK
+		if n := requests.Add(request{method: r.Method, header: r.Header, body: string(data)}); n <= len(statuses) {
+			status = statuses[n-1]
+		}
+		w.WriteHeader(status)
+	})
+	return server, requests
+}
+
+func TestHTTPHandler(t *testing.T) {
+	t.Run("ndjson", func(t *testing.T) {
+		server, requests := serve(t)
+		h := handlertest.New(t, NewHTTPHandlerFromConfig, map[string]any{
+			"url":       server.URL,
+			"batchSize": 2,
+			"headers":   map[string]any{"Authorization": "Bearer token"},
+		})
+		handlertest.Write(t, h, `{"msg":"a"}`+"\n", `{"msg":"b"}`+"\n", `{"msg":"c"}`+"\n")
+		assert.NoError(t, h.Close())
+		got := requests.All()
+		if assert.Len(t, got, 2) {
+			assert.Equal(t, http.MethodPost, got[0].method)
+			assert.Equal(t, "application/x-ndjson", got[0].header.Get("Content-Type"))
+			assert.Equal(t, "Bearer token", got[0].header.Get("Authorization"))
+			assert.Equal(t, `{"msg":"a"}`+"\n"+`{"msg":"b"}`+"\n", got[0].body)
+			assert.Equal(t, `{"msg":"c"}`+"\n", got[1].body)
+		}
+		_, err := h.Write([]byte("d"))
+		assert.ErrorIs(t, err, os.ErrClosed)
+	})
+
+	t.Run("json gzip", func(t *testing.T) {
+		server, requests := serve(t)
+		h := handlertest.New(t, NewHTTPHandlerFromConfig, map[string]any{
+			"url":    server.URL,
+			"format": "JSON",
+			"gzip":   true,
+			"method": "PUT",
+		})
+		handlertest.Write(t, h, `{"msg":"a"}`+"\n", "plain text\n")
+		assert.NoError(t, h.Sync())
+		got := requests.All()
+		if assert.Len(t, got, 1) {
+			assert.Equal(t, http.MethodPut, got[0].method)
+			assert.Equal(t, "application/json", got[0].header.Get("Content-Type"))
+			assert.Equal(t, "gzip", got[0].header.Get("Content-Encoding"))
+			assert.Equal(t, `[{"msg":"a"},"plain text"]`, got[0].body)
+		}
+		assert.NoError(t, h.Close())
+	})
+
+	t.Run("flush interval", func(t *testing.T) {
+		server, requests := serve(t)
+		h := handlertest.New(t, NewHTTPHandlerFromConfig, map[string]any{
+			"url":           server.URL,
+			"flushInterval": "20ms",
+		})
+		defer func() {
+			_ = h.Close()
+		}()
+		handlertest.Write(t, h, "a\n")
+		assert.Eventually(t, func() bool {
+			return len(requests.All()) == 1
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		server, requests := serve(t, http.StatusServiceUnavailable, http.StatusTooManyRequests)
+		h := handlertest.New(t, NewHTTPHandlerFromConfig, map[string]any{
+			"url":     server.URL,
+			"backoff": map[string]any{"initial": "1ms"},
+		})
+		handlertest.Write(t, h, "a\n")
+		assert.NoError(t, h.Sync())
+		got := requests.All()
+		if assert.Len(t, got, 3) {
+			assert.Equal(t, "a\n", got[2].body)
+		}
+		assert.Equal(t, uint64(0), h.Failed())
+		assert.NoError(t, h.Close())
+	})
+
+	t.Run("no retry", func(t *testing.T) {
+		server, requests := serve(t, http.StatusBadRequest)
+		var handled atomic.Int32
+		h, err := NewHTTPHandler(server.URL, WithErrorHandler(func(error) {
+			handled.Add(1)
+		}))
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		handlertest.Write(t, h, "a\n", "b\n")
+		err = h.Sync()
+		var statusErr *httpsend.StatusError
+		if assert.ErrorAs(t, err, &statusErr) {
+			assert.Equal(t, http.StatusBadRequest, statusErr.StatusCode)
+		}
+		assert.Len(t, requests.All(), 1)
+		assert.Equal(t, uint64(2), h.Failed())
+		assert.Equal(t, int32(1), handled.Load())
+		assert.NoError(t, h.Close())
+	})
+
+	t.Run("max in flight", func(t *testing.T) {
+		var running, peak atomic.Int32
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			<-release
+		}))
+		defer server.Close()
+		h := handlertest.New(t, NewHTTPHandlerFromConfig, map[string]any{
+			"url":         server.URL,
+			"batchSize":   1,
+			"maxInFlight": 2,
+		})
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			close(release)
+		}()
+		handlertest.Write(t, h, "a\n", "b\n", "c\n", "d\n")
+		assert.NoError(t, h.Close())
+		assert.Equal(t, int32(2), peak.Load())
+	})
+
+	t.Run("shutdown", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}))
+		defer server.Close()
+		defer close(release)
+		h := handlertest.New(t, NewHTTPHandlerFromConfig, map[string]any{"url": server.URL})
+		handlertest.Write(t, h, "a\n")
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		err := h.Shutdown(ctx)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := NewHTTPHandlerFromConfig(map[string]any{})
+		assert.IsType(t, new(InvalidURLException), err)
+		_, err = NewHTTPHandlerFromConfig(map[string]any{"url": "ftp://localhost"})
+		assert.IsType(t, new(InvalidURLException), err)
+		_, err = NewHTTPHandlerFromConfig(map[string]any{"url": "http://localhost", "format": "xml"})
+		assert.IsType(t, new(InvalidFormatException), err)
+	})
+}
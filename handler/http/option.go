@@ -0,0 +1,125 @@
+package http
+
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+
+	"github.com/gopi-frame/logger/handler/internal/backoff"
+)
+
+type Option func(h *HTTPHandler)
+
+// WithMethod sets the method of the requests, POST by default.
+func WithMethod(method string) Option {
+	return func(h *HTTPHandler) {
+		if method != "" {
+			h.method = method
+		}
+	}
+}
+
+// WithFormat sets the format of the request bodies, [FormatNDJSON] by default.
+func WithFormat(format Format) Option {
+	return func(h *HTTPHandler) {
+		h.format = format
+	}
+}
+
+// WithHeader adds a header to the requests, e.g. an Authorization header.
+func WithHeader(key, value string) Option {
+	return func(h *HTTPHandler) {
+		h.headers.Add(key, value)
+	}
+}
+
+// WithHeaders adds the headers to the requests.
+func WithHeaders(headers map[string]string) Option {
+	return func(h *HTTPHandler) {
+		for key, value := range headers {
+			h.headers.Add(key, value)
+		}
+	}
+}
+
+// WithGzip compresses the request bodies with gzip.
+func WithGzip(gzip bool) Option {
+	return func(h *HTTPHandler) {
+		h.gzip = gzip
+	}
+}
+
+// WithBatchSize sets the max number of records of a request, see [batch.DefaultMaxCount] for the default.
+func WithBatchSize(size int) Option {
+	return func(h *HTTPHandler) {
+		h.batch.MaxCount = size
+	}
+}
+
+// WithBatchBytes sets the max size of the records of a request before encoding and compression,
+// see [batch.DefaultMaxBytes] for the default.
+func WithBatchBytes(size int) Option {
+	return func(h *HTTPHandler) {
+		h.batch.MaxBytes = size
+	}
+}
+
+// WithFlushInterval sets the max time a record waits before it is sent, see [batch.DefaultInterval] for the default.
+func WithFlushInterval(interval time.Duration) Option {
+	return func(h *HTTPHandler) {
+		h.batch.Interval = interval
+	}
+}
+
+// WithMaxInFlight sets the max number of requests sent concurrently, 1 by default,
+// the writes block when a batch is full and this many requests are being sent.
+func WithMaxInFlight(n int) Option {
+	return func(h *HTTPHandler) {
+		h.batch.MaxInFlight = n
+	}
+}
+
+// WithErrorHandler sets the function called with the errors of the requests failed after all the retries.
+func WithErrorHandler(handler func(error)) Option {
+	return func(h *HTTPHandler) {
+		h.batch.ErrorHandler = handler
+	}
+}
+
+// WithMaxRetries sets the number of retries of a request failing with a network error, a 429 or a 5xx status,
+// see [httpsend.DefaultMaxRetries] for the default, a negative number disables the retries.
+func WithMaxRetries(n int) Option {
+	return func(h *HTTPHandler) {
+		h.sender.MaxRetries = n
+	}
+}
+
+// WithBackoff sets the backoff between the retries, see [backoff.Backoff] for the defaults.
+func WithBackoff(b backoff.Backoff) Option {
+	return func(h *HTTPHandler) {
+		h.sender.Backoff = b
+	}
+}
+
+// WithTimeout sets the timeout of a request, it is ignored if it is not positive or a client is given.
+func WithTimeout(timeout time.Duration) Option {
+	return func(h *HTTPHandler) {
+		if timeout > 0 {
+			h.timeout = timeout
+		}
+	}
+}
+
+// WithTLSConfig sets the TLS configuration of the requests, it is ignored if a client is given.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(h *HTTPHandler) {
+		h.tlsConfig = config
+	}
+}
+
+// WithClient sets the client sending the requests.
+func WithClient(client *http.Client) Option {
+	return func(h *HTTPHandler) {
+		h.sender.Client = client
+	}
+}
@@ -0,0 +1,59 @@
+// Package backoff computes the delays between the retries of the network handlers.
+package backoff
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff computes exponentially growing delays, the zero value uses the defaults.
+type Backoff struct {
+	// Initial is the first delay, 100ms if it is not positive.
+	Initial time.Duration
+	// Max is the max delay, 30s if it is not positive.
+	Max time.Duration
+	// Multiplier is the factor between two delays, 2 if it is not greater than 1.
+	Multiplier float64
+	// Jitter is the fraction of the delay randomly taken off, up to 1,
+	// so that the clients failing together do not retry together.
+	// It is [DefaultJitter] if it is 0, and there is no jitter if it is negative.
+	Jitter float64
+}
+
+const (
+	DefaultInitial    = 100 * time.Millisecond
+	DefaultMax        = 30 * time.Second
+	DefaultMultiplier = 2
+	DefaultJitter     = 0.5
+)
+
+// MaxDelay returns the max delay, [DefaultMax] if Max is not positive.
+func (b Backoff) MaxDelay() time.Duration {
+	if b.Max <= 0 {
+		return DefaultMax
+	}
+	return b.Max
+}
+
+// Delay returns the delay before the retry after the given number of failed attempts, starting from 0.
+func (b Backoff) Delay(attempt int) time.Duration {
+	initial, max, multiplier, jitter := b.Initial, b.MaxDelay(), b.Multiplier, b.Jitter
+	if initial <= 0 {
+		initial = DefaultInitial
+	}
+	if multiplier <= 1 {
+		multiplier = DefaultMultiplier
+	}
+	if jitter == 0 {
+		jitter = DefaultJitter
+	}
+	delay := float64(initial) * math.Pow(multiplier, float64(attempt))
+	if delay > float64(max) {
+		delay = float64(max)
+	}
+	if jitter > 0 {
+		delay -= delay * min(jitter, 1) * rand.Float64()
+	}
+	return time.Duration(delay)
+}
@@ -0,0 +1,32 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff_Delay(t *testing.T) {
+	b := Backoff{Jitter: -1}
+	assert.Equal(t, 100*time.Millisecond, b.Delay(0))
+	assert.Equal(t, 400*time.Millisecond, b.Delay(2))
+	assert.Equal(t, 30*time.Second, b.Delay(100))
+
+	b = Backoff{Initial: time.Second, Max: 5 * time.Second, Multiplier: 3, Jitter: -1}
+	assert.Equal(t, 3*time.Second, b.Delay(1))
+	assert.Equal(t, 5*time.Second, b.Delay(2))
+
+	b = Backoff{Initial: time.Second, Jitter: 0.25}
+	for i := 0; i < 100; i++ {
+		delay := b.Delay(1)
+		assert.True(t, delay > 1500*time.Millisecond && delay <= 2*time.Second, delay)
+	}
+
+	// the default jitter
+	b = Backoff{Initial: time.Second}
+	for i := 0; i < 100; i++ {
+		delay := b.Delay(1)
+		assert.True(t, delay > time.Second && delay <= 2*time.Second, delay)
+	}
+}
@@ -0,0 +1,261 @@
+// Package batch groups the records written to the network handlers into batches sent in the background.
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultMaxCount    = 100
+	DefaultMaxBytes    = 1024 * 1024
+	DefaultInterval    = time.Second
+	DefaultMaxInFlight = 1
+)
+
+// Options configures a [Batcher], the zero values use the defaults.
+type Options struct {
+	// MaxCount is the max number of records of a batch.
+	MaxCount int
+	// MaxBytes is the max size of the records of a batch, a larger record is sent alone.
+	MaxBytes int
+	// Interval is the max time a record waits in the batch before it is sent.
+	Interval time.Duration
+	// MaxInFlight is the max number of batches sent concurrently,
+	// the writes block when a batch is full and this many batches are being sent, see [Release].
+	MaxInFlight int
+	// ErrorHandler is called with the errors of the batches failing to be sent, it must be safe for concurrent use.
+	ErrorHandler func(error)
+}
+
+// SendFunc sends a batch of records, retrying until ctx is done if it wants to.
+// The records must not be retained after it returns.
+type SendFunc func(ctx context.Context, records [][]byte) error
+
+// releaseKey is the key of the function releasing the in-flight slot of a batch in the context of its send.
+type releaseKey struct{}
+
+// Release releases the in-flight slot of the batch sent with ctx, so the next batch can be sent
+// while this one is still waited for, e.g. for an acknowledgement. The batch is still waited for
+// by [Batcher.Flush] and [Batcher.Shutdown]. It does nothing if it is called again or with another context.
+func Release(ctx context.Context) {
+	if release, ok := ctx.Value(releaseKey{}).(func()); ok {
+		release()
+	}
+}
+
+// PartialError is the error of a batch of which only some records failed to be sent.
+type PartialError struct {
+	// Failed is the number of records failed to be sent.
+	Failed int
+	Err    error
+}
+
+func (e *PartialError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PartialError) Unwrap() error {
+	return e.Err
+}
+
+// Batcher groups the records into batches, which are sent in the background
+// when they are full or when the interval has elapsed since their first record.
+type Batcher struct {
+	mu       sync.Mutex
+	opts     Options
+	send     SendFunc
+	records  [][]byte
+	size     int
+	inFlight chan struct{}
+	sending  sync.WaitGroup
+	// lastErr is the error of the last batch failed since the last wait, errCount counts them,
+	// so a long outage without flush does not accumulate errors.
+	errMu    sync.Mutex
+	lastErr  error
+	errCount int
+	failed   atomic.Uint64
+	ctx      context.Context
+	cancel   context.CancelFunc
+	closed   bool
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// New creates a new batcher sending the batches with send.
+func New(send SendFunc, opts Options) *Batcher {
+	if opts.MaxCount <= 0 {
+		opts.MaxCount = DefaultMaxCount
+	}
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = DefaultMaxBytes
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	if opts.MaxInFlight <= 0 {
+		opts.MaxInFlight = DefaultMaxInFlight
+	}
+	b := &Batcher{
+		opts:     opts,
+		send:     send,
+		inFlight: make(chan struct{}, opts.MaxInFlight),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	b.ctx, b.cancel = context.WithCancel(context.Background())
+	go b.run()
+	return b
+}
+
+func (b *Batcher) run() {
+	defer close(b.done)
+	ticker := time.NewTicker(b.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+			b.mu.Lock()
+			b.flush()
+			b.mu.Unlock()
+		}
+	}
+}
+
+// Write adds a copy of the record to the batch, sending the batch first if the record does not fit in it.
+func (b *Batcher) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return 0, os.ErrClosed
+	}
+	if len(b.records) > 0 && b.size+len(p) > b.opts.MaxBytes {
+		b.flush()
+	}
+	b.records = append(b.records, append([]byte(nil), p...))
+	b.size += len(p)
+	if len(b.records) >= b.opts.MaxCount || b.size >= b.opts.MaxBytes {
+		b.flush()
+	}
+	return len(p), nil
+}
+
+// flush sends the batch in the background, waiting while too many batches are being sent,
+// unless the batcher is cancelled by the deadline of [Batcher.Shutdown], then the batch fails.
+// The mutex must be held.
+func (b *Batcher) flush() {
+	if len(b.records) == 0 {
+		return
+	}
+	records := b.records
+	b.records, b.size = nil, 0
+	select {
+	case b.inFlight <- struct{}{}:
+	default:
+		select {
+		case b.inFlight <- struct{}{}:
+		case <-b.ctx.Done():
+			b.fail(len(records), b.ctx.Err())
+			return
+		}
+	}
+	b.sending.Add(1)
+	release := sync.OnceFunc(func() {
+		<-b.inFlight
+	})
+	go func() {
+		defer func() {
+			release()
+			b.sending.Done()
+		}()
+		if err := b.send(context.WithValue(b.ctx, releaseKey{}, release), records); err != nil {
+			failed := len(records)
+			var partial *PartialError
+			if errors.As(err, &partial) {
+				failed = partial.Failed
+			}
+			b.fail(failed, err)
+		}
+	}()
+}
+
+// fail records the error of a batch of which the given number of records failed to be sent.
+func (b *Batcher) fail(failed int, err error) {
+	b.failed.Add(uint64(failed))
+	if b.opts.ErrorHandler != nil {
+		b.opts.ErrorHandler(err)
+	}
+	b.errMu.Lock()
+	b.lastErr = err
+	b.errCount++
+	b.errMu.Unlock()
+}
+
+// wait waits until the batches being sent are done or ctx is done,
+// and returns the error of the last batch failed since the last wait, with the number of failed batches if several.
+func (b *Batcher) wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		b.sending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	b.errMu.Lock()
+	defer b.errMu.Unlock()
+	err, count := b.lastErr, b.errCount
+	b.lastErr, b.errCount = nil, 0
+	if count > 1 {
+		return fmt.Errorf("%d batches failed, the last one: %w", count, err)
+	}
+	return err
+}
+
+// Failed returns the number of records of the batches failed to be sent.
+func (b *Batcher) Failed() uint64 {
+	return b.failed.Load()
+}
+
+// Flush sends the batch and waits until all the batches are sent,
+// it returns the error of the last batch failed since the last flush, see [Options.ErrorHandler] for all of them.
+func (b *Batcher) Flush() error {
+	b.mu.Lock()
+	b.flush()
+	b.mu.Unlock()
+	return b.wait(context.Background())
+}
+
+// Shutdown stops accepting records, sends the batch and waits until all the batches are sent or ctx is done,
+// then the batches still being sent are cancelled, and so are the batches waiting for one of them to be done.
+func (b *Batcher) Shutdown(ctx context.Context) error {
+	// the writes and the ticker may hold the mutex while waiting for a batch to be done
+	defer context.AfterFunc(ctx, b.cancel)()
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	b.flush()
+	b.mu.Unlock()
+	close(b.stop)
+	<-b.done
+	err := b.wait(ctx)
+	b.cancel()
+	return err
+}
+
+// Close stops accepting records, sends the batch and waits until all the batches are sent.
+func (b *Batcher) Close() error {
+	return b.Shutdown(context.Background())
+}
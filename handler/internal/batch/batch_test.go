@@ -0,0 +1,226 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder records the batches sent.
+type recorder struct {
+	mu      sync.Mutex
+	batches []string
+}
+
+func (r *recorder) send(_ context.Context, records [][]byte) error {
+	var parts []string
+	for _, record := range records {
+		parts = append(parts, string(record))
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, strings.Join(parts, ","))
+	return nil
+}
+
+func (r *recorder) sent() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.batches...)
+}
+
+func TestBatcher(t *testing.T) {
+	t.Run("count and bytes", func(t *testing.T) {
+		r := new(recorder)
+		b := New(r.send, Options{MaxCount: 2, MaxBytes: 5, Interval: time.Hour})
+		for _, record := range []string{"a", "b", "c", "dddd", "eeeeee", "f"} {
+			buf := []byte(record)
+			_, err := b.Write(buf)
+			assert.NoError(t, err)
+			buf[0] = 'x'
+		}
+		assert.NoError(t, b.Flush())
+		assert.ElementsMatch(t, []string{"a,b", "c,dddd", "eeeeee", "f"}, r.sent())
+		assert.NoError(t, b.Close())
+		_, err := b.Write([]byte("g"))
+		assert.ErrorIs(t, err, os.ErrClosed)
+	})
+
+	t.Run("interval", func(t *testing.T) {
+		r := new(recorder)
+		b := New(r.send, Options{Interval: 10 * time.Millisecond})
+		_, err := b.Write([]byte("a"))
+		assert.NoError(t, err)
+		assert.Eventually(t, func() bool {
+			return len(r.sent()) == 1
+		}, time.Second, 5*time.Millisecond)
+		assert.NoError(t, b.Close())
+	})
+
+	t.Run("max in flight", func(t *testing.T) {
+		gate := make(chan struct{})
+		started := make(chan struct{}, 10)
+		b := New(func(ctx context.Context, records [][]byte) error {
+			started <- struct{}{}
+			<-gate
+			return nil
+		}, Options{MaxCount: 1, MaxInFlight: 2, Interval: time.Hour})
+		for i := 0; i < 2; i++ {
+			_, err := b.Write([]byte("a"))
+			assert.NoError(t, err)
+		}
+		<-started
+		<-started
+		written := make(chan struct{})
+		go func() {
+			defer close(written)
+			_, _ = b.Write([]byte("b"))
+		}()
+		select {
+		case <-written:
+			assert.FailNow(t, "write should block")
+		case <-time.After(20 * time.Millisecond):
+		}
+		close(gate)
+		<-written
+		assert.NoError(t, b.Close())
+	})
+
+	t.Run("release", func(t *testing.T) {
+		gate := make(chan struct{})
+		started := make(chan struct{}, 10)
+		b := New(func(ctx context.Context, records [][]byte) error {
+			Release(ctx)
+			Release(ctx)
+			started <- struct{}{}
+			<-gate
+			return nil
+		}, Options{MaxCount: 1, Interval: time.Hour})
+		for i := 0; i < 3; i++ {
+			_, err := b.Write([]byte("a"))
+			assert.NoError(t, err)
+		}
+		for i := 0; i < 3; i++ {
+			<-started
+		}
+		flushed := make(chan error, 1)
+		go func() {
+			flushed <- b.Flush()
+		}()
+		select {
+		case <-flushed:
+			assert.FailNow(t, "flush should wait for the released batches")
+		case <-time.After(20 * time.Millisecond):
+		}
+		close(gate)
+		assert.NoError(t, <-flushed)
+		assert.NoError(t, b.Close())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		var handled []error
+		var mu sync.Mutex
+		failure := errors.New("unavailable")
+		b := New(func(ctx context.Context, records [][]byte) error {
+			return failure
+		}, Options{ErrorHandler: func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			handled = append(handled, err)
+		}})
+		_, _ = b.Write([]byte("a"))
+		_, _ = b.Write([]byte("b"))
+		assert.ErrorIs(t, b.Flush(), failure)
+		assert.Equal(t, uint64(2), b.Failed())
+		assert.NoError(t, b.Flush())
+		assert.NoError(t, b.Close())
+		assert.Equal(t, []error{failure}, handled)
+	})
+
+	t.Run("errors bounded", func(t *testing.T) {
+		var count int
+		b := New(func(ctx context.Context, records [][]byte) error {
+			count++
+			return fmt.Errorf("unavailable %d", count)
+		}, Options{MaxCount: 1})
+		for i := 0; i < 1000; i++ {
+			_, _ = b.Write([]byte("a"))
+		}
+		assert.EqualError(t, b.Flush(), "1000 batches failed, the last one: unavailable 1000")
+		assert.Equal(t, uint64(1000), b.Failed())
+		b.errMu.Lock()
+		assert.Zero(t, b.errCount)
+		b.errMu.Unlock()
+		assert.NoError(t, b.Close())
+	})
+
+	t.Run("partial errors", func(t *testing.T) {
+		failure := errors.New("rejected")
+		b := New(func(ctx context.Context, records [][]byte) error {
+			return &PartialError{Failed: 1, Err: failure}
+		}, Options{})
+		_, _ = b.Write([]byte("a"))
+		_, _ = b.Write([]byte("b"))
+		assert.ErrorIs(t, b.Flush(), failure)
+		assert.Equal(t, uint64(1), b.Failed())
+		assert.NoError(t, b.Close())
+	})
+
+	t.Run("shutdown deadline", func(t *testing.T) {
+		b := New(func(ctx context.Context, records [][]byte) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}, Options{})
+		_, _ = b.Write([]byte("a"))
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, b.Shutdown(ctx), context.DeadlineExceeded)
+		assert.Eventually(t, func() bool {
+			return b.Failed() == 1
+		}, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("shutdown deadline with the batches in flight", func(t *testing.T) {
+		b := New(func(ctx context.Context, records [][]byte) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}, Options{MaxCount: 1})
+		_, _ = b.Write([]byte("a"))
+		written := make(chan error, 1)
+		go func() {
+			_, err := b.Write([]byte("b"))
+			written <- err
+		}()
+		// the second write holds the mutex while waiting for the first batch
+		assert.Eventually(t, func() bool {
+			if b.mu.TryLock() {
+				b.mu.Unlock()
+				return false
+			}
+			return true
+		}, time.Second, time.Millisecond)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		shutdown := make(chan error, 1)
+		go func() {
+			shutdown <- b.Shutdown(ctx)
+		}()
+		select {
+		case err := <-shutdown:
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+		case <-time.After(time.Second):
+			assert.FailNow(t, "shutdown ignores the deadline")
+		}
+		assert.NoError(t, <-written)
+		assert.Eventually(t, func() bool {
+			return b.Failed() == 2
+		}, time.Second, 5*time.Millisecond)
+	})
+}
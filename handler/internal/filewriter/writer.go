@@ -0,0 +1,286 @@
+// Package filewriter provides a file writer keeping its file descriptor open,
+// which is shared by the file based handlers.
+package filewriter
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gopi-frame/logger/handler/internal/flock"
+)
+
+// DefaultFlushInterval is the flush interval of a buffered writer when none is given.
+const DefaultFlushInterval = time.Second
+
+// Options configures a [Writer].
+type Options struct {
+	// BufferSize is the size of the write buffer, writes are unbuffered if it is not positive.
+	BufferSize int
+	// FlushInterval is the interval to flush the buffer, [DefaultFlushInterval] is used if it is not positive.
+	FlushInterval time.Duration
+	// FsyncEvery is the number of writes after which the file is fsynced, never if it is not positive.
+	FsyncEvery int
+	// FsyncInterval is the interval to fsync the file, never if it is not positive.
+	FsyncInterval time.Duration
+	// Lock holds an exclusive advisory lock on the file during every write to it, direct or flushed from the buffer,
+	// so the writes of the processes sharing the file are not interleaved.
+	// A buffered write is never split between two writes to the file.
+	Lock bool
+}
+
+// Writer is a file writer keeping its file descriptor open between writes.
+//
+// The file is opened on the first write, and reopened transparently
+// when it has been moved or deleted underneath, detected by comparing the file identity (inode).
+type Writer struct {
+	mu       sync.Mutex
+	filename string
+	mode     os.FileMode
+	opts     Options
+	file     *os.File
+	buf      *bufio.Writer
+	writes   int
+	closed   bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// New creates a new writer for the given file, the file is opened on the first write.
+func New(filename string, mode os.FileMode, opts Options) *Writer {
+	w := &Writer{
+		filename: filename,
+		mode:     mode,
+		opts:     opts,
+	}
+	if opts.BufferSize > 0 && opts.FlushInterval <= 0 {
+		w.opts.FlushInterval = DefaultFlushInterval
+	}
+	if w.opts.BufferSize > 0 || w.opts.FsyncInterval > 0 {
+		w.stop = make(chan struct{})
+		w.done = make(chan struct{})
+		go w.run()
+	}
+	return w
+}
+
+func (w *Writer) run() {
+	defer close(w.done)
+	var flush, fsync <-chan time.Time
+	if w.opts.BufferSize > 0 {
+		ticker := time.NewTicker(w.opts.FlushInterval)
+		defer ticker.Stop()
+		flush = ticker.C
+	}
+	if w.opts.FsyncInterval > 0 {
+		ticker := time.NewTicker(w.opts.FsyncInterval)
+		defer ticker.Stop()
+		fsync = ticker.C
+	}
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-flush:
+			_ = w.Flush()
+		case <-fsync:
+			_ = w.Sync()
+		}
+	}
+}
+
+// Write writes p to the file, opening or reopening it if needed.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	if err := w.open(); err != nil {
+		return 0, err
+	}
+	var n int
+	var err error
+	if w.buf != nil {
+		if w.opts.Lock && w.buf.Buffered() > 0 && len(p) > w.buf.Available() {
+			// flush first, a write larger than the buffer is then written in one piece
+			if err := w.buf.Flush(); err != nil {
+				return 0, err
+			}
+		}
+		n, err = w.buf.Write(p)
+	} else {
+		n, err = w.output().Write(p)
+	}
+	if err != nil {
+		return n, err
+	}
+	w.writes++
+	if w.opts.FsyncEvery > 0 && w.writes%w.opts.FsyncEvery == 0 {
+		err = w.sync()
+	}
+	return n, err
+}
+
+// open opens the file if it is not open or has been moved or deleted.
+func (w *Writer) open() error {
+	if w.file != nil {
+		if !w.moved() {
+			return nil
+		}
+		if err := w.closeFile(); err != nil {
+			return err
+		}
+	}
+	file, err := os.OpenFile(w.filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, w.mode)
+	if err != nil {
+		return err
+	}
+	w.file = file
+	if w.opts.BufferSize > 0 {
+		w.buf = bufio.NewWriterSize(w.output(), w.opts.BufferSize)
+	}
+	return nil
+}
+
+// output returns the writer the data is written to the file through.
+func (w *Writer) output() io.Writer {
+	if w.opts.Lock {
+		return lockedFile{w}
+	}
+	return w.file
+}
+
+// lockedFile writes to the file of the writer holding an advisory lock on it,
+// the writer mutex must be held.
+type lockedFile struct {
+	w *Writer
+}
+
+func (f lockedFile) Write(p []byte) (int, error) {
+	w := f.w
+	for {
+		if err := flock.Lock(w.file); err != nil {
+			return 0, err
+		}
+		// another process may have moved the file before the lock was acquired,
+		// the data is written to the file by its name
+		if !w.moved() {
+			break
+		}
+		_ = flock.Unlock(w.file)
+		_ = w.file.Close()
+		file, err := os.OpenFile(w.filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, w.mode)
+		if err != nil {
+			return 0, err
+		}
+		w.file = file
+	}
+	defer func() {
+		_ = flock.Unlock(w.file)
+	}()
+	return w.file.Write(p)
+}
+
+// moved reports whether the file at filename is no longer the open file.
+func (w *Writer) moved() bool {
+	current, err := os.Stat(w.filename)
+	if err != nil {
+		return true
+	}
+	opened, err := w.file.Stat()
+	if err != nil {
+		return true
+	}
+	return !os.SameFile(current, opened)
+}
+
+func (w *Writer) flush() error {
+	if w.buf == nil {
+		return nil
+	}
+	return w.buf.Flush()
+}
+
+func (w *Writer) sync() error {
+	if w.file == nil {
+		return nil
+	}
+	if err := w.flush(); err != nil {
+		return err
+	}
+	return w.file.Sync()
+}
+
+// closeFile flushes and closes the open file, it is opened again on the next write.
+func (w *Writer) closeFile() error {
+	if w.file == nil {
+		return nil
+	}
+	err := errors.Join(w.flush(), w.file.Close())
+	w.file = nil
+	w.buf = nil
+	return err
+}
+
+// Filename returns the name of the file written to.
+func (w *Writer) Filename() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.filename
+}
+
+// SetFilename closes the open file and writes to the given file from the next write.
+func (w *Writer) SetFilename(filename string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if filename == w.filename {
+		return nil
+	}
+	err := w.closeFile()
+	w.filename = filename
+	return err
+}
+
+// Flush writes the buffered data to the file.
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.flush()
+}
+
+// Sync writes the buffered data to the file and commits it to the disk.
+func (w *Writer) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.sync()
+}
+
+// Reopen closes the open file, it is opened again by its name on the next write.
+func (w *Writer) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.closeFile()
+}
+
+// Close flushes and closes the file, and stops the background flushing.
+// Writes after Close fail with [os.ErrClosed].
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	err := w.closeFile()
+	w.mu.Unlock()
+	if w.stop != nil {
+		close(w.stop)
+		<-w.done
+	}
+	return err
+}
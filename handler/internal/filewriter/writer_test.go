@@ -0,0 +1,103 @@
+package filewriter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readFile(t *testing.T, filename string) string {
+	content, err := os.ReadFile(filename)
+	if !assert.NoError(t, err) {
+		assert.FailNow(t, err.Error())
+	}
+	return string(content)
+}
+
+func write(t *testing.T, w *Writer, s string) {
+	if _, err := w.Write([]byte(s)); !assert.NoError(t, err) {
+		assert.FailNow(t, err.Error())
+	}
+}
+
+func TestWriter(t *testing.T) {
+	t.Run("unbuffered", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "test.log")
+		w := New(filename, 0644, Options{})
+		write(t, w, "a")
+		write(t, w, "b")
+		assert.Equal(t, "ab", readFile(t, filename))
+		assert.NoError(t, w.Close())
+		_, err := w.Write([]byte("c"))
+		assert.ErrorIs(t, err, os.ErrClosed)
+		assert.NoError(t, w.Close())
+	})
+
+	t.Run("buffered", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "test.log")
+		w := New(filename, 0644, Options{BufferSize: 1024, FlushInterval: 20 * time.Millisecond})
+		defer func() {
+			_ = w.Close()
+		}()
+		write(t, w, "a")
+		assert.Equal(t, "", readFile(t, filename))
+		assert.Eventually(t, func() bool {
+			return readFile(t, filename) == "a"
+		}, time.Second, 10*time.Millisecond)
+		write(t, w, "b")
+		assert.NoError(t, w.Sync())
+		assert.Equal(t, "ab", readFile(t, filename))
+	})
+
+	t.Run("fsync every", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "test.log")
+		w := New(filename, 0644, Options{BufferSize: 1024, FlushInterval: time.Hour, FsyncEvery: 2})
+		defer func() {
+			_ = w.Close()
+		}()
+		write(t, w, "a")
+		assert.Equal(t, "", readFile(t, filename))
+		write(t, w, "b")
+		assert.Equal(t, "ab", readFile(t, filename))
+	})
+
+	t.Run("moved", func(t *testing.T) {
+		dir := t.TempDir()
+		filename := filepath.Join(dir, "test.log")
+		w := New(filename, 0644, Options{})
+		defer func() {
+			_ = w.Close()
+		}()
+		write(t, w, "a")
+		if err := os.Rename(filename, filename+".1"); err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		write(t, w, "b")
+		assert.Equal(t, "a", readFile(t, filename+".1"))
+		assert.Equal(t, "b", readFile(t, filename))
+
+		if err := os.Remove(filename); err != nil {
+			assert.FailNow(t, err.Error())
+		}
+		write(t, w, "c")
+		assert.Equal(t, "c", readFile(t, filename))
+	})
+
+	t.Run("set filename", func(t *testing.T) {
+		dir := t.TempDir()
+		w := New(filepath.Join(dir, "a.log"), 0644, Options{BufferSize: 1024})
+		defer func() {
+			_ = w.Close()
+		}()
+		write(t, w, "a")
+		assert.NoError(t, w.SetFilename(filepath.Join(dir, "b.log")))
+		assert.Equal(t, filepath.Join(dir, "b.log"), w.Filename())
+		assert.Equal(t, "a", readFile(t, filepath.Join(dir, "a.log")))
+		write(t, w, "b")
+		assert.NoError(t, w.Reopen())
+		assert.Equal(t, "b", readFile(t, filepath.Join(dir, "b.log")))
+	})
+}
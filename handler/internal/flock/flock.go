@@ -0,0 +1,67 @@
+// Package flock provides the advisory file locks coordinating the processes writing to the same files.
+//
+// The locks are flock(2) locks on Unix systems, on other systems they do nothing.
+package flock
+
+import (
+	"os"
+	"sync"
+)
+
+// Mutex is an exclusive lock shared by the goroutines of a process, through an in-process mutex,
+// and by the processes opening the same lock file, through an advisory lock on it.
+//
+// The lock file is created on the first lock and left in place, removing it would let
+// two processes hold locks on different files of the same name.
+type Mutex struct {
+	mu       sync.Mutex
+	filename string
+	file     *os.File
+	closed   bool
+}
+
+// New creates a new mutex on the given lock file.
+func New(filename string) *Mutex {
+	return &Mutex{filename: filename}
+}
+
+// Lock locks the mutex, blocking until it is acquired.
+func (m *Mutex) Lock() error {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return os.ErrClosed
+	}
+	if m.file == nil {
+		file, err := os.OpenFile(m.filename, os.O_CREATE|os.O_RDWR, 0644)
+		if err != nil {
+			m.mu.Unlock()
+			return err
+		}
+		m.file = file
+	}
+	if err := Lock(m.file); err != nil {
+		m.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Unlock unlocks the mutex.
+func (m *Mutex) Unlock() error {
+	defer m.mu.Unlock()
+	return Unlock(m.file)
+}
+
+// Close closes the lock file, locks after Close fail with [os.ErrClosed].
+func (m *Mutex) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+	if m.file == nil {
+		return nil
+	}
+	err := m.file.Close()
+	m.file = nil
+	return err
+}
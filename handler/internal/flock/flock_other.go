@@ -0,0 +1,15 @@
+//go:build !unix
+
+package flock
+
+import "os"
+
+// Lock does nothing, advisory locks are not supported on this system.
+func Lock(*os.File) error {
+	return nil
+}
+
+// Unlock does nothing, advisory locks are not supported on this system.
+func Unlock(*os.File) error {
+	return nil
+}
@@ -0,0 +1,40 @@
+package flock
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMutex(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), ".app.log.lock")
+	// two mutexes on the same lock file behave like two processes
+	a, b := New(filename), New(filename)
+	// holders counts the goroutines in the critical section, atomically for the race detector
+	var holders, counter atomic.Int32
+	var wg sync.WaitGroup
+	for _, m := range []*Mutex{a, b, a, b} {
+		wg.Add(1)
+		go func(m *Mutex) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				if !assert.NoError(t, m.Lock()) {
+					return
+				}
+				assert.True(t, holders.CompareAndSwap(0, 1))
+				counter.Add(1)
+				holders.Store(0)
+				assert.NoError(t, m.Unlock())
+			}
+		}(m)
+	}
+	wg.Wait()
+	assert.Equal(t, int32(400), counter.Load())
+	assert.NoError(t, a.Close())
+	assert.NoError(t, b.Close())
+	assert.ErrorIs(t, a.Lock(), os.ErrClosed)
+}
@@ -0,0 +1,79 @@
+// Package handlertest provides the helpers shared by the tests of the handlers.
+package handlertest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// New returns the handler created by create from the config, the test fails now on an error.
+func New[H any](t *testing.T, create func(config map[string]any) (H, error), config map[string]any) H {
+	t.Helper()
+	h, err := create(config)
+	if !assert.NoError(t, err) {
+		assert.FailNow(t, err.Error())
+	}
+	return h
+}
+
+// Write writes the records to w one by one, the test fails now on an error and on a short write.
+func Write(t *testing.T, w io.Writer, records ...string) {
+	t.Helper()
+	for _, record := range records {
+		n, err := w.Write([]byte(record))
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		assert.Equal(t, len(record), n)
+	}
+}
+
+// Receive receives n values from the channel, the test fails now if they are not received within a second.
+func Receive[T any](t *testing.T, values <-chan T, n int) []T {
+	t.Helper()
+	received := make([]T, 0, n)
+	timeout := time.After(time.Second)
+	for len(received) < n {
+		select {
+		case v := <-values:
+			received = append(received, v)
+		case <-timeout:
+			assert.FailNow(t, "timeout")
+		}
+	}
+	return received
+}
+
+// Serve starts a server, closed when the test is done.
+func Serve(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// Recorder records the values received by a stand-in of a server, it is safe for concurrent use.
+type Recorder[T any] struct {
+	mu     sync.Mutex
+	values []T
+}
+
+// Add records the value and returns the number of the values recorded.
+func (r *Recorder[T]) Add(value T) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values = append(r.values, value)
+	return len(r.values)
+}
+
+// All returns the values recorded.
+func (r *Recorder[T]) All() []T {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]T(nil), r.values...)
+}
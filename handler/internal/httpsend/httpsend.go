@@ -0,0 +1,120 @@
+// Package httpsend sends the requests of the HTTP based handlers, retrying the failed ones.
+package httpsend
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gopi-frame/logger/handler/internal/backoff"
+)
+
+// DefaultMaxRetries is the number of retries when none is given.
+const DefaultMaxRetries = 3
+
+// StatusError is the error of a request answered with an unexpected status.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected response status %d: %s", e.StatusCode, e.Body)
+}
+
+// Retryable reports whether a request answered with the status may succeed later, on 429 and 5xx.
+func Retryable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// NewClient creates a client with its own transport cloned from [http.DefaultTransport],
+// using the given timeout of a request and TLS configuration, which may be nil.
+func NewClient(timeout time.Duration, tlsConfig *tls.Config) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Transport: transport, Timeout: timeout}
+}
+
+// Sender sends requests with retries.
+type Sender struct {
+	// Client sends the requests, [http.DefaultClient] if it is nil.
+	Client *http.Client
+	// MaxRetries is the number of retries of a failed request, [DefaultMaxRetries] if it is 0, none if it is negative.
+	MaxRetries int
+	// Backoff is the backoff between the retries, a Retry-After header takes precedence up to its max delay.
+	Backoff backoff.Backoff
+}
+
+// Do sends the request built by newRequest, which is called again for every retry,
+// until a response with a 2xx status, whose body is returned.
+// The request is retried on network errors and responses with a retryable status, see [Retryable],
+// other statuses fail with a [StatusError].
+func (s *Sender) Do(ctx context.Context, newRequest func(ctx context.Context) (*http.Request, error)) ([]byte, error) {
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	retries := s.Retries()
+	for attempt := 0; ; attempt++ {
+		body, retryAfter, err := s.do(ctx, client, newRequest)
+		if err == nil {
+			return body, nil
+		}
+		if retryAfter < 0 || attempt >= retries || ctx.Err() != nil {
+			return nil, err
+		}
+		delay := s.Backoff.Delay(attempt)
+		if retryAfter > 0 {
+			// the server may ask for any delay, it is capped like the backoff
+			delay = min(retryAfter, s.Backoff.MaxDelay())
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, err
+		}
+	}
+}
+
+// Retries returns the number of retries of a failed request.
+func (s *Sender) Retries() int {
+	if s.MaxRetries == 0 {
+		return DefaultMaxRetries
+	}
+	return max(s.MaxRetries, 0)
+}
+
+// do sends the request once, retryAfter is negative if it must not be retried,
+// and positive if the response asked for a delay.
+func (s *Sender) do(ctx context.Context, client *http.Client, newRequest func(ctx context.Context) (*http.Request, error)) (body []byte, retryAfter time.Duration, err error) {
+	req, err := newRequest(ctx)
+	if err != nil {
+		return nil, -1, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return body, 0, nil
+	}
+	err = &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+	if !Retryable(resp.StatusCode) {
+		return nil, -1, err
+	}
+	if seconds, parseErr := strconv.Atoi(resp.Header.Get("Retry-After")); parseErr == nil && seconds > 0 {
+		return nil, time.Duration(seconds) * time.Second, err
+	}
+	return nil, 0, err
+}
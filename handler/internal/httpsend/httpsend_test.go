@@ -0,0 +1,75 @@
+package httpsend
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gopi-frame/logger/handler/internal/backoff"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSender_Do(t *testing.T) {
+	var requests atomic.Int32
+	statuses := []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK}
+	retryAfter := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if retryAfter != "" {
+			w.Header().Set("Retry-After", retryAfter)
+		}
+		w.WriteHeader(statuses[requests.Add(1)-1])
+		_, _ = w.Write([]byte("body"))
+	}))
+	defer server.Close()
+	newRequest := func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodPost, server.URL, nil)
+	}
+	sender := &Sender{Backoff: backoff.Backoff{Initial: time.Millisecond}}
+	body, err := sender.Do(context.Background(), newRequest)
+	assert.NoError(t, err)
+	assert.Equal(t, "body", string(body))
+	assert.Equal(t, int32(3), requests.Load())
+
+	// not retried
+	requests.Store(0)
+	statuses = []int{http.StatusBadRequest}
+	_, err = sender.Do(context.Background(), newRequest)
+	var statusErr *StatusError
+	if assert.ErrorAs(t, err, &statusErr) {
+		assert.Equal(t, http.StatusBadRequest, statusErr.StatusCode)
+		assert.Equal(t, "body", statusErr.Body)
+	}
+	assert.Equal(t, int32(1), requests.Load())
+
+	// retries exhausted
+	requests.Store(0)
+	statuses = []int{500, 500, 500}
+	sender.MaxRetries = 2
+	_, err = sender.Do(context.Background(), newRequest)
+	assert.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, int32(3), requests.Load())
+
+	// cancelled while waiting
+	requests.Store(0)
+	statuses = []int{500, 500, 500}
+	sender.Backoff = backoff.Backoff{Initial: time.Hour}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = sender.Do(ctx, newRequest)
+	assert.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, int32(1), requests.Load())
+
+	// the Retry-After of the server is capped at the max delay
+	requests.Store(0)
+	statuses = []int{http.StatusServiceUnavailable, http.StatusOK}
+	retryAfter = "3600"
+	sender.Backoff = backoff.Backoff{Max: 10 * time.Millisecond}
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = sender.Do(ctx, newRequest)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), requests.Load())
+}
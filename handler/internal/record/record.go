@@ -0,0 +1,75 @@
+// Package record reads the fields of the records written to the handlers,
+// which are encoded by the drivers as JSON or as key=value pairs.
+package record
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/gopi-frame/logger"
+)
+
+// Field reads the value of a field of a record encoded as JSON, e.g. {"level":"info"},
+// or as key=value pairs, e.g. level=INFO. A JSON string is unquoted, other JSON values are returned as is,
+// objects and arrays are not supported.
+func Field(record []byte, key string) (string, bool) {
+	value, _, ok := field(record, key)
+	return value, ok
+}
+
+// field reads the value of a field, the second result reports whether it is a JSON string or a key=value pair.
+func field(record []byte, key string) (string, bool, bool) {
+	if i := bytes.Index(record, []byte(`"`+key+`":`)); i >= 0 {
+		value := bytes.TrimLeft(record[i+len(key)+3:], " ")
+		if len(value) == 0 {
+			return "", false, false
+		}
+		if value[0] != '"' {
+			end := bytes.IndexAny(value, ",}] \n")
+			if end < 0 {
+				end = len(value)
+			}
+			if end == 0 || value[0] == '{' || value[0] == '[' {
+				return "", false, false
+			}
+			return string(value[:end]), false, true
+		}
+		escaped := false
+		for end := 1; end < len(value); end++ {
+			switch {
+			case escaped:
+				escaped = false
+			case value[end] == '\\':
+				escaped = true
+			case value[end] == '"':
+				var s string
+				if err := json.Unmarshal(value[:end+1], &s); err != nil {
+					return "", false, false
+				}
+				return s, true, true
+			}
+		}
+		return "", false, false
+	}
+	if i := bytes.Index(record, []byte(key+"=")); i >= 0 && (i == 0 || record[i-1] == ' ') {
+		value := record[i+len(key)+1:]
+		if end := bytes.IndexAny(value, " \n"); end >= 0 {
+			value = value[:end]
+		}
+		return string(bytes.Trim(value, `"`)), true, true
+	}
+	return "", false, false
+}
+
+// Level reads the level of a record, see [Field], a JSON value which is not a string is not a level.
+func Level(record []byte, key string) (logger.Level, bool) {
+	value, text, ok := field(record, key)
+	if !ok || !text {
+		return 0, false
+	}
+	var level logger.Level
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		return 0, false
+	}
+	return level, true
+}
@@ -0,0 +1,42 @@
+package record
+
+import (
+	"testing"
+
+	"github.com/gopi-frame/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestField(t *testing.T) {
+	cases := []struct {
+		record string
+		value  string
+		ok     bool
+	}{
+		{`{"level":"info","app":"api"}`, "api", true},
+		{`{"app": "say \"hi\"\n"}`, "say \"hi\"\n", true},
+		{`{"app":42,"level":"info"}`, "42", true},
+		{`{"app":true}`, "true", true},
+		{`{"app":{"name":"api"}}`, "", false},
+		{`{"app":"unterminated`, "", false},
+		{`level=INFO app=api msg=hi`, "api", true},
+		{`level=INFO app="api" msg=hi`, "api", true},
+		{`level=INFO webapp=api`, "", false},
+		{`{"level":"info"}`, "", false},
+	}
+	for _, c := range cases {
+		value, ok := Field([]byte(c.record), "app")
+		assert.Equal(t, c.ok, ok, c.record)
+		assert.Equal(t, c.value, value, c.record)
+	}
+}
+
+func TestLevel(t *testing.T) {
+	level, ok := Level([]byte(`{"level":"warn"}`), "level")
+	assert.True(t, ok)
+	assert.Equal(t, logger.LevelWarn, level)
+	_, ok = Level([]byte(`level=LOUD`), "level")
+	assert.False(t, ok)
+	_, ok = Level([]byte(`{"level":1}`), "level")
+	assert.False(t, ok)
+}
@@ -0,0 +1,179 @@
+// Package strftime formats and parses the strftime-style time patterns of the handlers,
+// e.g. the time part of the file names of the daily handler or the index names of the Elasticsearch handler.
+package strftime
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Pattern is a strftime-style pattern.
+//
+// Supported tokens:
+//   - %Y year with century, e.g. 2026
+//   - %y year without century, e.g. 26
+//   - %m month, 01-12
+//   - %d day of the month, 01-31
+//   - %j day of the year, 001-366
+//   - %H hour, 00-23
+//   - %M minute, 00-59
+//   - %S second, 00-59
+//   - %% a literal %
+type Pattern struct {
+	source string
+	tokens []string
+}
+
+// tokenDigits is the number of digits of each supported token.
+var tokenDigits = map[byte]int{
+	'Y': 4, 'y': 2, 'm': 2, 'd': 2, 'j': 3, 'H': 2, 'M': 2, 'S': 2,
+}
+
+// Parse parses a strftime-style pattern, a token is kept as "%x" and a literal as itself.
+// The error tells why the pattern is invalid, e.g. "trailing %".
+func Parse(source string) (*Pattern, error) {
+	p := &Pattern{source: source}
+	var literal strings.Builder
+	for i := 0; i < len(source); i++ {
+		if source[i] != '%' {
+			literal.WriteByte(source[i])
+			continue
+		}
+		if i+1 == len(source) {
+			return nil, errors.New("trailing %")
+		}
+		i++
+		if source[i] == '%' {
+			literal.WriteByte('%')
+			continue
+		}
+		if _, ok := tokenDigits[source[i]]; !ok {
+			return nil, errors.New("unsupported token %" + string(source[i]))
+		}
+		if literal.Len() > 0 {
+			p.tokens = append(p.tokens, literal.String())
+			literal.Reset()
+		}
+		p.tokens = append(p.tokens, source[i-1:i+1])
+	}
+	if literal.Len() > 0 {
+		p.tokens = append(p.tokens, literal.String())
+	}
+	return p, nil
+}
+
+func isToken(token string) bool {
+	return len(token) == 2 && token[0] == '%'
+}
+
+// String returns the source of the pattern.
+func (p *Pattern) String() string {
+	return p.source
+}
+
+// Format formats t by the pattern.
+func (p *Pattern) Format(t time.Time) string {
+	var b strings.Builder
+	for _, token := range p.tokens {
+		if !isToken(token) {
+			b.WriteString(token)
+			continue
+		}
+		var value int
+		switch token[1] {
+		case 'Y':
+			value = t.Year()
+		case 'y':
+			value = t.Year() % 100
+		case 'm':
+			value = int(t.Month())
+		case 'd':
+			value = t.Day()
+		case 'j':
+			value = t.YearDay()
+		case 'H':
+			value = t.Hour()
+		case 'M':
+			value = t.Minute()
+		case 'S':
+			value = t.Second()
+		}
+		s := strconv.Itoa(value)
+		b.WriteString(strings.Repeat("0", tokenDigits[token[1]]-len(s)))
+		b.WriteString(s)
+	}
+	return b.String()
+}
+
+// Regexp returns the expression matching the formatted times, with a group for each token.
+func (p *Pattern) Regexp() string {
+	var b strings.Builder
+	for _, token := range p.tokens {
+		if isToken(token) {
+			b.WriteString(`(\d{` + strconv.Itoa(tokenDigits[token[1]]) + `})`)
+		} else {
+			b.WriteString(regexp.QuoteMeta(token))
+		}
+	}
+	return b.String()
+}
+
+// Time parses the time from the groups matched by [Pattern.Regexp] in the given location.
+// Missing fields are the start of their unit, e.g. the first day of the month.
+func (p *Pattern) Time(groups []string, loc *time.Location) time.Time {
+	year, month, day, yearDay, hour, minute, second := 0, 1, 1, 0, 0, 0, 0
+	i := 0
+	for _, token := range p.tokens {
+		if !isToken(token) {
+			continue
+		}
+		value, _ := strconv.Atoi(groups[i])
+		i++
+		switch token[1] {
+		case 'Y':
+			year = value
+		case 'y':
+			year = 2000 + value
+		case 'm':
+			month = value
+		case 'd':
+			day = value
+		case 'j':
+			yearDay = value
+		case 'H':
+			hour = value
+		case 'M':
+			minute = value
+		case 'S':
+			second = value
+		}
+	}
+	if yearDay > 0 {
+		return time.Date(year, 1, yearDay, hour, minute, second, 0, loc)
+	}
+	return time.Date(year, time.Month(month), day, hour, minute, second, 0, loc)
+}
+
+// Groups returns the number of groups of [Pattern.Regexp], which is the number of tokens.
+func (p *Pattern) Groups() int {
+	n := 0
+	for _, token := range p.tokens {
+		if isToken(token) {
+			n++
+		}
+	}
+	return n
+}
+
+// Has reports whether the pattern contains any of the tokens, given by their letters, e.g. "Yy".
+func (p *Pattern) Has(letters string) bool {
+	for _, token := range p.tokens {
+		if isToken(token) && strings.IndexByte(letters, token[1]) >= 0 {
+			return true
+		}
+	}
+	return false
+}
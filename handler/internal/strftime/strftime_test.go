@@ -0,0 +1,37 @@
+package strftime
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPattern(t *testing.T) {
+	p, err := Parse("%Y%m%d-%H%M%S.%j.%y%%")
+	if !assert.NoError(t, err) {
+		assert.FailNow(t, err.Error())
+	}
+	tm := time.Date(2026, 2, 3, 4, 5, 6, 0, time.UTC)
+	assert.Equal(t, "20260203-040506.034.26%", p.Format(tm))
+	assert.Equal(t, 8, p.Groups())
+	assert.True(t, p.Has("Yy"))
+	matches := regexp.MustCompile("^" + p.Regexp() + "$").FindStringSubmatch(p.Format(tm))
+	if !assert.Len(t, matches, 9) {
+		assert.FailNow(t, "pattern does not match")
+	}
+	assert.Equal(t, tm, p.Time(matches[1:], time.UTC))
+
+	p, err = Parse("logs-100%%")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "logs-100%", p.Format(tm))
+		assert.Zero(t, p.Groups())
+		assert.False(t, p.Has("Yy"))
+	}
+
+	_, err = Parse("logs-%")
+	assert.EqualError(t, err, "trailing %")
+	_, err = Parse("logs-%W")
+	assert.EqualError(t, err, "unsupported token %W")
+}
@@ -0,0 +1,36 @@
+// Package symlink maintains the symlinks to the current files of the rotating handlers.
+package symlink
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// Update points the symlink at name to target atomically, replacing the existing file or symlink.
+// The target is made relative to the directory of the symlink,
+// so that the symlink keeps working when the directory is moved or mounted elsewhere.
+func Update(name, target string) error {
+	if absName, err := filepath.Abs(name); err == nil {
+		if absTarget, err := filepath.Abs(target); err == nil {
+			if rel, err := filepath.Rel(filepath.Dir(absName), absTarget); err == nil {
+				target = rel
+			}
+		}
+	}
+	if current, err := os.Readlink(name); err == nil && current == target {
+		return nil
+	}
+	// the symlink is created under a temporary name and renamed over the existing one,
+	// readers never see the symlink missing
+	tmp := name + "." + strconv.Itoa(os.Getpid()) + "." + strconv.FormatInt(time.Now().UnixNano(), 36) + ".tmp"
+	if err := os.Symlink(target, tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, name); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
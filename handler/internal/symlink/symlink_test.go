@@ -0,0 +1,31 @@
+package symlink
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdate(t *testing.T) {
+	dir := t.TempDir()
+	link := filepath.Join(dir, "app.log")
+	for _, name := range []string{"app.1.log", "app.2.log", "app.2.log"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		if err := Update(link, filepath.Join(dir, name)); !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		target, err := os.Readlink(link)
+		assert.NoError(t, err)
+		assert.Equal(t, name, target)
+		content, err := os.ReadFile(link)
+		assert.NoError(t, err)
+		assert.Equal(t, name, string(content))
+	}
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 3)
+}
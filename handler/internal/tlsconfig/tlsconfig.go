@@ -0,0 +1,60 @@
+// Package tlsconfig builds the TLS configurations of the network handlers from their options.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+)
+
+// Config is the TLS part of the configuration of a handler, e.g.
+//
+//	"tls": map[string]any{
+//		"enabled": true,
+//		"caFile":  "/etc/ssl/aggregator-ca.pem",
+//	}
+type Config struct {
+	// Enabled enables TLS, it is implied by the other fields.
+	Enabled bool
+	// CAFile is the PEM file of the certificate authorities verifying the server,
+	// the system ones are used if it is empty.
+	CAFile string
+	// CertFile and KeyFile are the PEM files of the client certificate and its key, for mutual TLS.
+	CertFile string
+	KeyFile  string
+	// ServerName is the name verified in the server certificate, the host of the address by default.
+	ServerName string
+	// InsecureSkipVerify disables the verification of the server certificate, for testing only.
+	InsecureSkipVerify bool
+}
+
+// Build returns the TLS configuration, or nil if TLS is not enabled.
+func (c Config) Build() (*tls.Config, error) {
+	if !c.Enabled && c.CAFile == "" && c.CertFile == "" && c.ServerName == "" && !c.InsecureSkipVerify {
+		return nil, nil
+	}
+	config := &tls.Config{
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no certificate found in " + c.CAFile)
+		}
+	}
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
@@ -0,0 +1,38 @@
+package loki
+
+import (
+	"fmt"
+
+	. "github.com/gopi-frame/contract/exception"
+	"github.com/gopi-frame/exception"
+)
+
+type InvalidURLException struct {
+	Throwable
+}
+
+func NewInvalidURLException(url string) *InvalidURLException {
+	return &InvalidURLException{
+		Throwable: exception.New(fmt.Sprintf("invalid url [%s]", url)),
+	}
+}
+
+type InvalidEncodingException struct {
+	Throwable
+}
+
+func NewInvalidEncodingException(encoding string) *InvalidEncodingException {
+	return &InvalidEncodingException{
+		Throwable: exception.New(fmt.Sprintf("invalid encoding [%s]", encoding)),
+	}
+}
+
+type LabelsMissingException struct {
+	Throwable
+}
+
+func NewLabelsMissingException() *LabelsMissingException {
+	return &LabelsMissingException{
+		Throwable: exception.New("static labels are missing, Loki rejects the streams without labels"),
+	}
+}
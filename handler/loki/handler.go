@@ -0,0 +1,312 @@
+package loki
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/gopi-frame/env"
+	"github.com/gopi-frame/logger"
+	"github.com/gopi-frame/logger/handler/internal/backoff"
+	"github.com/gopi-frame/logger/handler/internal/batch"
+	"github.com/gopi-frame/logger/handler/internal/httpsend"
+	"github.com/gopi-frame/logger/handler/internal/record"
+	"github.com/gopi-frame/logger/handler/internal/tlsconfig"
+	"github.com/klauspost/compress/snappy"
+)
+
+var handlerName = "loki"
+
+//goland:noinspection GoBoolExpressions
+func init() {
+	if handlerName != "" {
+		logger.RegisterHandler(handlerName, func(config map[string]any) (io.WriteCloser, error) {
+			return NewLokiHandlerFromConfig(config)
+		})
+		logger.RegisterHandlerValidator(handlerName, func(config map[string]any) error {
+			_, _, err := parseConfig(config)
+			return err
+		})
+	}
+}
+
+const (
+	// PushPath is the path of the push API, appended to a URL without path.
+	PushPath = "/loki/api/v1/push"
+	// DefaultTimeout is the timeout of a push when none is given.
+	DefaultTimeout = 10 * time.Second
+)
+
+// Encoding is the encoding of the push requests.
+type Encoding string
+
+const (
+	// EncodingJSON pushes the streams as JSON.
+	EncodingJSON Encoding = "json"
+	// EncodingProtobuf pushes the streams as snappy-compressed protobuf, which is smaller.
+	EncodingProtobuf Encoding = "protobuf"
+)
+
+// ParseEncoding parses an encoding case-insensitively, "proto" is accepted as well.
+func ParseEncoding(s string) (Encoding, error) {
+	switch strings.ToLower(s) {
+	case "json":
+		return EncodingJSON, nil
+	case "protobuf", "proto":
+		return EncodingProtobuf, nil
+	default:
+		return "", NewInvalidEncodingException(s)
+	}
+}
+
+// LokiHandler pushes the records in batches to the push API of Loki.
+//
+// Each record is a log line of the stream of its labels, the static labels
+// and the labels read from the fields of the record, e.g. level, timestamped when it is written.
+// A push failing with a network error, a 429 or a 5xx status is retried with a jittered exponential backoff,
+// the records of the pushes failing after all the retries are dropped, see [LokiHandler.Failed].
+type LokiHandler struct {
+	url         string
+	encoding    Encoding
+	labels      map[string]string
+	labelFields []string
+	headers     http.Header
+	timeout     time.Duration
+	tlsConfig   *tls.Config
+	batch       batch.Options
+	sender      httpsend.Sender
+	batcher     *batch.Batcher
+	now         func() time.Time // for testing
+}
+
+// NewLokiHandler creates a new Loki handler pushing to the given URL, the push path is appended if it has no path,
+// e.g. http://loki:3100. At least one static label is required, see [WithLabels].
+func NewLokiHandler(endpoint string, opts ...Option) (*LokiHandler, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, NewInvalidURLException(endpoint)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = PushPath
+	}
+	h := &LokiHandler{
+		url:      u.String(),
+		encoding: EncodingJSON,
+		labels:   make(map[string]string),
+		headers:  make(http.Header),
+		timeout:  DefaultTimeout,
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	if !hasLabel(h.labels) {
+		return nil, NewLabelsMissingException()
+	}
+	if h.sender.Client == nil {
+		h.sender.Client = httpsend.NewClient(h.timeout, h.tlsConfig)
+	}
+	h.batcher = batch.New(h.push, h.batch)
+	return h, nil
+}
+
+func NewLokiHandlerFromConfig(config map[string]any) (*LokiHandler, error) {
+	url, opts, err := parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return NewLokiHandler(url, opts...)
+}
+
+// parseConfig decodes the config into the arguments and the options of [NewLokiHandler].
+func parseConfig(config map[string]any) (string, []Option, error) {
+	var cfg struct {
+		URL           string
+		Encoding      string
+		Labels        map[string]string
+		LabelFields   []string
+		Tenant        string
+		Username      string
+		Password      string
+		Headers       map[string]string
+		BatchSize     int
+		BatchBytes    int
+		FlushInterval time.Duration
+		MaxInFlight   int
+		MaxRetries    int
+		Backoff       backoff.Backoff
+		Timeout       time.Duration
+		TLS           tlsconfig.Config
+	}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           &cfg,
+		WeaklyTypedInput: true,
+		MatchName: func(mapKey, fieldName string) bool {
+			return strings.EqualFold(mapKey, fieldName) || strings.EqualFold(fieldName, strings.ReplaceAll(mapKey, "_", ""))
+		},
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			env.ExpandStringWithEnvHookFunc(),
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToSliceHookFunc(","),
+			mapstructure.StringToBasicTypeHookFunc(),
+		),
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	if err := decoder.Decode(config); err != nil {
+		return "", nil, err
+	}
+	if !hasLabel(cfg.Labels) {
+		return "", nil, NewLabelsMissingException()
+	}
+	opts := []Option{
+		WithLabels(cfg.Labels),
+		WithLabelFields(cfg.LabelFields...),
+		WithTenant(cfg.Tenant),
+		WithHeaders(cfg.Headers),
+		WithBatchSize(cfg.BatchSize),
+		WithBatchBytes(cfg.BatchBytes),
+		WithFlushInterval(cfg.FlushInterval),
+		WithMaxInFlight(cfg.MaxInFlight),
+		WithMaxRetries(cfg.MaxRetries),
+		WithBackoff(cfg.Backoff),
+		WithTimeout(cfg.Timeout),
+	}
+	if cfg.Username != "" || cfg.Password != "" {
+		opts = append(opts, WithBasicAuth(cfg.Username, cfg.Password))
+	}
+	if cfg.Encoding != "" {
+		encoding, err := ParseEncoding(cfg.Encoding)
+		if err != nil {
+			return "", nil, err
+		}
+		opts = append(opts, WithEncoding(encoding))
+	}
+	tlsConfig, err := cfg.TLS.Build()
+	if err != nil {
+		return "", nil, err
+	}
+	if tlsConfig != nil {
+		opts = append(opts, WithTLSConfig(tlsConfig))
+	}
+	return cfg.URL, opts, nil
+}
+
+// hasLabel reports whether there is a label with a value, the labels without a value are ignored by Loki.
+func hasLabel(labels map[string]string) bool {
+	for _, value := range labels {
+		if value != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// labelName replaces the characters not allowed in a label name by underscores.
+func labelName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		if c != '_' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (i == 0 || c < '0' || c > '9') {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+// streams groups the records of a batch by their labels, each record is prefixed with its timestamp,
+// the streams are in the order of their first record.
+func (h *LokiHandler) streams(records [][]byte) []*stream {
+	var streams []*stream
+	index := make(map[string]*stream)
+	for _, r := range records {
+		t := time.Unix(0, int64(binary.BigEndian.Uint64(r)))
+		line := bytes.TrimRight(r[8:], "\r\n")
+		labels := make(map[string]string, len(h.labels)+len(h.labelFields))
+		for name, value := range h.labels {
+			labels[name] = value
+		}
+		for _, field := range h.labelFields {
+			if value, ok := record.Field(line, field); ok && value != "" {
+				labels[labelName(field)] = value
+			}
+		}
+		s := &stream{labels: labels}
+		key := s.key()
+		if existing, ok := index[key]; ok {
+			s = existing
+		} else {
+			index[key] = s
+			streams = append(streams, s)
+		}
+		s.entries = append(s.entries, entry{time: t, line: string(line)})
+	}
+	return streams
+}
+
+// push pushes a batch of records in one request.
+func (h *LokiHandler) push(ctx context.Context, records [][]byte) error {
+	streams := h.streams(records)
+	var body []byte
+	var contentType string
+	if h.encoding == EncodingProtobuf {
+		body, contentType = snappy.Encode(nil, encodeProtobuf(streams)), "application/x-protobuf"
+	} else {
+		var err error
+		if body, err = encodeJSON(streams); err != nil {
+			return err
+		}
+		contentType = "application/json"
+	}
+	_, err := h.sender.Do(ctx, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		for key, values := range h.headers {
+			req.Header[key] = values
+		}
+		req.Header.Set("Content-Type", contentType)
+		return req, nil
+	})
+	return err
+}
+
+// Write adds the record to the batch, timestamped with the current time, it is pushed in the background.
+func (h *LokiHandler) Write(p []byte) (int, error) {
+	r := binary.BigEndian.AppendUint64(make([]byte, 0, 8+len(p)), uint64(h.now().UnixNano()))
+	if _, err := h.batcher.Write(append(r, p...)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Sync pushes the batch and waits until all the pushes are done,
+// it returns the error of the last push failed since the last sync.
+func (h *LokiHandler) Sync() error {
+	return h.batcher.Flush()
+}
+
+// Failed returns the number of records dropped because their pushes failed.
+func (h *LokiHandler) Failed() uint64 {
+	return h.batcher.Failed()
+}
+
+// Shutdown stops accepting records, pushes the batch and waits until all the pushes are done or ctx is done,
+// then the pushes still running are cancelled.
+func (h *LokiHandler) Shutdown(ctx context.Context) error {
+	return h.batcher.Shutdown(ctx)
+}
+
+// Close stops accepting records, pushes the batch and waits until all the pushes are done,
+// writes after Close fail with [os.ErrClosed].
+func (h *LokiHandler) Close() error {
+	return h.batcher.Close()
+}
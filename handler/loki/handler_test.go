@@ -0,0 +1,230 @@
+package loki
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gopi-frame/logger/handler/internal/handlertest"
+	"github.com/klauspost/compress/snappy"
+	"github.com/stretchr/testify/assert"
+)
+
+// protoFields decodes the varint and length-delimited fields of a protobuf message.
+func protoFields(b []byte) map[int][]any {
+	fields := make(map[int][]any)
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		b = b[n:]
+		field := int(key >> 3)
+		v, n := binary.Uvarint(b)
+		b = b[n:]
+		if key&7 == 2 {
+			fields[field] = append(fields[field], b[:v])
+			b = b[v:]
+		} else {
+			fields[field] = append(fields[field], v)
+		}
+	}
+	return fields
+}
+
+type pushedStream struct {
+	Stream map[string]string `json:"stream"`
+	Labels string            `json:"-"`
+	Values [][2]string       `json:"values"`
+}
+
+type push struct {
+	header  http.Header
+	streams []pushedStream
+}
+
+// serve starts a Loki stand-in decoding the pushes, which are answered with the given statuses in turn, then 204.
+func serve(t *testing.T, statuses ...int) (*httptest.Server, *handlertest.Recorder[push]) {
+	pushes := new(handlertest.Recorder[push])
+	var requests atomic.Int64
+	server := handlertest.Serve(t, func(w http.ResponseWriter, r *http.Request) {
+		if n := int(requests.Add(1)); n <= len(statuses) {
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		if r.URL.Path != PushPath {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		p := push{header: r.Header}
+		switch r.Header.Get("Content-Type") {
+		case "application/json":
+			var data struct {
+				Streams []pushedStream `json:"streams"`
+			}
+			if err := json.Unmarshal(body, &data); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			p.streams = data.Streams
+		case "application/x-protobuf":
+			body, err := snappy.Decode(nil, body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			for _, s := range protoFields(body)[1] {
+				fields := protoFields(s.([]byte))
+				stream := pushedStream{Labels: string(fields[1][0].([]byte))}
+				for _, e := range fields[2] {
+					entry := protoFields(e.([]byte))
+					timestamp := protoFields(entry[1][0].([]byte))
+					seconds, nanos := timestamp[1][0].(uint64), uint64(0)
+					if len(timestamp[2]) > 0 {
+						nanos = timestamp[2][0].(uint64)
+					}
+					ts := time.Unix(int64(seconds), int64(nanos)).UnixNano()
+					stream.Values = append(stream.Values, [2]string{time.Unix(0, ts).Format(time.RFC3339Nano), string(entry[2][0].([]byte))})
+				}
+				p.streams = append(p.streams, stream)
+			}
+		default:
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		pushes.Add(p)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	return server, pushes
+}
+
+func clock() time.Time {
+	return time.Date(2026, 10, 17, 12, 0, 0, 500, time.UTC)
+}
+
+func TestLokiHandler(t *testing.T) {
+	records := []string{
+		`{"level":"info","msg":"a"}` + "\n",
+		`{"level":"error","msg":"b"}` + "\n",
+		`{"level":"info","msg":"c"}` + "\n",
+		`{"msg":"d"}` + "\n",
+	}
+
+	t.Run("json", func(t *testing.T) {
+		server, pushes := serve(t)
+		h := handlertest.New(t, NewLokiHandlerFromConfig, map[string]any{
+			"url":         server.URL,
+			"labels":      map[string]any{"app": "api", "env": "prod"},
+			"labelFields": "level,service.name",
+			"tenant":      "team-a",
+			"username":    "user",
+			"password":    "secret",
+		})
+		h.now = clock
+		handlertest.Write(t, h, records...)
+		assert.NoError(t, h.Close())
+		got := pushes.All()
+		if !assert.Len(t, got, 1) {
+			return
+		}
+		assert.Equal(t, "team-a", got[0].header.Get("X-Scope-OrgID"))
+		assert.Equal(t, "Basic dXNlcjpzZWNyZXQ=", got[0].header.Get("Authorization"))
+		ts := "1792238400000000500"
+		assert.Equal(t, []pushedStream{
+			{
+				Stream: map[string]string{"app": "api", "env": "prod", "level": "info"},
+				Values: [][2]string{{ts, `{"level":"info","msg":"a"}`}, {ts, `{"level":"info","msg":"c"}`}},
+			},
+			{
+				Stream: map[string]string{"app": "api", "env": "prod", "level": "error"},
+				Values: [][2]string{{ts, `{"level":"error","msg":"b"}`}},
+			},
+			{
+				Stream: map[string]string{"app": "api", "env": "prod"},
+				Values: [][2]string{{ts, `{"msg":"d"}`}},
+			},
+		}, got[0].streams)
+	})
+
+	t.Run("protobuf", func(t *testing.T) {
+		server, pushes := serve(t)
+		h := handlertest.New(t, NewLokiHandlerFromConfig, map[string]any{
+			"url":         server.URL + "/",
+			"encoding":    "protobuf",
+			"labels":      map[string]any{"app": "api"},
+			"labelFields": []string{"level"},
+		})
+		h.now = clock
+		handlertest.Write(t, h, records...)
+		assert.NoError(t, h.Close())
+		got := pushes.All()
+		if !assert.Len(t, got, 1) {
+			return
+		}
+		ts := "2026-10-17T12:00:00.0000005Z"
+		assert.Equal(t, []pushedStream{
+			{
+				Labels: `{app="api", level="info"}`,
+				Values: [][2]string{{ts, `{"level":"info","msg":"a"}`}, {ts, `{"level":"info","msg":"c"}`}},
+			},
+			{
+				Labels: `{app="api", level="error"}`,
+				Values: [][2]string{{ts, `{"level":"error","msg":"b"}`}},
+			},
+			{
+				Labels: `{app="api"}`,
+				Values: [][2]string{{ts, `{"msg":"d"}`}},
+			},
+		}, got[0].streams)
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		server, pushes := serve(t, http.StatusTooManyRequests, http.StatusBadGateway)
+		h := handlertest.New(t, NewLokiHandlerFromConfig, map[string]any{
+			"url":     server.URL,
+			"backoff": map[string]any{"initial": "1ms"},
+			"labels":  map[string]any{"app": "api"},
+		})
+		h.now = clock
+		handlertest.Write(t, h, records[0])
+		assert.NoError(t, h.Sync())
+		assert.Len(t, pushes.All(), 1)
+		assert.Equal(t, uint64(0), h.Failed())
+		assert.NoError(t, h.Close())
+	})
+
+	t.Run("batch size", func(t *testing.T) {
+		server, pushes := serve(t)
+		h := handlertest.New(t, NewLokiHandlerFromConfig, map[string]any{
+			"url":       server.URL,
+			"batchSize": 2,
+			"labels":    map[string]any{"app": "api"},
+		})
+		h.now = clock
+		handlertest.Write(t, h, records...)
+		assert.NoError(t, h.Close())
+		assert.Len(t, pushes.All(), 2)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := NewLokiHandlerFromConfig(map[string]any{"url": "loki:3100", "labels": map[string]any{"app": "api"}})
+		assert.IsType(t, new(InvalidURLException), err)
+		_, err = NewLokiHandlerFromConfig(map[string]any{"url": "http://loki:3100", "labels": map[string]any{"app": "api"}, "encoding": "xml"})
+		assert.IsType(t, new(InvalidEncodingException), err)
+		_, err = NewLokiHandlerFromConfig(map[string]any{"url": "http://loki:3100"})
+		assert.IsType(t, new(LabelsMissingException), err)
+		_, err = NewLokiHandlerFromConfig(map[string]any{"url": "http://loki:3100", "labels": map[string]any{"app": ""}})
+		assert.IsType(t, new(LabelsMissingException), err)
+		_, err = NewLokiHandler("http://loki:3100")
+		assert.IsType(t, new(LabelsMissingException), err)
+	})
+}
+
+func TestLabelName(t *testing.T) {
+	assert.Equal(t, "service_name", labelName("service.name"))
+	assert.Equal(t, "_st", labelName("1st"))
+	assert.Equal(t, "level_2", labelName("level_2"))
+}
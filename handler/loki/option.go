@@ -0,0 +1,136 @@
+package loki
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"net/http"
+	"time"
+
+	"github.com/gopi-frame/logger/handler/internal/backoff"
+)
+
+type Option func(h *LokiHandler)
+
+// WithEncoding sets the encoding of the push requests, [EncodingJSON] by default.
+func WithEncoding(encoding Encoding) Option {
+	return func(h *LokiHandler) {
+		h.encoding = encoding
+	}
+}
+
+// WithLabels adds static labels to the streams, e.g. app and env, at least one is required.
+func WithLabels(labels map[string]string) Option {
+	return func(h *LokiHandler) {
+		for name, value := range labels {
+			h.labels[labelName(name)] = value
+		}
+	}
+}
+
+// WithLabelFields adds the values of the given fields of the records to the labels of their streams, e.g. level.
+// The fields should have few distinct values, each set of labels is a stream in Loki.
+func WithLabelFields(fields ...string) Option {
+	return func(h *LokiHandler) {
+		h.labelFields = append(h.labelFields, fields...)
+	}
+}
+
+// WithTenant sets the tenant of a multi-tenant Loki, sent in the X-Scope-OrgID header.
+func WithTenant(tenant string) Option {
+	return func(h *LokiHandler) {
+		if tenant != "" {
+			h.headers.Set("X-Scope-OrgID", tenant)
+		}
+	}
+}
+
+// WithBasicAuth authenticates the push requests with the given username and password.
+func WithBasicAuth(username, password string) Option {
+	return func(h *LokiHandler) {
+		h.headers.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(username+":"+password)))
+	}
+}
+
+// WithHeaders adds the headers to the push requests.
+func WithHeaders(headers map[string]string) Option {
+	return func(h *LokiHandler) {
+		for key, value := range headers {
+			h.headers.Add(key, value)
+		}
+	}
+}
+
+// WithBatchSize sets the max number of records of a push, see [batch.DefaultMaxCount] for the default.
+func WithBatchSize(size int) Option {
+	return func(h *LokiHandler) {
+		h.batch.MaxCount = size
+	}
+}
+
+// WithBatchBytes sets the max size of the records of a push before encoding,
+// see [batch.DefaultMaxBytes] for the default.
+func WithBatchBytes(size int) Option {
+	return func(h *LokiHandler) {
+		h.batch.MaxBytes = size
+	}
+}
+
+// WithFlushInterval sets the max time a record waits before it is pushed, see [batch.DefaultInterval] for the default.
+func WithFlushInterval(interval time.Duration) Option {
+	return func(h *LokiHandler) {
+		h.batch.Interval = interval
+	}
+}
+
+// WithMaxInFlight sets the max number of pushes sent concurrently, 1 by default.
+// The order of the entries of a stream is kept by a single push at a time only.
+func WithMaxInFlight(n int) Option {
+	return func(h *LokiHandler) {
+		h.batch.MaxInFlight = n
+	}
+}
+
+// WithErrorHandler sets the function called with the errors of the pushes failed after all the retries.
+func WithErrorHandler(handler func(error)) Option {
+	return func(h *LokiHandler) {
+		h.batch.ErrorHandler = handler
+	}
+}
+
+// WithMaxRetries sets the number of retries of a push failing with a network error, a 429 or a 5xx status,
+// see [httpsend.DefaultMaxRetries] for the default, a negative number disables the retries.
+func WithMaxRetries(n int) Option {
+	return func(h *LokiHandler) {
+		h.sender.MaxRetries = n
+	}
+}
+
+// WithBackoff sets the backoff between the retries, see [backoff.Backoff] for the defaults.
+func WithBackoff(b backoff.Backoff) Option {
+	return func(h *LokiHandler) {
+		h.sender.Backoff = b
+	}
+}
+
+// WithTimeout sets the timeout of a push, it is ignored if it is not positive or a client is given.
+func WithTimeout(timeout time.Duration) Option {
+	return func(h *LokiHandler) {
+		if timeout > 0 {
+			h.timeout = timeout
+		}
+	}
+}
+
+// WithTLSConfig sets the TLS configuration of the pushes, it is ignored if a client is given.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(h *LokiHandler) {
+		h.tlsConfig = config
+	}
+}
+
+// WithClient sets the client sending the pushes.
+func WithClient(client *http.Client) Option {
+	return func(h *LokiHandler) {
+		h.sender.Client = client
+	}
+}
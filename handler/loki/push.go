@@ -0,0 +1,99 @@
+package loki
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// stream is the entries of a batch sharing the same labels.
+type stream struct {
+	labels  map[string]string
+	entries []entry
+}
+
+type entry struct {
+	time time.Time
+	line string
+}
+
+// key returns the labels in the Prometheus format, e.g. {app="api", level="info"}, sorted by name.
+func (s *stream) key() string {
+	names := make([]string, 0, len(s.labels))
+	for name := range s.labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(s.labels[name]))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// encodeJSON encodes the streams as the JSON body of a push request.
+func encodeJSON(streams []*stream) ([]byte, error) {
+	type pushStream struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	}
+	body := struct {
+		Streams []pushStream `json:"streams"`
+	}{Streams: make([]pushStream, 0, len(streams))}
+	for _, s := range streams {
+		values := make([][2]string, 0, len(s.entries))
+		for _, e := range s.entries {
+			values = append(values, [2]string{strconv.FormatInt(e.time.UnixNano(), 10), e.line})
+		}
+		body.Streams = append(body.Streams, pushStream{Stream: s.labels, Values: values})
+	}
+	return json.Marshal(body)
+}
+
+// encodeProtobuf encodes the streams as the protobuf body of a push request, before compression:
+//
+//	message PushRequest { repeated StreamAdapter streams = 1; }
+//	message StreamAdapter { string labels = 1; repeated EntryAdapter entries = 2; }
+//	message EntryAdapter { google.protobuf.Timestamp timestamp = 1; string line = 2; }
+//	message Timestamp { int64 seconds = 1; int32 nanos = 2; }
+func encodeProtobuf(streams []*stream) []byte {
+	var body, message, entryMessage, timestamp []byte
+	for _, s := range streams {
+		message = appendBytes(message[:0], 1, []byte(s.key()))
+		for _, e := range s.entries {
+			timestamp = appendVarint(timestamp[:0], 1, uint64(e.time.Unix()))
+			timestamp = appendVarint(timestamp, 2, uint64(e.time.Nanosecond()))
+			entryMessage = appendBytes(entryMessage[:0], 1, timestamp)
+			entryMessage = appendBytes(entryMessage, 2, []byte(e.line))
+			message = appendBytes(message, 2, entryMessage)
+		}
+		body = appendBytes(body, 1, message)
+	}
+	return body
+}
+
+// appendVarint appends a varint field, a zero value is omitted.
+func appendVarint(b []byte, field int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = binary.AppendUvarint(b, uint64(field)<<3)
+	return binary.AppendUvarint(b, v)
+}
+
+// appendBytes appends a length-delimited field.
+func appendBytes(b []byte, field int, v []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(field)<<3|2)
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
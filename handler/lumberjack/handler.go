@@ -4,9 +4,11 @@ import (
 	"github.com/go-viper/mapstructure/v2"
 	"github.com/gopi-frame/env"
 	"github.com/gopi-frame/logger"
+	"github.com/gopi-frame/logger/handler/internal/symlink"
 	"gopkg.in/natefinch/lumberjack.v2"
 	"io"
 	"strings"
+	"sync"
 )
 
 var handlerName = "lumberjack"
@@ -15,7 +17,11 @@ var handlerName = "lumberjack"
 func init() {
 	if handlerName != "" {
 		logger.RegisterHandler(handlerName, func(config map[string]any) (io.WriteCloser, error) {
-			return NewLumberjackHandlerFromConfig(config)
+			return NewSymlinkHandlerFromConfig(config)
+		})
+		logger.RegisterHandlerValidator(handlerName, func(config map[string]any) error {
+			_, err := parseConfig(config)
+			return err
 		})
 	}
 }
@@ -29,9 +35,53 @@ func NewLumberjackHandler(filename string) *LumberjackHandler {
 }
 
 func NewLumberjackHandlerFromConfig(config map[string]any) (*LumberjackHandler, error) {
-	var handler LumberjackHandler
+	cfg, err := parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return &cfg.Logger, nil
+}
+
+// SymlinkHandler is a wrapper around [LumberjackHandler], which is reopened by [logger.ReopenHandler]
+// and maintains a symlink to the file. The registered lumberjack handler creates it.
+//
+// The file of lumberjack keeps its name and the backups are renamed, so a symlink set by [SymlinkHandler.Symlink]
+// is created on the first write only, e.g. logs/current.log -> app.log.
+type SymlinkHandler struct {
+	*LumberjackHandler
+	// Symlink is the path of a symlink maintained to point to the file.
+	Symlink string
+	linked  sync.Once
+}
+
+// NewLumberjackHandlerWithSymlink creates a new lumberjack handler which maintains a symlink to the file.
+func NewLumberjackHandlerWithSymlink(filename string, symlink string) *SymlinkHandler {
+	return &SymlinkHandler{
+		LumberjackHandler: NewLumberjackHandler(filename),
+		Symlink:           symlink,
+	}
+}
+
+// NewSymlinkHandlerFromConfig creates a new [SymlinkHandler] from the config of [NewLumberjackHandlerFromConfig]
+// and the symlink.
+func NewSymlinkHandlerFromConfig(config map[string]any) (*SymlinkHandler, error) {
+	cfg, err := parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return &SymlinkHandler{LumberjackHandler: &cfg.Logger, Symlink: cfg.Symlink}, nil
+}
+
+type handlerConfig struct {
+	lumberjack.Logger `mapstructure:",squash"`
+	Symlink           string
+}
+
+// parseConfig decodes the config of [NewSymlinkHandlerFromConfig].
+func parseConfig(config map[string]any) (*handlerConfig, error) {
+	var cfg handlerConfig
 	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
-		Result:           &handler,
+		Result:           &cfg,
 		WeaklyTypedInput: true,
 		MatchName: func(mapKey, fieldName string) bool {
 			return strings.EqualFold(mapKey, fieldName) || strings.EqualFold(fieldName, strings.ReplaceAll(mapKey, "_", ""))
@@ -50,5 +100,21 @@ func NewLumberjackHandlerFromConfig(config map[string]any) (*LumberjackHandler,
 	if err := decoder.Decode(config); err != nil {
 		return nil, err
 	}
-	return &handler, nil
+	return &cfg, nil
+}
+
+// Write writes to the file, the symlink is created on the first write if both it and the filename are set.
+func (h *SymlinkHandler) Write(p []byte) (int, error) {
+	n, err := h.LumberjackHandler.Write(p)
+	if h.Symlink != "" && h.Filename != "" {
+		h.linked.Do(func() {
+			_ = symlink.Update(h.Symlink, h.Filename)
+		})
+	}
+	return n, err
+}
+
+// Reopen closes the current file, the next write opens it again by its name.
+func (h *SymlinkHandler) Reopen() error {
+	return h.LumberjackHandler.Close()
 }
@@ -0,0 +1,39 @@
+package network
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/gopi-frame/contract/exception"
+	"github.com/gopi-frame/exception"
+)
+
+type InvalidFramingException struct {
+	Throwable
+}
+
+func NewInvalidFramingException(framing string) *InvalidFramingException {
+	return &InvalidFramingException{
+		Throwable: exception.New(fmt.Sprintf("invalid framing [%s]", framing)),
+	}
+}
+
+type InvalidNetworkException struct {
+	Throwable
+}
+
+func NewInvalidNetworkException(network string) *InvalidNetworkException {
+	return &InvalidNetworkException{
+		Throwable: exception.New(fmt.Sprintf("invalid network [%s]", network)),
+	}
+}
+
+type UnavailableException struct {
+	Throwable
+}
+
+func NewUnavailableException(address string, retryAt time.Time) *UnavailableException {
+	return &UnavailableException{
+		Throwable: exception.New(fmt.Sprintf("[%s] is unavailable, reconnecting at %s", address, retryAt.Format(time.RFC3339Nano))),
+	}
+}
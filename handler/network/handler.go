@@ -0,0 +1,287 @@
+package network
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/gopi-frame/env"
+	"github.com/gopi-frame/logger"
+	"github.com/gopi-frame/logger/handler/internal/backoff"
+	"github.com/gopi-frame/logger/handler/internal/tlsconfig"
+)
+
+var handlerName = "network"
+
+//goland:noinspection GoBoolExpressions
+func init() {
+	if handlerName != "" {
+		logger.RegisterHandler(handlerName, func(config map[string]any) (io.WriteCloser, error) {
+			return NewNetworkHandlerFromConfig(config)
+		})
+		logger.RegisterHandlerValidator(handlerName, func(config map[string]any) error {
+			_, _, _, err := parseConfig(config)
+			return err
+		})
+	}
+}
+
+const (
+	// DefaultDialTimeout is the timeout to connect when none is given.
+	DefaultDialTimeout = 5 * time.Second
+	// DefaultWriteTimeout is the timeout to write a record when none is given.
+	DefaultWriteTimeout = 5 * time.Second
+)
+
+// Framing is how the records are delimited on the connection.
+type Framing string
+
+const (
+	// FramingNewline terminates each record with a single newline.
+	FramingNewline Framing = "newline"
+	// FramingLengthPrefix prefixes each record with its length as a 4-byte big-endian integer.
+	FramingLengthPrefix Framing = "lengthPrefix"
+)
+
+// ParseFraming parses a framing case-insensitively, ignoring dashes and underscores.
+func ParseFraming(s string) (Framing, error) {
+	switch strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(s)) {
+	case "newline":
+		return FramingNewline, nil
+	case "lengthprefix":
+		return FramingLengthPrefix, nil
+	default:
+		return "", NewInvalidFramingException(s)
+	}
+}
+
+// NetworkHandler writes every record to a TCP, UDP or unix socket, or over TLS.
+//
+// The records are written on a pool of connections, opened on demand.
+// A connection failing to write is closed and opened again to write the record once more,
+// when it fails to connect, the next attempts of all the connections are delayed by an exponential backoff,
+// during which the writes fail immediately with an [UnavailableException].
+type NetworkHandler struct {
+	network      string
+	address      string
+	datagram     bool
+	framing      Framing
+	tlsConfig    *tls.Config
+	poolSize     int
+	dialTimeout  time.Duration
+	writeTimeout time.Duration
+	backoff      backoff.Backoff
+	pool         chan *conn
+
+	// dialing is held while connecting, so a single attempt is made per backoff delay
+	// whatever the size of the pool.
+	dialing  sync.Mutex
+	failures int
+	retryAt  time.Time
+
+	done    chan struct{}
+	closed  atomic.Bool
+	closing sync.Once
+	now     func() time.Time // for testing
+}
+
+// conn is a connection of the pool, nil until it is opened.
+type conn struct {
+	net.Conn
+}
+
+// NewNetworkHandler creates a new network handler for the given network and address,
+// the network is one of "tcp", "udp", "unix", "unixgram" and their variants.
+func NewNetworkHandler(network, address string, opts ...Option) (*NetworkHandler, error) {
+	h := &NetworkHandler{
+		network:      network,
+		address:      address,
+		framing:      FramingNewline,
+		poolSize:     1,
+		dialTimeout:  DefaultDialTimeout,
+		writeTimeout: DefaultWriteTimeout,
+		done:         make(chan struct{}),
+		now:          time.Now,
+	}
+	switch network {
+	case "tcp", "tcp4", "tcp6", "unix":
+	case "udp", "udp4", "udp6", "unixgram":
+		h.datagram = true
+	default:
+		return nil, NewInvalidNetworkException(network)
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	if h.tlsConfig != nil && h.datagram {
+		return nil, NewInvalidNetworkException(network + " with TLS")
+	}
+	h.pool = make(chan *conn, h.poolSize)
+	for i := 0; i < h.poolSize; i++ {
+		h.pool <- new(conn)
+	}
+	return h, nil
+}
+
+func NewNetworkHandlerFromConfig(config map[string]any) (*NetworkHandler, error) {
+	network, address, opts, err := parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return NewNetworkHandler(network, address, opts...)
+}
+
+// parseConfig decodes the config into the arguments and the options of [NewNetworkHandler].
+func parseConfig(config map[string]any) (string, string, []Option, error) {
+	var cfg struct {
+		Network      string
+		Address      string
+		Framing      string
+		TLS          tlsconfig.Config
+		PoolSize     int
+		DialTimeout  time.Duration
+		WriteTimeout time.Duration
+		Backoff      backoff.Backoff
+	}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           &cfg,
+		WeaklyTypedInput: true,
+		MatchName: func(mapKey, fieldName string) bool {
+			return strings.EqualFold(mapKey, fieldName) || strings.EqualFold(fieldName, strings.ReplaceAll(mapKey, "_", ""))
+		},
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			env.ExpandStringWithEnvHookFunc(),
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToBasicTypeHookFunc(),
+		),
+	})
+	if err != nil {
+		return "", "", nil, err
+	}
+	if err := decoder.Decode(config); err != nil {
+		return "", "", nil, err
+	}
+	opts := []Option{
+		WithPoolSize(cfg.PoolSize),
+		WithDialTimeout(cfg.DialTimeout),
+		WithWriteTimeout(cfg.WriteTimeout),
+		WithBackoff(cfg.Backoff),
+	}
+	if cfg.Framing != "" {
+		framing, err := ParseFraming(cfg.Framing)
+		if err != nil {
+			return "", "", nil, err
+		}
+		opts = append(opts, WithFraming(framing))
+	}
+	tlsConfig, err := cfg.TLS.Build()
+	if err != nil {
+		return "", "", nil, err
+	}
+	if tlsConfig != nil {
+		opts = append(opts, WithTLSConfig(tlsConfig))
+	}
+	return cfg.Network, cfg.Address, opts, nil
+}
+
+// frame returns the record framed, without its trailing newline.
+func (h *NetworkHandler) frame(p []byte) []byte {
+	p = bytes.TrimRight(p, "\r\n")
+	if h.framing == FramingLengthPrefix {
+		return append(binary.BigEndian.AppendUint32(make([]byte, 0, 4+len(p)), uint32(len(p))), p...)
+	}
+	return append(append(make([]byte, 0, len(p)+1), p...), '\n')
+}
+
+// connect opens the connection unless the handler is backing off after failing to connect.
+func (h *NetworkHandler) connect(c *conn) error {
+	h.dialing.Lock()
+	defer h.dialing.Unlock()
+	now := h.now()
+	if now.Before(h.retryAt) {
+		return NewUnavailableException(h.address, h.retryAt)
+	}
+	dialer := &net.Dialer{Timeout: h.dialTimeout}
+	var nc net.Conn
+	var err error
+	if h.tlsConfig != nil {
+		nc, err = tls.DialWithDialer(dialer, h.network, h.address, h.tlsConfig)
+	} else {
+		nc, err = dialer.Dial(h.network, h.address)
+	}
+	if err != nil {
+		h.retryAt = now.Add(h.backoff.Delay(h.failures))
+		h.failures++
+		return err
+	}
+	c.Conn = nc
+	h.failures, h.retryAt = 0, time.Time{}
+	return nil
+}
+
+// send writes the frame on the connection, reconnecting and writing it once more if it fails.
+func (h *NetworkHandler) send(c *conn, frame []byte) error {
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if c.Conn == nil {
+			if err = h.connect(c); err != nil {
+				return err
+			}
+		}
+		if h.writeTimeout > 0 {
+			_ = c.SetWriteDeadline(time.Now().Add(h.writeTimeout))
+		}
+		if _, err = c.Write(frame); err == nil {
+			return nil
+		}
+		_ = c.Close()
+		c.Conn = nil
+	}
+	return err
+}
+
+// Write writes the record on a connection of the pool, waiting for one to be available.
+func (h *NetworkHandler) Write(p []byte) (int, error) {
+	var c *conn
+	select {
+	case c = <-h.pool:
+	case <-h.done:
+		return 0, net.ErrClosed
+	}
+	defer func() {
+		h.pool <- c
+	}()
+	if h.closed.Load() {
+		return 0, net.ErrClosed
+	}
+	if err := h.send(c, h.frame(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close waits for the running writes and closes the connections,
+// writes after Close fail with [net.ErrClosed].
+func (h *NetworkHandler) Close() error {
+	var errs []error
+	h.closing.Do(func() {
+		h.closed.Store(true)
+		close(h.done)
+		for i := 0; i < h.poolSize; i++ {
+			c := <-h.pool
+			if c.Conn != nil {
+				errs = append(errs, c.Close())
+				c.Conn = nil
+			}
+		}
+	})
+	return errors.Join(errs...)
+}
@@ -0,0 +1,338 @@
+package network
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gopi-frame/logger/handler/internal/handlertest"
+	"github.com/stretchr/testify/assert"
+)
+
+// accept accepts the connections of the listener and sends the lines read from them,
+// it returns the number of accepted connections as well.
+func accept(listener net.Listener) (<-chan string, *atomic.Int32) {
+	lines := make(chan string, 100)
+	accepted := new(atomic.Int32)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			accepted.Add(1)
+			go func() {
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					lines <- scanner.Text()
+				}
+			}()
+		}
+	}()
+	return lines, accepted
+}
+
+func TestNetworkHandler(t *testing.T) {
+	t.Run("tcp", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		defer func() {
+			_ = listener.Close()
+		}()
+		lines, _ := accept(listener)
+		h := handlertest.New(t, NewNetworkHandlerFromConfig, map[string]any{"network": "tcp", "address": listener.Addr().String()})
+		handlertest.Write(t, h, `{"msg":"a"}`+"\n")
+		handlertest.Write(t, h, `{"msg":"b"}`)
+		assert.Equal(t, `{"msg":"a"}`, <-lines)
+		assert.Equal(t, `{"msg":"b"}`, <-lines)
+
+		// the failed write is written again on a new connection
+		c := <-h.pool
+		_ = c.Close()
+		h.pool <- c
+		handlertest.Write(t, h, `{"msg":"c"}`+"\n")
+		assert.Equal(t, `{"msg":"c"}`, <-lines)
+
+		assert.NoError(t, h.Close())
+		_, err = h.Write([]byte("d"))
+		assert.ErrorIs(t, err, net.ErrClosed)
+	})
+
+	t.Run("length prefix", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		defer func() {
+			_ = listener.Close()
+		}()
+		h := handlertest.New(t, NewNetworkHandlerFromConfig, map[string]any{
+			"network": "tcp",
+			"address": listener.Addr().String(),
+			"framing": "length-prefix",
+		})
+		handlertest.Write(t, h, "first\n")
+		handlertest.Write(t, h, "second")
+		conn, err := listener.Accept()
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		for _, expected := range []string{"first", "second"} {
+			var size uint32
+			assert.NoError(t, binary.Read(conn, binary.BigEndian, &size))
+			record := make([]byte, size)
+			_, err := io.ReadFull(conn, record)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, string(record))
+		}
+		assert.NoError(t, h.Close())
+	})
+
+	t.Run("udp and unixgram", func(t *testing.T) {
+		dir, err := os.MkdirTemp("", "network")
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		defer func() {
+			_ = os.RemoveAll(dir)
+		}()
+		for network, address := range map[string]string{"udp": "127.0.0.1:0", "unixgram": filepath.Join(dir, "agent.sock")} {
+			conn, err := net.ListenPacket(network, address)
+			if !assert.NoError(t, err) {
+				assert.FailNow(t, err.Error())
+			}
+			h := handlertest.New(t, NewNetworkHandlerFromConfig, map[string]any{"network": network, "address": conn.LocalAddr().String()})
+			handlertest.Write(t, h, "record\n")
+			buf := make([]byte, 1024)
+			n, _, err := conn.ReadFrom(buf)
+			assert.NoError(t, err)
+			assert.Equal(t, "record\n", string(buf[:n]), network)
+			assert.NoError(t, h.Close())
+			_ = conn.Close()
+		}
+	})
+
+	t.Run("tls", func(t *testing.T) {
+		caFile, serverCert := newCertificates(t)
+		listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{serverCert}})
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		defer func() {
+			_ = listener.Close()
+		}()
+		lines, _ := accept(listener)
+		h := handlertest.New(t, NewNetworkHandlerFromConfig, map[string]any{
+			"network": "tcp",
+			"address": listener.Addr().String(),
+			"tls":     map[string]any{"caFile": caFile},
+		})
+		handlertest.Write(t, h, "secure\n")
+		assert.Equal(t, "secure", <-lines)
+		assert.NoError(t, h.Close())
+
+		// the server is not trusted without the CA
+		h = handlertest.New(t, NewNetworkHandlerFromConfig, map[string]any{
+			"network": "tcp",
+			"address": listener.Addr().String(),
+			"tls":     map[string]any{"enabled": true},
+		})
+		_, err = h.Write([]byte("insecure\n"))
+		assert.Error(t, err)
+		assert.NoError(t, h.Close())
+	})
+
+	t.Run("backoff", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		address := listener.Addr().String()
+		_ = listener.Close()
+		h := handlertest.New(t, NewNetworkHandlerFromConfig, map[string]any{
+			"network": "tcp",
+			"address": address,
+			"backoff": map[string]any{"initial": "1s", "max": "4s", "jitter": -1},
+		})
+		now := time.Now()
+		h.now = func() time.Time {
+			return now
+		}
+		for _, delay := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second} {
+			_, err := h.Write([]byte("a"))
+			assert.Error(t, err)
+			_, err = h.Write([]byte("a"))
+			assert.IsType(t, new(UnavailableException), err)
+			now = now.Add(delay - time.Millisecond)
+			_, err = h.Write([]byte("a"))
+			assert.IsType(t, new(UnavailableException), err)
+			now = now.Add(time.Millisecond)
+		}
+
+		listener, err = net.Listen("tcp", address)
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		defer func() {
+			_ = listener.Close()
+		}()
+		lines, _ := accept(listener)
+		handlertest.Write(t, h, "b\n")
+		assert.Equal(t, "b", <-lines)
+		assert.Zero(t, h.failures)
+		assert.NoError(t, h.Close())
+	})
+
+	t.Run("backoff shared by the pool", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		address := listener.Addr().String()
+		_ = listener.Close()
+		h := handlertest.New(t, NewNetworkHandlerFromConfig, map[string]any{
+			"network":  "tcp",
+			"address":  address,
+			"poolSize": 3,
+			"backoff":  map[string]any{"initial": "1s", "jitter": -1},
+		})
+		now := time.Now()
+		h.now = func() time.Time {
+			return now
+		}
+		_, err = h.Write([]byte("a"))
+		_, unavailable := err.(*UnavailableException)
+		assert.False(t, assert.Error(t, err) && unavailable)
+		// the other connections of the pool do not connect until the delay is over
+		for i := 0; i < 3; i++ {
+			_, err = h.Write([]byte("a"))
+			assert.IsType(t, new(UnavailableException), err)
+		}
+		assert.Equal(t, 1, h.failures)
+		assert.NoError(t, h.Close())
+	})
+
+	t.Run("pool", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		defer func() {
+			_ = listener.Close()
+		}()
+		lines, accepted := accept(listener)
+		h := handlertest.New(t, NewNetworkHandlerFromConfig, map[string]any{"network": "tcp", "address": listener.Addr().String(), "pool_size": 3})
+		var wg sync.WaitGroup
+		for i := 0; i < 30; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_, err := h.Write([]byte(strconv.Itoa(i) + "\n"))
+				assert.NoError(t, err)
+			}(i)
+		}
+		wg.Wait()
+		var received []int
+		for i := 0; i < 30; i++ {
+			n, _ := strconv.Atoi(<-lines)
+			received = append(received, n)
+		}
+		sort.Ints(received)
+		for i, n := range received {
+			assert.Equal(t, i, n)
+		}
+		assert.NoError(t, h.Close())
+		assert.True(t, accepted.Load() >= 1 && accepted.Load() <= 3, accepted.Load())
+	})
+}
+
+func TestNewNetworkHandlerFromConfig(t *testing.T) {
+	h, err := NewNetworkHandlerFromConfig(map[string]any{
+		"network":      "tcp",
+		"address":      "localhost:5170",
+		"framing":      "length_prefix",
+		"poolSize":     4,
+		"writeTimeout": "1s",
+		"backoff":      map[string]any{"initial": "50ms", "jitter": 0.2},
+	})
+	if !assert.NoError(t, err) {
+		assert.FailNow(t, err.Error())
+	}
+	assert.Equal(t, FramingLengthPrefix, h.framing)
+	assert.Equal(t, 4, cap(h.pool))
+	assert.Equal(t, time.Second, h.writeTimeout)
+	assert.Equal(t, DefaultDialTimeout, h.dialTimeout)
+	assert.Equal(t, 50*time.Millisecond, h.backoff.Initial)
+	assert.Equal(t, 0.2, h.backoff.Jitter)
+	assert.Nil(t, h.tlsConfig)
+
+	_, err = NewNetworkHandlerFromConfig(map[string]any{"network": "udp", "tls": map[string]any{"enabled": true}})
+	assert.IsType(t, new(InvalidNetworkException), err)
+	_, err = NewNetworkHandlerFromConfig(map[string]any{"network": "tcp", "framing": "json"})
+	assert.IsType(t, new(InvalidFramingException), err)
+	_, err = NewNetworkHandlerFromConfig(map[string]any{"network": "http"})
+	assert.IsType(t, new(InvalidNetworkException), err)
+}
+
+// newCertificates creates a CA and a certificate of 127.0.0.1 signed by it,
+// and returns the PEM file of the CA and the certificate.
+func newCertificates(t *testing.T) (string, tls.Certificate) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if !assert.NoError(t, err) {
+		assert.FailNow(t, err.Error())
+	}
+	ca := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, ca, ca, &caKey.PublicKey, caKey)
+	if !assert.NoError(t, err) {
+		assert.FailNow(t, err.Error())
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if !assert.NoError(t, err) {
+		assert.FailNow(t, err.Error())
+	}
+	cert := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, cert, ca, &key.PublicKey, caKey)
+	if !assert.NoError(t, err) {
+		assert.FailNow(t, err.Error())
+	}
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}), 0644); !assert.NoError(t, err) {
+		assert.FailNow(t, err.Error())
+	}
+	return caFile, tls.Certificate{Certificate: [][]byte{certDER}, PrivateKey: key}
+}
@@ -0,0 +1,59 @@
+package network
+
+import (
+	"crypto/tls"
+	"time"
+
+	"github.com/gopi-frame/logger/handler/internal/backoff"
+)
+
+type Option func(h *NetworkHandler)
+
+// WithFraming sets how the records are delimited, [FramingNewline] by default.
+func WithFraming(framing Framing) Option {
+	return func(h *NetworkHandler) {
+		h.framing = framing
+	}
+}
+
+// WithTLSConfig connects over TLS with the given configuration, for stream networks only.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(h *NetworkHandler) {
+		h.tlsConfig = config
+	}
+}
+
+// WithPoolSize sets the number of connections the records are written on concurrently,
+// it is ignored if size is not positive.
+func WithPoolSize(size int) Option {
+	return func(h *NetworkHandler) {
+		if size > 0 {
+			h.poolSize = size
+		}
+	}
+}
+
+// WithDialTimeout sets the timeout to connect, it is ignored if it is not positive.
+func WithDialTimeout(timeout time.Duration) Option {
+	return func(h *NetworkHandler) {
+		if timeout > 0 {
+			h.dialTimeout = timeout
+		}
+	}
+}
+
+// WithWriteTimeout sets the timeout to write a record, it is ignored if it is not positive.
+func WithWriteTimeout(timeout time.Duration) Option {
+	return func(h *NetworkHandler) {
+		if timeout > 0 {
+			h.writeTimeout = timeout
+		}
+	}
+}
+
+// WithBackoff sets the backoff between the attempts to connect, see [backoff.Backoff] for the defaults.
+func WithBackoff(b backoff.Backoff) Option {
+	return func(h *NetworkHandler) {
+		h.backoff = b
+	}
+}
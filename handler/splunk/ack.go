@@ -0,0 +1,103 @@
+package splunk
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// acker polls the acks of all the requests waiting for one in a single query per interval.
+type acker struct {
+	interval time.Duration
+	query    func(ctx context.Context, ackIDs []int64) (map[string]bool, error)
+	mu       sync.Mutex
+	// pending receives nil when the ack is acknowledged, or the error of the query
+	pending map[int64]chan error
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+func newAcker(interval time.Duration, query func(ctx context.Context, ackIDs []int64) (map[string]bool, error)) *acker {
+	a := &acker{
+		interval: interval,
+		query:    query,
+		pending:  make(map[int64]chan error),
+		done:     make(chan struct{}),
+	}
+	var ctx context.Context
+	ctx, a.cancel = context.WithCancel(context.Background())
+	go a.run(ctx)
+	return a
+}
+
+func (a *acker) run(ctx context.Context) {
+	defer close(a.done)
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		a.mu.Lock()
+		ackIDs := make([]int64, 0, len(a.pending))
+		for ackID := range a.pending {
+			ackIDs = append(ackIDs, ackID)
+		}
+		a.mu.Unlock()
+		if len(ackIDs) == 0 {
+			continue
+		}
+		acks, err := a.query(ctx, ackIDs)
+		a.mu.Lock()
+		for _, ackID := range ackIDs {
+			ch, ok := a.pending[ackID]
+			if !ok {
+				continue
+			}
+			if err != nil {
+				ch <- err
+			} else if acks[strconv.FormatInt(ackID, 10)] {
+				ch <- nil
+			} else {
+				continue
+			}
+			delete(a.pending, ackID)
+		}
+		a.mu.Unlock()
+	}
+}
+
+// wait waits until the ack is acknowledged or the timeout has elapsed.
+func (a *acker) wait(ctx context.Context, ackID int64, timeout time.Duration) (bool, error) {
+	ch := make(chan error, 1)
+	a.mu.Lock()
+	a.pending[ackID] = ch
+	a.mu.Unlock()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-ch:
+		return err == nil, err
+	case <-ctx.Done():
+		a.forget(ackID)
+		return false, ctx.Err()
+	case <-timer.C:
+		a.forget(ackID)
+		return false, nil
+	}
+}
+
+func (a *acker) forget(ackID int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.pending, ackID)
+}
+
+// close stops the polling and waits until the query running is done.
+func (a *acker) close() {
+	a.cancel()
+	<-a.done
+}
@@ -0,0 +1,38 @@
+package splunk
+
+import (
+	"fmt"
+
+	. "github.com/gopi-frame/contract/exception"
+	"github.com/gopi-frame/exception"
+)
+
+type InvalidURLException struct {
+	Throwable
+}
+
+func NewInvalidURLException(url string) *InvalidURLException {
+	return &InvalidURLException{
+		Throwable: exception.New(fmt.Sprintf("invalid url [%s]", url)),
+	}
+}
+
+type TokenMissingException struct {
+	Throwable
+}
+
+func NewTokenMissingException() *TokenMissingException {
+	return &TokenMissingException{
+		Throwable: exception.New("token is missing"),
+	}
+}
+
+type AckTimeoutException struct {
+	Throwable
+}
+
+func NewAckTimeoutException(ackID int64) *AckTimeoutException {
+	return &AckTimeoutException{
+		Throwable: exception.New(fmt.Sprintf("events of ack [%d] not acknowledged in time", ackID)),
+	}
+}
@@ -0,0 +1,353 @@
+package splunk
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/gopi-frame/env"
+	"github.com/gopi-frame/logger"
+	"github.com/gopi-frame/logger/handler/internal/backoff"
+	"github.com/gopi-frame/logger/handler/internal/batch"
+	"github.com/gopi-frame/logger/handler/internal/httpsend"
+	"github.com/gopi-frame/logger/handler/internal/tlsconfig"
+)
+
+var handlerName = "splunk"
+
+//goland:noinspection GoBoolExpressions
+func init() {
+	if handlerName != "" {
+		logger.RegisterHandler(handlerName, func(config map[string]any) (io.WriteCloser, error) {
+			return NewSplunkHandlerFromConfig(config)
+		})
+		logger.RegisterHandlerValidator(handlerName, func(config map[string]any) error {
+			_, _, _, err := parseConfig(config)
+			return err
+		})
+	}
+}
+
+const (
+	// EventPath is the path of the event endpoint, appended to a URL without path.
+	EventPath = "/services/collector/event"
+	// AckPath is the path of the endpoint querying the acks.
+	AckPath = "/services/collector/ack"
+	// DefaultTimeout is the timeout of a request when none is given.
+	DefaultTimeout = 10 * time.Second
+	// DefaultAckInterval is the interval between the queries of the acks when none is given.
+	DefaultAckInterval = time.Second
+	// DefaultAckTimeout is the time to wait for an ack before sending the events again when none is given.
+	DefaultAckTimeout = time.Minute
+)
+
+// SplunkHandler sends the records in batches to the HTTP Event Collector (HEC) of Splunk,
+// or to another collector accepting its event envelopes.
+//
+// Each record is the event of an envelope with its time, the time it is written, and the configured
+// host, source, sourcetype and index. A JSON record is sent as a JSON event, another record as a string.
+// A request failing with a network error, a 429 or a 5xx status is retried with a jittered exponential backoff.
+// With acks, the events of a request are sent again when they are not acknowledged in time,
+// the acks of all the requests waiting for one are queried together.
+// The records of the requests failing after all the retries are dropped, see [SplunkHandler.Failed].
+//
+// The delivery is at least once: the events of a request sent again may have been indexed already,
+// e.g. when the ack is late or the response is lost, and the requests have no key Splunk could deduplicate
+// them with, so the same event may be indexed several times, up to once per attempt.
+type SplunkHandler struct {
+	url         string
+	ackURL      string
+	token       string
+	host        string
+	source      string
+	sourceType  string
+	index       string
+	fields      map[string]string
+	ack         bool
+	channel     string
+	ackInterval time.Duration
+	ackTimeout  time.Duration
+	timeout     time.Duration
+	tlsConfig   *tls.Config
+	batch       batch.Options
+	sender      httpsend.Sender
+	batcher     *batch.Batcher
+	acker       *acker
+	now         func() time.Time // for testing
+}
+
+// NewSplunkHandler creates a new Splunk handler sending to the collector at the given URL with the given token,
+// the event path is appended if the URL has no path, e.g. https://splunk:8088.
+func NewSplunkHandler(endpoint string, token string, opts ...Option) (*SplunkHandler, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, NewInvalidURLException(endpoint)
+	}
+	if token == "" {
+		return nil, NewTokenMissingException()
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = EventPath
+	}
+	ackURL := *u
+	ackURL.Path, ackURL.RawQuery = AckPath, ""
+	h := &SplunkHandler{
+		url:         u.String(),
+		ackURL:      ackURL.String(),
+		token:       token,
+		ackInterval: DefaultAckInterval,
+		ackTimeout:  DefaultAckTimeout,
+		timeout:     DefaultTimeout,
+		now:         time.Now,
+	}
+	h.host, _ = os.Hostname()
+	for _, opt := range opts {
+		opt(h)
+	}
+	if h.ack && h.channel == "" {
+		h.channel = newChannel()
+	}
+	if h.ack {
+		h.acker = newAcker(h.ackInterval, h.queryAcks)
+	}
+	if h.sender.Client == nil {
+		h.sender.Client = httpsend.NewClient(h.timeout, h.tlsConfig)
+	}
+	h.batcher = batch.New(h.send, h.batch)
+	return h, nil
+}
+
+func NewSplunkHandlerFromConfig(config map[string]any) (*SplunkHandler, error) {
+	url, token, opts, err := parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return NewSplunkHandler(url, token, opts...)
+}
+
+// parseConfig decodes the config into the arguments and the options of [NewSplunkHandler].
+func parseConfig(config map[string]any) (string, string, []Option, error) {
+	var cfg struct {
+		URL           string
+		Token         string
+		Host          string
+		Source        string
+		SourceType    string
+		Index         string
+		Fields        map[string]string
+		Ack           bool
+		Channel       string
+		AckInterval   time.Duration
+		AckTimeout    time.Duration
+		BatchSize     int
+		BatchBytes    int
+		FlushInterval time.Duration
+		MaxInFlight   int
+		MaxRetries    int
+		Backoff       backoff.Backoff
+		Timeout       time.Duration
+		TLS           tlsconfig.Config
+	}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           &cfg,
+		WeaklyTypedInput: true,
+		MatchName: func(mapKey, fieldName string) bool {
+			return strings.EqualFold(mapKey, fieldName) || strings.EqualFold(fieldName, strings.ReplaceAll(mapKey, "_", ""))
+		},
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			env.ExpandStringWithEnvHookFunc(),
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToBasicTypeHookFunc(),
+		),
+	})
+	if err != nil {
+		return "", "", nil, err
+	}
+	if err := decoder.Decode(config); err != nil {
+		return "", "", nil, err
+	}
+	opts := []Option{
+		WithHost(cfg.Host),
+		WithSource(cfg.Source),
+		WithSourceType(cfg.SourceType),
+		WithIndex(cfg.Index),
+		WithFields(cfg.Fields),
+		WithAck(cfg.Ack),
+		WithChannel(cfg.Channel),
+		WithAckInterval(cfg.AckInterval),
+		WithAckTimeout(cfg.AckTimeout),
+		WithBatchSize(cfg.BatchSize),
+		WithBatchBytes(cfg.BatchBytes),
+		WithFlushInterval(cfg.FlushInterval),
+		WithMaxInFlight(cfg.MaxInFlight),
+		WithMaxRetries(cfg.MaxRetries),
+		WithBackoff(cfg.Backoff),
+		WithTimeout(cfg.Timeout),
+	}
+	tlsConfig, err := cfg.TLS.Build()
+	if err != nil {
+		return "", "", nil, err
+	}
+	if tlsConfig != nil {
+		opts = append(opts, WithTLSConfig(tlsConfig))
+	}
+	return cfg.URL, cfg.Token, opts, nil
+}
+
+// newChannel returns a random channel identifier, a version 4 UUID.
+func newChannel() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// envelope is the envelope of an event.
+type envelope struct {
+	Time       json.Number       `json:"time"`
+	Host       string            `json:"host,omitempty"`
+	Source     string            `json:"source,omitempty"`
+	SourceType string            `json:"sourcetype,omitempty"`
+	Index      string            `json:"index,omitempty"`
+	Fields     map[string]string `json:"fields,omitempty"`
+	Event      any               `json:"event"`
+}
+
+// encode encodes the records of a batch, each prefixed with its timestamp, as concatenated envelopes.
+func (h *SplunkHandler) encode(records [][]byte) ([]byte, error) {
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, r := range records {
+		t := time.Unix(0, int64(binary.BigEndian.Uint64(r)))
+		line := bytes.TrimRight(r[8:], "\r\n")
+		e := envelope{
+			Time:       json.Number(strconv.FormatFloat(float64(t.UnixMilli())/1e3, 'f', 3, 64)),
+			Host:       h.host,
+			Source:     h.source,
+			SourceType: h.sourceType,
+			Index:      h.index,
+			Fields:     h.fields,
+			Event:      string(line),
+		}
+		if json.Valid(line) {
+			e.Event = json.RawMessage(line)
+		}
+		if err := enc.Encode(e); err != nil {
+			return nil, err
+		}
+	}
+	return body.Bytes(), nil
+}
+
+// post posts the body to the URL with retries, and decodes the JSON response into v.
+func (h *SplunkHandler) post(ctx context.Context, url string, body []byte, v any) error {
+	data, err := h.sender.Do(ctx, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Splunk "+h.token)
+		req.Header.Set("Content-Type", "application/json")
+		if h.channel != "" {
+			req.Header.Set("X-Splunk-Request-Channel", h.channel)
+		}
+		return req, nil
+	})
+	if err != nil || v == nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// queryAcks queries the acks, and returns whether each of them is acknowledged.
+func (h *SplunkHandler) queryAcks(ctx context.Context, ackIDs []int64) (map[string]bool, error) {
+	body, _ := json.Marshal(map[string][]int64{"acks": ackIDs})
+	var resp struct {
+		Acks map[string]bool `json:"acks"`
+	}
+	if err := h.post(ctx, h.ackURL, body, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Acks, nil
+}
+
+// send sends a batch of records in one request, and sends it again while it is not acknowledged if acks are enabled,
+// the next batch is sent while it waits for its ack.
+func (h *SplunkHandler) send(ctx context.Context, records [][]byte) error {
+	body, err := h.encode(records)
+	if err != nil {
+		return err
+	}
+	for attempt := 0; ; attempt++ {
+		var resp struct {
+			AckID *int64 `json:"ackId"`
+		}
+		if !h.ack {
+			return h.post(ctx, h.url, body, nil)
+		}
+		if err := h.post(ctx, h.url, body, &resp); err != nil {
+			return err
+		}
+		// the acks are disabled on the token
+		if resp.AckID == nil {
+			return nil
+		}
+		batch.Release(ctx)
+		acked, err := h.acker.wait(ctx, *resp.AckID, h.ackTimeout)
+		if err != nil || acked {
+			return err
+		}
+		if attempt >= h.sender.Retries() {
+			return NewAckTimeoutException(*resp.AckID)
+		}
+	}
+}
+
+// Write adds the record to the batch, timestamped with the current time, it is sent in the background.
+func (h *SplunkHandler) Write(p []byte) (int, error) {
+	r := binary.BigEndian.AppendUint64(make([]byte, 0, 8+len(p)), uint64(h.now().UnixNano()))
+	if _, err := h.batcher.Write(append(r, p...)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Sync sends the batch and waits until all the requests are done, and acknowledged if enabled,
+// it returns the error of the last request failed since the last sync.
+func (h *SplunkHandler) Sync() error {
+	return h.batcher.Flush()
+}
+
+// Failed returns the number of records dropped because their requests failed.
+func (h *SplunkHandler) Failed() uint64 {
+	return h.batcher.Failed()
+}
+
+// Shutdown stops accepting records, sends the batch and waits until all the requests are done or ctx is done,
+// then the requests still running are cancelled.
+func (h *SplunkHandler) Shutdown(ctx context.Context) error {
+	err := h.batcher.Shutdown(ctx)
+	if h.acker != nil {
+		h.acker.close()
+	}
+	return err
+}
+
+// Close stops accepting records, sends the batch and waits until all the requests are done,
+// writes after Close fail with [os.ErrClosed].
+func (h *SplunkHandler) Close() error {
+	return h.Shutdown(context.Background())
+}
@@ -0,0 +1,282 @@
+package splunk
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gopi-frame/logger/handler/internal/handlertest"
+	"github.com/stretchr/testify/assert"
+)
+
+type request struct {
+	header http.Header
+	events []map[string]any
+}
+
+// hec is a stand-in of the HTTP Event Collector, the acks are acknowledged after the given number of queries,
+// never if it is negative. The acks of the first lostAcks requests are never acknowledged,
+// as if they were lost while their events are indexed.
+type hec struct {
+	mu       sync.Mutex
+	statuses []int
+	ackAfter int
+	lostAcks int64
+	requests []request
+	queries  map[int64]int
+	// widest is the max number of acks of a query
+	widest  int
+	nextAck int64
+}
+
+func serve(t *testing.T, ackAfter int, statuses ...int) (*httptest.Server, *hec) {
+	s := &hec{statuses: statuses, ackAfter: ackAfter, queries: make(map[int64]int)}
+	server := handlertest.Serve(t, func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if r.Header.Get("Authorization") != "Splunk token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case EventPath:
+			if len(s.statuses) > 0 {
+				status := s.statuses[0]
+				s.statuses = s.statuses[1:]
+				w.WriteHeader(status)
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			req := request{header: r.Header}
+			dec := json.NewDecoder(bytes.NewReader(body))
+			for dec.More() {
+				var event map[string]any
+				if err := dec.Decode(&event); err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				req.events = append(req.events, event)
+			}
+			s.requests = append(s.requests, req)
+			if r.Header.Get("X-Splunk-Request-Channel") == "" {
+				_, _ = w.Write([]byte(`{"text":"Success","code":0}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"text":"Success","code":0,"ackId":` + strconv.FormatInt(s.nextAck, 10) + `}`))
+			s.nextAck++
+		case AckPath:
+			var query struct {
+				Acks []int64 `json:"acks"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&query); err != nil || r.Header.Get("X-Splunk-Request-Channel") == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			s.widest = max(s.widest, len(query.Acks))
+			acks := make(map[string]bool)
+			for _, id := range query.Acks {
+				s.queries[id]++
+				acks[strconv.FormatInt(id, 10)] = s.ackAfter >= 0 && s.queries[id] > s.ackAfter && id >= s.lostAcks
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"acks": acks})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	return server, s
+}
+
+func (s *hec) sent() []request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]request(nil), s.requests...)
+}
+
+func (s *hec) queried(ackID int64) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.queries[ackID]
+}
+
+func (s *hec) widestQuery() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.widest
+}
+
+func clock() time.Time {
+	return time.Date(2026, 10, 17, 12, 0, 0, 250_000_000, time.UTC)
+}
+
+func TestSplunkHandler(t *testing.T) {
+	records := []string{
+		`{"level":"info","msg":"a"}` + "\n",
+		"level=error msg=b\n",
+	}
+
+	t.Run("events", func(t *testing.T) {
+		server, s := serve(t, 0)
+		h := handlertest.New(t, NewSplunkHandlerFromConfig, map[string]any{
+			"url":        server.URL,
+			"token":      "token",
+			"host":       "web-1",
+			"source":     "api",
+			"sourcetype": "_json",
+			"index":      "main",
+			"fields":     map[string]any{"env": "prod"},
+		})
+		h.now = clock
+		handlertest.Write(t, h, records...)
+		assert.NoError(t, h.Close())
+		got := s.sent()
+		if !assert.Len(t, got, 1) {
+			return
+		}
+		assert.Empty(t, got[0].header.Get("X-Splunk-Request-Channel"))
+		assert.Equal(t, []map[string]any{
+			{
+				"time":       1792238400.25,
+				"host":       "web-1",
+				"source":     "api",
+				"sourcetype": "_json",
+				"index":      "main",
+				"fields":     map[string]any{"env": "prod"},
+				"event":      map[string]any{"level": "info", "msg": "a"},
+			},
+			{
+				"time":       1792238400.25,
+				"host":       "web-1",
+				"source":     "api",
+				"sourcetype": "_json",
+				"index":      "main",
+				"fields":     map[string]any{"env": "prod"},
+				"event":      "level=error msg=b",
+			},
+		}, got[0].events)
+	})
+
+	t.Run("ack", func(t *testing.T) {
+		server, s := serve(t, 2)
+		h := handlertest.New(t, NewSplunkHandlerFromConfig, map[string]any{
+			"url":         server.URL,
+			"token":       "token",
+			"ack":         true,
+			"ackInterval": "1ms",
+		})
+		h.now = clock
+		handlertest.Write(t, h, records...)
+		assert.NoError(t, h.Sync())
+		got := s.sent()
+		if assert.Len(t, got, 1) {
+			assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, got[0].header.Get("X-Splunk-Request-Channel"))
+		}
+		assert.Equal(t, 3, s.queried(0))
+		assert.NoError(t, h.Close())
+	})
+
+	t.Run("acks polled together", func(t *testing.T) {
+		server, s := serve(t, 3)
+		h := handlertest.New(t, NewSplunkHandlerFromConfig, map[string]any{
+			"url":         server.URL,
+			"token":       "token",
+			"ack":         true,
+			"ackInterval": "10ms",
+			"batchSize":   1,
+		})
+		h.now = clock
+		// the second request is sent while the first one waits for its ack
+		handlertest.Write(t, h, records...)
+		assert.NoError(t, h.Sync())
+		assert.Len(t, s.sent(), 2)
+		assert.Equal(t, 2, s.widestQuery())
+		assert.Equal(t, uint64(0), h.Failed())
+		assert.NoError(t, h.Close())
+	})
+
+	t.Run("ack timeout", func(t *testing.T) {
+		server, s := serve(t, -1)
+		h := handlertest.New(t, NewSplunkHandlerFromConfig, map[string]any{
+			"url":         server.URL,
+			"token":       "token",
+			"ack":         true,
+			"channel":     "00000000-0000-4000-8000-000000000000",
+			"ackInterval": "1ms",
+			"ackTimeout":  "10ms",
+			"maxRetries":  1,
+		})
+		h.now = clock
+		handlertest.Write(t, h, records...)
+		var timeout *AckTimeoutException
+		assert.ErrorAs(t, h.Sync(), &timeout)
+		got := s.sent()
+		if assert.Len(t, got, 2) {
+			assert.Equal(t, got[0].events, got[1].events)
+			assert.Equal(t, "00000000-0000-4000-8000-000000000000", got[1].header.Get("X-Splunk-Request-Channel"))
+		}
+		assert.Equal(t, uint64(2), h.Failed())
+		assert.NoError(t, h.Close())
+	})
+
+	t.Run("duplicates on a lost ack", func(t *testing.T) {
+		server, s := serve(t, 0)
+		s.lostAcks = 1
+		h := handlertest.New(t, NewSplunkHandlerFromConfig, map[string]any{
+			"url":         server.URL,
+			"token":       "token",
+			"ack":         true,
+			"ackInterval": "1ms",
+			"ackTimeout":  "10ms",
+			"maxRetries":  1,
+		})
+		h.now = clock
+		handlertest.Write(t, h, records...)
+		assert.NoError(t, h.Sync())
+		// the events indexed by the first request are indexed again, the delivery is at least once
+		got := s.sent()
+		if assert.Len(t, got, 2) {
+			assert.Len(t, got[0].events, 2)
+			assert.Equal(t, got[0].events, got[1].events)
+		}
+		assert.Equal(t, 1, s.queried(1))
+		assert.Equal(t, uint64(0), h.Failed())
+		assert.NoError(t, h.Close())
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		server, s := serve(t, 0, http.StatusServiceUnavailable, http.StatusTooManyRequests)
+		h := handlertest.New(t, NewSplunkHandlerFromConfig, map[string]any{
+			"url":     server.URL,
+			"token":   "token",
+			"backoff": map[string]any{"initial": "1ms"},
+		})
+		h.now = clock
+		handlertest.Write(t, h, records[0])
+		assert.NoError(t, h.Sync())
+		assert.Len(t, s.sent(), 1)
+		assert.Equal(t, uint64(0), h.Failed())
+		assert.NoError(t, h.Close())
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		server, _ := serve(t, 0, http.StatusBadRequest)
+		h := handlertest.New(t, NewSplunkHandlerFromConfig, map[string]any{"url": server.URL, "token": "token"})
+		h.now = clock
+		handlertest.Write(t, h, records[0])
+		assert.Error(t, h.Sync())
+		assert.Equal(t, uint64(1), h.Failed())
+		assert.NoError(t, h.Close())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := NewSplunkHandlerFromConfig(map[string]any{"url": "splunk:8088", "token": "token"})
+		assert.IsType(t, new(InvalidURLException), err)
+		_, err = NewSplunkHandlerFromConfig(map[string]any{"url": "https://splunk:8088"})
+		assert.IsType(t, new(TokenMissingException), err)
+	})
+}
@@ -0,0 +1,167 @@
+package splunk
+
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+
+	"github.com/gopi-frame/logger/handler/internal/backoff"
+)
+
+type Option func(h *SplunkHandler)
+
+// WithHost sets the host of the events, the host name of the machine by default.
+func WithHost(host string) Option {
+	return func(h *SplunkHandler) {
+		if host != "" {
+			h.host = host
+		}
+	}
+}
+
+// WithSource sets the source of the events, the default of the token if it is empty.
+func WithSource(source string) Option {
+	return func(h *SplunkHandler) {
+		h.source = source
+	}
+}
+
+// WithSourceType sets the sourcetype of the events, e.g. _json, the default of the token if it is empty.
+func WithSourceType(sourceType string) Option {
+	return func(h *SplunkHandler) {
+		h.sourceType = sourceType
+	}
+}
+
+// WithIndex sets the index of the events, the default index of the token if it is empty.
+func WithIndex(index string) Option {
+	return func(h *SplunkHandler) {
+		h.index = index
+	}
+}
+
+// WithFields adds indexed fields to the events, e.g. app and env.
+func WithFields(fields map[string]string) Option {
+	return func(h *SplunkHandler) {
+		for name, value := range fields {
+			if h.fields == nil {
+				h.fields = make(map[string]string)
+			}
+			h.fields[name] = value
+		}
+	}
+}
+
+// WithAck waits until the events of each request are acknowledged by the indexers,
+// the acks must be enabled on the token. The events are sent again when they are not acknowledged in time,
+// even if they were indexed, so they may be indexed twice, see [SplunkHandler].
+// A request waiting for its ack does not count against [WithMaxInFlight], and neither does its resend,
+// so the records of all the requests sent in the last ack timeout may be held.
+func WithAck(ack bool) Option {
+	return func(h *SplunkHandler) {
+		h.ack = ack
+	}
+}
+
+// WithChannel sets the channel of the requests, a GUID sent in the X-Splunk-Request-Channel header,
+// a random one is generated if it is empty and the acks are enabled.
+func WithChannel(channel string) Option {
+	return func(h *SplunkHandler) {
+		h.channel = channel
+	}
+}
+
+// WithAckInterval sets the interval between the queries of the acks, [DefaultAckInterval] if it is not positive,
+// each query asks for all the acks waited for.
+func WithAckInterval(interval time.Duration) Option {
+	return func(h *SplunkHandler) {
+		if interval > 0 {
+			h.ackInterval = interval
+		}
+	}
+}
+
+// WithAckTimeout sets the time to wait for an ack before the events are sent again,
+// [DefaultAckTimeout] if it is not positive.
+func WithAckTimeout(timeout time.Duration) Option {
+	return func(h *SplunkHandler) {
+		if timeout > 0 {
+			h.ackTimeout = timeout
+		}
+	}
+}
+
+// WithBatchSize sets the max number of events of a request, see [batch.DefaultMaxCount] for the default.
+func WithBatchSize(size int) Option {
+	return func(h *SplunkHandler) {
+		h.batch.MaxCount = size
+	}
+}
+
+// WithBatchBytes sets the max size of the records of a request before encoding,
+// see [batch.DefaultMaxBytes] for the default.
+func WithBatchBytes(size int) Option {
+	return func(h *SplunkHandler) {
+		h.batch.MaxBytes = size
+	}
+}
+
+// WithFlushInterval sets the max time a record waits before it is sent, see [batch.DefaultInterval] for the default.
+func WithFlushInterval(interval time.Duration) Option {
+	return func(h *SplunkHandler) {
+		h.batch.Interval = interval
+	}
+}
+
+// WithMaxInFlight sets the max number of requests sent concurrently, 1 by default.
+func WithMaxInFlight(n int) Option {
+	return func(h *SplunkHandler) {
+		h.batch.MaxInFlight = n
+	}
+}
+
+// WithErrorHandler sets the function called with the errors of the requests failed after all the retries.
+func WithErrorHandler(handler func(error)) Option {
+	return func(h *SplunkHandler) {
+		h.batch.ErrorHandler = handler
+	}
+}
+
+// WithMaxRetries sets the number of retries of a request failing with a network error, a 429 or a 5xx status,
+// and of the events not acknowledged in time, see [httpsend.DefaultMaxRetries] for the default,
+// a negative number disables the retries.
+func WithMaxRetries(n int) Option {
+	return func(h *SplunkHandler) {
+		h.sender.MaxRetries = n
+	}
+}
+
+// WithBackoff sets the backoff between the retries, see [backoff.Backoff] for the defaults.
+func WithBackoff(b backoff.Backoff) Option {
+	return func(h *SplunkHandler) {
+		h.sender.Backoff = b
+	}
+}
+
+// WithTimeout sets the timeout of a request, it is ignored if it is not positive or a client is given.
+func WithTimeout(timeout time.Duration) Option {
+	return func(h *SplunkHandler) {
+		if timeout > 0 {
+			h.timeout = timeout
+		}
+	}
+}
+
+// WithTLSConfig sets the TLS configuration of the requests, it is ignored if a client is given.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(h *SplunkHandler) {
+		h.tlsConfig = config
+	}
+}
+
+// WithClient sets the client sending the requests.
+func WithClient(client *http.Client) Option {
+	return func(h *SplunkHandler) {
+		h.sender.Client = client
+	}
+}
@@ -17,6 +17,18 @@ func init() {
 		logger.RegisterHandler(handlerName, func(config map[string]any) (io.WriteCloser, error) {
 			return NewStackHandlerFromConfig(config)
 		})
+		logger.RegisterHandlerValidator(handlerName, func(config map[string]any) error {
+			_, configs, err := parseConfig(config)
+			if err != nil {
+				return err
+			}
+			var errs []error
+			for _, handler := range configs {
+				driver, _ := handler["driver"].(string)
+				errs = append(errs, logger.ValidateHandler(driver, handler))
+			}
+			return errors.Join(errs...)
+		})
 	}
 }
 
@@ -33,6 +45,31 @@ func NewStackHandler(handlers ...io.WriteCloser) *StackHandler {
 }
 
 func NewStackHandlerFromConfig(config map[string]any) (*StackHandler, error) {
+	breakOnError, configs, err := parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	var handlers []io.WriteCloser
+	for _, handler := range configs {
+		driver, _ := handler["driver"].(string)
+		h, err := logger.CreateHandler(driver, handler)
+		if err != nil {
+			return nil, err
+		}
+		handlers = append(handlers, h)
+	}
+	if len(handlers) == 0 {
+		return &StackHandler{
+			breakOnError: true,
+		}, nil
+	}
+	handler := NewStackHandler(handlers...)
+	handler.breakOnError = breakOnError
+	return handler, nil
+}
+
+// parseConfig decodes the config into the break on error flag and the configs of the stacked handlers.
+func parseConfig(config map[string]any) (bool, []map[string]any, error) {
 	var cfg struct {
 		BreakOnError bool
 		Handlers     []map[string]any
@@ -51,28 +88,12 @@ func NewStackHandlerFromConfig(config map[string]any) (*StackHandler, error) {
 		),
 	})
 	if err != nil {
-		return nil, err
+		return false, nil, err
 	}
 	if err := decoder.Decode(config); err != nil {
-		return nil, err
-	}
-	var handlers []io.WriteCloser
-	for _, handler := range cfg.Handlers {
-		driver := handler["driver"].(string)
-		h, err := logger.CreateHandler(driver, handler)
-		if err != nil {
-			return nil, err
-		}
-		handlers = append(handlers, h)
+		return false, nil, err
 	}
-	if len(handlers) == 0 {
-		return &StackHandler{
-			breakOnError: true,
-		}, nil
-	}
-	handler := NewStackHandler(handlers...)
-	handler.breakOnError = cfg.BreakOnError
-	return handler, nil
+	return cfg.BreakOnError, cfg.Handlers, nil
 }
 
 func (h *StackHandler) Write(p []byte) (n int, err error) {
@@ -89,6 +110,28 @@ func (h *StackHandler) Write(p []byte) (n int, err error) {
 	return
 }
 
+// Reopen reopens every handler implementing [logger.Reopener], errors are joined.
+func (h *StackHandler) Reopen() error {
+	var errs []error
+	for _, handler := range h.handlers {
+		if err := logger.ReopenHandler(handler); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Sync flushes every handler which has a Sync method, errors are joined.
+func (h *StackHandler) Sync() error {
+	var errs []error
+	for _, handler := range h.handlers {
+		if err := logger.SyncHandler(handler); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func (h *StackHandler) Close() error {
 	var errs []error
 	for _, handler := range h.handlers {
@@ -121,4 +121,13 @@ func TestNewStackHandlerFromConfig(t *testing.T) {
 			assert.Equal(t, "test", handler.handlers[2].(*mockHandler3).String())
 		}
 	})
+	t.Run("validate", func(t *testing.T) {
+		assert.NoError(t, logger.ValidateHandler(handlerName, map[string]any{
+			"handlers": []map[string]any{{"driver": "mock1"}, {"driver": "mock2"}},
+		}))
+		err := logger.ValidateHandler(handlerName, map[string]any{
+			"handlers": []map[string]any{{"driver": "mock1"}, {"driver": "missing"}},
+		})
+		assert.ErrorContains(t, err, "unknown handler [missing]")
+	})
 }
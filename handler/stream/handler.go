@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"strings"
+	"sync"
 )
 
 var handlerName = "stream"
@@ -16,11 +17,18 @@ func init() {
 		logger.RegisterHandler(handlerName, func(config map[string]any) (io.WriteCloser, error) {
 			return NewStreamHandlerFromConfig(config)
 		})
+		logger.RegisterHandlerValidator(handlerName, func(config map[string]any) error {
+			return validateConfig(config)
+		})
 	}
 }
 
 type StreamHandler struct {
-	w io.Writer
+	mu sync.Mutex
+	w  io.Writer
+	// filename and mode are set if the stream is a file opened by the handler, see [StreamHandler.Reopen].
+	filename string
+	mode     os.FileMode
 }
 
 func NewStreamHandler(w io.Writer) *StreamHandler {
@@ -29,6 +37,19 @@ func NewStreamHandler(w io.Writer) *StreamHandler {
 	}
 }
 
+// NewFileStreamHandler creates a new stream handler which appends to the given file.
+func NewFileStreamHandler(filename string, mode os.FileMode) (*StreamHandler, error) {
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, mode)
+	if err != nil {
+		return nil, err
+	}
+	return &StreamHandler{
+		w:        file,
+		filename: filename,
+		mode:     mode,
+	}, nil
+}
+
 func NewStreamHandlerFromConfig(config map[string]any) (*StreamHandler, error) {
 	stream, ok := config["stream"]
 	if !ok || stream == nil {
@@ -51,25 +72,80 @@ func NewStreamHandlerFromConfig(config map[string]any) (*StreamHandler, error) {
 				if m, ok := config["mode"]; ok {
 					mode = cast.ToUint32(m)
 				}
-				stream, err := os.OpenFile(strings.TrimLeft(stream, "file://"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, os.FileMode(mode))
-				if err != nil {
-					panic(err)
-				}
-				return NewStreamHandler(stream), nil
+				return NewFileStreamHandler(strings.TrimPrefix(stream, "file://"), os.FileMode(mode))
 			}
 		}
 	}
 	return nil, NewInvalidStreamException()
 }
 
+// validateConfig checks the stream of the config like [NewStreamHandlerFromConfig] without opening the file.
+func validateConfig(config map[string]any) error {
+	stream, ok := config["stream"]
+	if !ok || stream == nil {
+		return nil
+	}
+	if _, ok := stream.(io.Writer); ok {
+		return nil
+	}
+	if stream, ok := stream.(string); ok {
+		switch stream {
+		case "stdout", "stderr", "discard", "null":
+			return nil
+		default:
+			if strings.HasPrefix(stream, "file://") {
+				return nil
+			}
+		}
+	}
+	return NewInvalidStreamException()
+}
+
 func (h *StreamHandler) Write(p []byte) (n int, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 	return h.w.Write(p)
 }
 
-func (h *StreamHandler) Close() error {
+// Reopen reopens the file of a handler created by [NewFileStreamHandler] or with a "file://" stream.
+// The current file is kept if the file cannot be opened. For other streams, it does nothing.
+func (h *StreamHandler) Reopen() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.filename == "" || h.w == nil {
+		return nil
+	}
+	file, err := os.OpenFile(h.filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, h.mode)
+	if err != nil {
+		return err
+	}
+	old := h.w
+	h.w = file
+	if closer, ok := old.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// Sync flushes the stream if it has a Sync method, e.g. [os.File].
+// The standard streams are skipped.
+func (h *StreamHandler) Sync() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 	if h.w == nil {
 		return nil
 	}
+	return logger.SyncHandler(h.w)
+}
+
+// Close closes the stream if it implements [io.Closer].
+// The standard streams are never closed.
+func (h *StreamHandler) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.w == nil || h.w == io.Writer(os.Stdout) || h.w == io.Writer(os.Stderr) {
+		return nil
+	}
 	if closer, ok := h.w.(io.Closer); ok {
 		if err := closer.Close(); err != nil {
 			return err
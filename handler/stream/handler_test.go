@@ -110,3 +110,40 @@ func TestNewStreamHandlerFromConfig(t *testing.T) {
 		assert.Equal(t, "test", string(content))
 	})
 }
+
+func TestStreamHandler_Reopen(t *testing.T) {
+	_ = os.MkdirAll("testdata", 0755)
+	handler, err := NewFileStreamHandler("testdata/reopen.log", 0644)
+	if !assert.NoError(t, err) {
+		assert.FailNow(t, err.Error())
+	}
+	defer func() {
+		_ = handler.Close()
+		_ = os.Remove("testdata/reopen.log")
+		_ = os.Remove("testdata/reopen.log.1")
+	}()
+	_, err = handler.Write([]byte("before"))
+	if !assert.NoError(t, err) {
+		assert.FailNow(t, err.Error())
+	}
+	if err := os.Rename("testdata/reopen.log", "testdata/reopen.log.1"); err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	if err := handler.Reopen(); !assert.NoError(t, err) {
+		assert.FailNow(t, err.Error())
+	}
+	_, err = handler.Write([]byte("after"))
+	if !assert.NoError(t, err) {
+		assert.FailNow(t, err.Error())
+	}
+	content, err := os.ReadFile("testdata/reopen.log.1")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "before", string(content))
+	}
+	content, err = os.ReadFile("testdata/reopen.log")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "after", string(content))
+	}
+
+	assert.NoError(t, NewStreamHandler(io.Discard).Reopen())
+}
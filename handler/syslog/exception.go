@@ -0,0 +1,48 @@
+package syslog
+
+import (
+	"fmt"
+
+	. "github.com/gopi-frame/contract/exception"
+	"github.com/gopi-frame/exception"
+)
+
+type InvalidFacilityException struct {
+	Throwable
+}
+
+func NewInvalidFacilityException(facility string) *InvalidFacilityException {
+	return &InvalidFacilityException{
+		Throwable: exception.New(fmt.Sprintf("invalid facility [%s]", facility)),
+	}
+}
+
+type InvalidFormatException struct {
+	Throwable
+}
+
+func NewInvalidFormatException(format string) *InvalidFormatException {
+	return &InvalidFormatException{
+		Throwable: exception.New(fmt.Sprintf("invalid format [%s]", format)),
+	}
+}
+
+type InvalidFramingException struct {
+	Throwable
+}
+
+func NewInvalidFramingException(framing string) *InvalidFramingException {
+	return &InvalidFramingException{
+		Throwable: exception.New(fmt.Sprintf("invalid framing [%s]", framing)),
+	}
+}
+
+type InvalidNetworkException struct {
+	Throwable
+}
+
+func NewInvalidNetworkException(network string) *InvalidNetworkException {
+	return &InvalidNetworkException{
+		Throwable: exception.New(fmt.Sprintf("invalid network [%s]", network)),
+	}
+}
@@ -0,0 +1,284 @@
+package syslog
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/gopi-frame/env"
+	"github.com/gopi-frame/logger"
+	"github.com/gopi-frame/logger/handler/internal/record"
+)
+
+var handlerName = "syslog"
+
+//goland:noinspection GoBoolExpressions
+func init() {
+	if handlerName != "" {
+		logger.RegisterHandler(handlerName, func(config map[string]any) (io.WriteCloser, error) {
+			return NewSyslogHandlerFromConfig(config)
+		})
+		logger.RegisterHandlerValidator(handlerName, func(config map[string]any) error {
+			_, _, _, err := parseConfig(config)
+			return err
+		})
+	}
+}
+
+// DefaultAddress is the address of the local syslog socket.
+const DefaultAddress = "/dev/log"
+
+// DefaultTimeout is the timeout to connect and write when none is given.
+const DefaultTimeout = 5 * time.Second
+
+// SyslogHandler sends every record as a syslog message, to the local syslog socket
+// or to a syslog server over UDP or TCP.
+//
+// The severity of a message is read from the level of the record, see [WithLevelKey],
+// the trailing newline of the record is removed.
+// The connection is opened on the first write, and opened again when a write fails,
+// the failed message is sent once more on the new connection.
+type SyslogHandler struct {
+	mu            sync.Mutex
+	network       string
+	address       string
+	facility      Facility
+	appName       string
+	hostname      string
+	pid           int
+	messageFormat Format
+	framing       Framing
+	levelKey      string
+	timeout       time.Duration
+	conn          net.Conn
+	connNetwork   string
+	local         bool
+	closed        bool
+	now           func() time.Time // for testing
+}
+
+// NewSyslogHandler creates a new syslog handler for the given network and address.
+//
+// The network is "unixgram" or "unix" for a local socket, "udp" or "tcp" (or their variants) for a server,
+// if it is empty, the local socket at the address is connected as "unixgram" then "unix".
+// The address defaults to [DefaultAddress] for local sockets.
+func NewSyslogHandler(network, address string, opts ...Option) (*SyslogHandler, error) {
+	h := &SyslogHandler{
+		network:  network,
+		address:  address,
+		facility: FacilityUser,
+		appName:  filepath.Base(os.Args[0]),
+		pid:      os.Getpid(),
+		levelKey: "level",
+		timeout:  DefaultTimeout,
+		now:      time.Now,
+	}
+	h.hostname, _ = os.Hostname()
+	switch network {
+	case "", "unix", "unixgram":
+		h.local = true
+		if h.address == "" {
+			h.address = DefaultAddress
+		}
+	case "udp", "udp4", "udp6", "tcp", "tcp4", "tcp6":
+	default:
+		return nil, NewInvalidNetworkException(network)
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	if h.messageFormat == "" {
+		if h.local {
+			h.messageFormat = FormatRFC3164
+		} else {
+			h.messageFormat = FormatRFC5424
+		}
+	}
+	if h.framing == "" {
+		if h.messageFormat == FormatRFC5424 && !h.local {
+			h.framing = FramingOctetCounting
+		} else {
+			h.framing = FramingNewline
+		}
+	}
+	return h, nil
+}
+
+func NewSyslogHandlerFromConfig(config map[string]any) (*SyslogHandler, error) {
+	network, address, opts, err := parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return NewSyslogHandler(network, address, opts...)
+}
+
+// parseConfig decodes the config into the arguments and the options of [NewSyslogHandler].
+func parseConfig(config map[string]any) (string, string, []Option, error) {
+	var cfg struct {
+		Network  string
+		Address  string
+		Facility string
+		AppName  string
+		Hostname string
+		Format   string
+		Framing  string
+		LevelKey string
+		Timeout  time.Duration
+	}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           &cfg,
+		WeaklyTypedInput: true,
+		MatchName: func(mapKey, fieldName string) bool {
+			return strings.EqualFold(mapKey, fieldName) || strings.EqualFold(fieldName, strings.NewReplacer("_", "", "-", "").Replace(mapKey))
+		},
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			env.ExpandStringWithEnvHookFunc(),
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToBasicTypeHookFunc(),
+		),
+	})
+	if err != nil {
+		return "", "", nil, err
+	}
+	if err := decoder.Decode(config); err != nil {
+		return "", "", nil, err
+	}
+	var opts []Option
+	if cfg.Facility != "" {
+		facility, err := ParseFacility(cfg.Facility)
+		if err != nil {
+			return "", "", nil, err
+		}
+		opts = append(opts, WithFacility(facility))
+	}
+	if cfg.AppName != "" {
+		opts = append(opts, WithAppName(cfg.AppName))
+	}
+	if cfg.Hostname != "" {
+		opts = append(opts, WithHostname(cfg.Hostname))
+	}
+	if cfg.Format != "" {
+		format, err := ParseFormat(cfg.Format)
+		if err != nil {
+			return "", "", nil, err
+		}
+		opts = append(opts, WithFormat(format))
+	}
+	if cfg.Framing != "" {
+		framing, err := ParseFraming(cfg.Framing)
+		if err != nil {
+			return "", "", nil, err
+		}
+		opts = append(opts, WithFraming(framing))
+	}
+	if cfg.LevelKey != "" {
+		opts = append(opts, WithLevelKey(cfg.LevelKey))
+	}
+	opts = append(opts, WithTimeout(cfg.Timeout))
+	return cfg.Network, cfg.Address, opts, nil
+}
+
+// dial connects to the syslog socket or server.
+func (h *SyslogHandler) dial() error {
+	networks := []string{h.network}
+	if h.network == "" {
+		networks = []string{"unixgram", "unix"}
+	}
+	var errs []error
+	for _, network := range networks {
+		conn, err := net.DialTimeout(network, h.address, h.timeout)
+		if err == nil {
+			h.conn, h.connNetwork = conn, network
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// stream reports whether the connection is a stream, on which the messages are framed.
+func (h *SyslogHandler) stream() bool {
+	return h.connNetwork == "unix" || strings.HasPrefix(h.connNetwork, "tcp")
+}
+
+// frame returns the message framed for the connection.
+func (h *SyslogHandler) frame(msg []byte) []byte {
+	if !h.stream() {
+		return msg
+	}
+	if h.framing == FramingOctetCounting {
+		return append(strconv.AppendInt(nil, int64(len(msg)), 10), append([]byte{' '}, msg...)...)
+	}
+	return append(msg, '\n')
+}
+
+// send sends the message on the connection, connecting first if needed.
+func (h *SyslogHandler) send(msg []byte) error {
+	if h.conn == nil {
+		if err := h.dial(); err != nil {
+			return err
+		}
+	}
+	if h.timeout > 0 {
+		if err := h.conn.SetWriteDeadline(time.Now().Add(h.timeout)); err != nil {
+			return err
+		}
+	}
+	_, err := h.conn.Write(h.frame(msg))
+	return err
+}
+
+// Write sends the record as a syslog message, reconnecting and sending it again once if it fails.
+func (h *SyslogHandler) Write(p []byte) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return 0, net.ErrClosed
+	}
+	severity := SeverityInfo
+	if level, ok := record.Level(p, h.levelKey); ok {
+		severity = severityOf(level)
+	}
+	msg := h.format(severity, h.now(), bytes.TrimRight(p, "\r\n"), h.local)
+	if err := h.send(msg); err != nil {
+		_ = h.disconnect()
+		if err := h.send(msg); err != nil {
+			_ = h.disconnect()
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// disconnect closes the connection, it is opened again on the next send.
+func (h *SyslogHandler) disconnect() error {
+	if h.conn == nil {
+		return nil
+	}
+	err := h.conn.Close()
+	h.conn = nil
+	return err
+}
+
+// Reopen closes the connection, it is opened again on the next write.
+func (h *SyslogHandler) Reopen() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.disconnect()
+}
+
+// Close closes the connection, writes after Close fail with [net.ErrClosed].
+func (h *SyslogHandler) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	return h.disconnect()
+}
@@ -0,0 +1,167 @@
+package syslog
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gopi-frame/logger/handler/internal/handlertest"
+	"github.com/stretchr/testify/assert"
+)
+
+func clock() time.Time {
+	return time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+}
+
+func TestSyslogHandler(t *testing.T) {
+	pid := strconv.Itoa(os.Getpid())
+
+	t.Run("udp", func(t *testing.T) {
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+		h := handlertest.New(t, NewSyslogHandlerFromConfig, map[string]any{
+			"network":  "udp",
+			"address":  conn.LocalAddr().String(),
+			"facility": "local0",
+			"app-name": "app",
+			"hostname": "host",
+		})
+		h.now = clock
+		handlertest.Write(t, h, `{"level":"error","msg":"a"}`+"\n")
+		handlertest.Write(t, h, "level=DEBUG msg=b\n")
+		buf := make([]byte, 1024)
+		for _, expected := range []string{
+			"<131>1 2026-10-17T12:00:00.000000Z host app " + pid + ` - - {"level":"error","msg":"a"}`,
+			"<135>1 2026-10-17T12:00:00.000000Z host app " + pid + " - - level=DEBUG msg=b",
+		} {
+			n, _, err := conn.ReadFrom(buf)
+			if !assert.NoError(t, err) {
+				assert.FailNow(t, err.Error())
+			}
+			assert.Equal(t, expected, string(buf[:n]))
+		}
+		assert.NoError(t, h.Close())
+		_, err = h.Write([]byte("c"))
+		assert.ErrorIs(t, err, net.ErrClosed)
+	})
+
+	t.Run("tcp", func(t *testing.T) {
+		msg := "<14>1 2026-10-17T12:00:00.000000Z host app " + pid + " - - a"
+		for framing, expected := range map[string]string{
+			"octet-counting": strconv.Itoa(len(msg)) + " " + msg,
+			"newline":        msg + "\n",
+		} {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if !assert.NoError(t, err) {
+				assert.FailNow(t, err.Error())
+			}
+			h := handlertest.New(t, NewSyslogHandlerFromConfig, map[string]any{
+				"network":  "tcp",
+				"address":  listener.Addr().String(),
+				"appName":  "app",
+				"hostname": "host",
+				"framing":  framing,
+			})
+			h.now = clock
+			handlertest.Write(t, h, "a\n")
+			conn, err := listener.Accept()
+			if !assert.NoError(t, err) {
+				assert.FailNow(t, err.Error())
+			}
+			// the failed write is sent again on a new connection
+			_ = h.conn.Close()
+			handlertest.Write(t, h, "a\n")
+			reconnected, err := listener.Accept()
+			if !assert.NoError(t, err) {
+				assert.FailNow(t, err.Error())
+			}
+			assert.NoError(t, h.Close())
+			for _, c := range []net.Conn{conn, reconnected} {
+				buf := make([]byte, len(expected))
+				_, err := io.ReadFull(bufio.NewReader(c), buf)
+				assert.NoError(t, err)
+				assert.Equal(t, expected, string(buf), framing)
+				_ = c.Close()
+			}
+			_ = listener.Close()
+		}
+	})
+
+	t.Run("local", func(t *testing.T) {
+		dir, err := os.MkdirTemp("", "syslog")
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		defer func() {
+			_ = os.RemoveAll(dir)
+		}()
+		address := filepath.Join(dir, "log")
+		conn, err := net.ListenPacket("unixgram", address)
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+		h := handlertest.New(t, NewSyslogHandlerFromConfig, map[string]any{
+			"address":  address,
+			"app_name": "app",
+		})
+		h.now = clock
+		handlertest.Write(t, h, "level=WARN msg=a\n")
+		buf := make([]byte, 1024)
+		n, _, err := conn.ReadFrom(buf)
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		assert.Equal(t, "<12>Oct 17 12:00:00 app["+pid+"]: level=WARN msg=a", string(buf[:n]))
+		assert.NoError(t, h.Close())
+	})
+
+	t.Run("unavailable", func(t *testing.T) {
+		h := handlertest.New(t, NewSyslogHandlerFromConfig, map[string]any{"address": filepath.Join(t.TempDir(), "missing")})
+		h.now = clock
+		_, err := h.Write([]byte("a"))
+		assert.Error(t, err)
+	})
+}
+
+func TestNewSyslogHandlerFromConfig(t *testing.T) {
+	h, err := NewSyslogHandlerFromConfig(map[string]any{})
+	if !assert.NoError(t, err) {
+		assert.FailNow(t, err.Error())
+	}
+	assert.Equal(t, DefaultAddress, h.address)
+	assert.Equal(t, FacilityUser, h.facility)
+	assert.Equal(t, FormatRFC3164, h.messageFormat)
+	assert.Equal(t, FramingNewline, h.framing)
+
+	h, err = NewSyslogHandlerFromConfig(map[string]any{"network": "tcp", "address": "localhost:514", "facility": 23, "timeout": "1s"})
+	if !assert.NoError(t, err) {
+		assert.FailNow(t, err.Error())
+	}
+	assert.Equal(t, FacilityLocal7, h.facility)
+	assert.Equal(t, FormatRFC5424, h.messageFormat)
+	assert.Equal(t, FramingOctetCounting, h.framing)
+	assert.Equal(t, time.Second, h.timeout)
+
+	for config, expected := range map[string]any{
+		"network":  new(InvalidNetworkException),
+		"facility": new(InvalidFacilityException),
+		"format":   new(InvalidFormatException),
+		"framing":  new(InvalidFramingException),
+	} {
+		_, err := NewSyslogHandlerFromConfig(map[string]any{config: "invalid"})
+		assert.IsType(t, expected, err, config)
+	}
+}
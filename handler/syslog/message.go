@@ -0,0 +1,189 @@
+package syslog
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gopi-frame/logger"
+)
+
+// Facility is the syslog facility of the messages.
+type Facility int
+
+const (
+	FacilityKern Facility = iota
+	FacilityUser
+	FacilityMail
+	FacilityDaemon
+	FacilityAuth
+	FacilitySyslog
+	FacilityLpr
+	FacilityNews
+	FacilityUucp
+	FacilityCron
+	FacilityAuthPriv
+	FacilityFtp
+	FacilityLocal0 Facility = iota + 4
+	FacilityLocal1
+	FacilityLocal2
+	FacilityLocal3
+	FacilityLocal4
+	FacilityLocal5
+	FacilityLocal6
+	FacilityLocal7
+)
+
+var facilities = map[string]Facility{
+	"kern":     FacilityKern,
+	"user":     FacilityUser,
+	"mail":     FacilityMail,
+	"daemon":   FacilityDaemon,
+	"auth":     FacilityAuth,
+	"syslog":   FacilitySyslog,
+	"lpr":      FacilityLpr,
+	"news":     FacilityNews,
+	"uucp":     FacilityUucp,
+	"cron":     FacilityCron,
+	"authpriv": FacilityAuthPriv,
+	"ftp":      FacilityFtp,
+	"local0":   FacilityLocal0,
+	"local1":   FacilityLocal1,
+	"local2":   FacilityLocal2,
+	"local3":   FacilityLocal3,
+	"local4":   FacilityLocal4,
+	"local5":   FacilityLocal5,
+	"local6":   FacilityLocal6,
+	"local7":   FacilityLocal7,
+}
+
+// ParseFacility parses a facility by its name case-insensitively, e.g. "local0", or by its code, e.g. "16".
+func ParseFacility(s string) (Facility, error) {
+	if facility, ok := facilities[strings.ToLower(s)]; ok {
+		return facility, nil
+	}
+	if code, err := strconv.Atoi(s); err == nil && code >= 0 && code <= int(FacilityLocal7) {
+		return Facility(code), nil
+	}
+	return 0, NewInvalidFacilityException(s)
+}
+
+// Severity is the syslog severity of a message.
+type Severity int
+
+const (
+	SeverityEmergency Severity = iota
+	SeverityAlert
+	SeverityCritical
+	SeverityError
+	SeverityWarning
+	SeverityNotice
+	SeverityInfo
+	SeverityDebug
+)
+
+// severityOf returns the severity of a logger level.
+func severityOf(level logger.Level) Severity {
+	switch level {
+	case logger.LevelDebug:
+		return SeverityDebug
+	case logger.LevelWarn:
+		return SeverityWarning
+	case logger.LevelError:
+		return SeverityError
+	case logger.LevelPanic:
+		return SeverityCritical
+	case logger.LevelFatal:
+		return SeverityAlert
+	default:
+		return SeverityInfo
+	}
+}
+
+// Format is the format of the syslog messages.
+type Format string
+
+const (
+	// FormatRFC5424 is the format of RFC 5424, e.g. <134>1 2026-10-17T12:00:00.000000Z host app 42 - - message.
+	FormatRFC5424 Format = "rfc5424"
+	// FormatRFC3164 is the BSD format of RFC 3164, e.g. <134>Oct 17 12:00:00 host app[42]: message.
+	// The hostname is omitted on local sockets, like the syslog(3) function does.
+	FormatRFC3164 Format = "rfc3164"
+)
+
+// ParseFormat parses a format case-insensitively.
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(s)) {
+	case FormatRFC5424:
+		return FormatRFC5424, nil
+	case FormatRFC3164:
+		return FormatRFC3164, nil
+	default:
+		return "", NewInvalidFormatException(s)
+	}
+}
+
+// Framing is how the messages are delimited on a stream connection, see RFC 6587.
+// Messages sent in datagrams are not framed.
+type Framing string
+
+const (
+	// FramingOctetCounting prefixes each message with its length and a space.
+	FramingOctetCounting Framing = "octet-counting"
+	// FramingNewline terminates each message with a newline.
+	FramingNewline Framing = "newline"
+)
+
+// ParseFraming parses a framing case-insensitively, ignoring dashes and underscores.
+func ParseFraming(s string) (Framing, error) {
+	switch strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(s)) {
+	case "octetcounting":
+		return FramingOctetCounting, nil
+	case "newline":
+		return FramingNewline, nil
+	default:
+		return "", NewInvalidFramingException(s)
+	}
+}
+
+// nilValue is the value of the empty fields in RFC 5424.
+const nilValue = "-"
+
+// format formats a message with the given severity, local tells whether it is sent to a local socket.
+func (h *SyslogHandler) format(severity Severity, t time.Time, msg []byte, local bool) []byte {
+	var b []byte
+	b = append(b, '<')
+	b = strconv.AppendInt(b, int64(int(h.facility)*8+int(severity)), 10)
+	b = append(b, '>')
+	if h.messageFormat == FormatRFC3164 {
+		b = t.AppendFormat(b, time.Stamp)
+		b = append(b, ' ')
+		if !local {
+			b = append(b, orNil(h.hostname)...)
+			b = append(b, ' ')
+		}
+		b = append(b, h.appName...)
+		b = append(b, '[')
+		b = strconv.AppendInt(b, int64(h.pid), 10)
+		b = append(b, "]: "...)
+		return append(b, msg...)
+	}
+	b = append(b, "1 "...)
+	b = t.UTC().AppendFormat(b, "2006-01-02T15:04:05.000000Z07:00")
+	b = append(b, ' ')
+	b = append(b, orNil(h.hostname)...)
+	b = append(b, ' ')
+	b = append(b, orNil(h.appName)...)
+	b = append(b, ' ')
+	b = strconv.AppendInt(b, int64(h.pid), 10)
+	// no message id and structured data
+	b = append(b, " - - "...)
+	return append(b, msg...)
+}
+
+func orNil(s string) string {
+	if s == "" {
+		return nilValue
+	}
+	return s
+}
@@ -0,0 +1,58 @@
+package syslog
+
+import "time"
+
+type Option func(h *SyslogHandler)
+
+// WithFacility sets the facility of the messages, [FacilityUser] by default.
+func WithFacility(facility Facility) Option {
+	return func(h *SyslogHandler) {
+		h.facility = facility
+	}
+}
+
+// WithAppName sets the application name of the messages, the name of the executable by default.
+func WithAppName(appName string) Option {
+	return func(h *SyslogHandler) {
+		h.appName = appName
+	}
+}
+
+// WithHostname sets the hostname of the messages, the hostname of the system by default.
+func WithHostname(hostname string) Option {
+	return func(h *SyslogHandler) {
+		h.hostname = hostname
+	}
+}
+
+// WithFormat sets the format of the messages,
+// [FormatRFC3164] by default for local sockets and [FormatRFC5424] for servers.
+func WithFormat(format Format) Option {
+	return func(h *SyslogHandler) {
+		h.messageFormat = format
+	}
+}
+
+// WithFraming sets the framing of the messages on stream connections,
+// [FramingOctetCounting] by default for [FormatRFC5424] over TCP and [FramingNewline] otherwise.
+func WithFraming(framing Framing) Option {
+	return func(h *SyslogHandler) {
+		h.framing = framing
+	}
+}
+
+// WithLevelKey sets the key of the level in the records, which gives the severity of the messages.
+func WithLevelKey(key string) Option {
+	return func(h *SyslogHandler) {
+		h.levelKey = key
+	}
+}
+
+// WithTimeout sets the timeout to connect and write, it is ignored if it is not positive.
+func WithTimeout(timeout time.Duration) Option {
+	return func(h *SyslogHandler) {
+		if timeout > 0 {
+			h.timeout = timeout
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type nopHandler struct{}
+
+func (nopHandler) Write(p []byte) (int, error) { return len(p), nil }
+func (nopHandler) Close() error                { return nil }
+
+func init() {
+	RegisterHandler("nop", func(config map[string]any) (io.WriteCloser, error) {
+		return nopHandler{}, nil
+	})
+	RegisterHandler("validated", func(config map[string]any) (io.WriteCloser, error) {
+		return nopHandler{}, nil
+	})
+	RegisterHandlerValidator("validated", func(config map[string]any) error {
+		if _, ok := config["filename"]; !ok {
+			return errors.New("filename is missing")
+		}
+		return nil
+	})
+}
+
+func TestValidateHandler(t *testing.T) {
+	var unknown *UnknownHandlerException
+	assert.True(t, errors.As(ValidateHandler("unknown", nil), &unknown))
+	assert.NoError(t, ValidateHandler("nop", nil))
+	assert.EqualError(t, ValidateHandler("validated", nil), "filename is missing")
+	assert.NoError(t, ValidateHandler("validated", map[string]any{"filename": "app.log"}))
+}
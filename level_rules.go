@@ -0,0 +1,300 @@
+package logger
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gopi-frame/contract/logger"
+)
+
+// LevelRules is a table of levels by logger name, the level type L is defined by the driver.
+//
+// Patterns are matched against the full name of a logger:
+//   - "*" matches every logger, including the unnamed one.
+//   - "billing.*" matches "billing" and its descendants, e.g. "billing.invoice".
+//   - "gorm" matches only the logger named "gorm".
+//
+// The pattern with the longest matching prefix wins,
+// an exact pattern wins over a wildcard pattern with the same prefix.
+//
+// A rule and the atomic level of a logger are combined by [NameLevel], the most recent change wins:
+// the rules given at creation override the level of the logger,
+// a rule set later overrides an earlier [LevelStamp.Touch] for the names it matches, and vice versa.
+type LevelRules[L any] struct {
+	mu    sync.RWMutex
+	rules map[string]levelRule[L]
+	// version is incremented on every change, so the cached levels of [NameLevel] are resolved again.
+	version atomic.Uint64
+}
+
+// levelRule is a level with the sequence of its last change, 0 for the rules given at creation.
+type levelRule[L any] struct {
+	level L
+	seq   uint64
+}
+
+// levelClock orders the changes of the rules and of the atomic levels.
+var levelClock atomic.Uint64
+
+// NewLevelRules creates a new level rule table from the given rules.
+func NewLevelRules[L any](rules map[string]L) *LevelRules[L] {
+	r := &LevelRules[L]{
+		rules: make(map[string]levelRule[L], len(rules)),
+	}
+	for pattern, level := range rules {
+		r.rules[pattern] = levelRule[L]{level: level}
+	}
+	return r
+}
+
+// Match returns the level of the rule matching the given name.
+// It returns false if no rule matches or r is nil.
+func (r *LevelRules[L]) Match(name string) (L, bool) {
+	rule, ok := r.match(name)
+	return rule.level, ok
+}
+
+// match returns the rule matching the given name.
+func (r *LevelRules[L]) match(name string) (levelRule[L], bool) {
+	var rule levelRule[L]
+	if r == nil {
+		return rule, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	best := -1
+	for pattern, l := range r.rules {
+		if score := matchLevelPattern(pattern, name); score > best {
+			best = score
+			rule = l
+		}
+	}
+	return rule, best >= 0
+}
+
+// Set sets the level of the given pattern.
+func (r *LevelRules[L]) Set(pattern string, level L) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rules[pattern] = levelRule[L]{level: level, seq: levelClock.Add(1)}
+	r.version.Add(1)
+}
+
+// Remove removes the rule of the given pattern.
+func (r *LevelRules[L]) Remove(pattern string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.rules, pattern)
+	r.version.Add(1)
+}
+
+// Rules returns a copy of the rules.
+func (r *LevelRules[L]) Rules() map[string]L {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rules := make(map[string]L, len(r.rules))
+	for pattern, rule := range r.rules {
+		rules[pattern] = rule.level
+	}
+	return rules
+}
+
+// LevelStamp records the last change of an atomic level, to be combined with the level rules by [NameLevel].
+// The zero value is an atomic level never changed since its creation.
+type LevelStamp struct {
+	seq atomic.Uint64
+}
+
+// Touch records that the atomic level has just changed, it overrides the rules set before.
+func (s *LevelStamp) Touch() {
+	s.seq.Store(levelClock.Add(1))
+}
+
+// NameLevel is the level rule of a named logger, resolved once when the rules change.
+type NameLevel[L any] struct {
+	rules *LevelRules[L]
+	name  string
+	stamp *LevelStamp
+	cache atomic.Pointer[nameLevelCache[L]]
+}
+
+type nameLevelCache[L any] struct {
+	version uint64
+	rule    levelRule[L]
+	ok      bool
+}
+
+// NewNameLevel creates the level rule of the logger with the given name,
+// combined with the atomic level whose changes are recorded by stamp.
+func NewNameLevel[L any](rules *LevelRules[L], name string, stamp *LevelStamp) *NameLevel[L] {
+	return &NameLevel[L]{rules: rules, name: name, stamp: stamp}
+}
+
+// Level returns the level of the rule matching the name,
+// it returns false if no rule matches or the atomic level has changed since the rule was set,
+// then the atomic level applies.
+func (n *NameLevel[L]) Level() (L, bool) {
+	var version uint64
+	if n.rules != nil {
+		version = n.rules.version.Load()
+	}
+	c := n.cache.Load()
+	if c == nil || c.version != version {
+		rule, ok := n.rules.match(n.name)
+		c = &nameLevelCache[L]{version: version, rule: rule, ok: ok}
+		n.cache.Store(c)
+	}
+	if !c.ok || (n.stamp != nil && c.rule.seq < n.stamp.seq.Load()) {
+		var level L
+		return level, false
+	}
+	return c.rule.level, true
+}
+
+// matchLevelPattern returns the score of pattern for name, or -1 if it does not match.
+// Longer prefixes score higher, and an exact pattern scores higher than a wildcard one with the same prefix.
+func matchLevelPattern(pattern, name string) int {
+	if pattern == "*" {
+		return 0
+	}
+	if prefix, ok := strings.CutSuffix(pattern, ".*"); ok {
+		if name == prefix || strings.HasPrefix(name, prefix+".") {
+			return 2*len(prefix) + 1
+		}
+		return -1
+	}
+	if name == pattern {
+		return 2*len(pattern) + 2
+	}
+	return -1
+}
+
+// NameLevels is a level rule table by logger name which can be changed at runtime,
+// see [LevelRules] for the matching rules.
+type NameLevels interface {
+	// Levels returns a copy of the rules.
+	Levels() map[string]Level
+
+	// Match returns the level of the rule matching the given logger name,
+	// it returns false if no rule matches or the atomic level of the logger changed after the rule.
+	Match(name string) (Level, bool)
+
+	// SetLevel sets the level of the given pattern.
+	SetLevel(pattern string, level Level)
+
+	// RemoveLevel removes the rule of the given pattern.
+	RemoveLevel(pattern string)
+}
+
+// NameLevelLogger is a logger whose levels by logger name can be changed at runtime.
+type NameLevelLogger interface {
+	logger.Logger
+
+	// NameLevels returns the level rule table shared by the logger and the loggers derived from it.
+	NameLevels() NameLevels
+}
+
+// GetNameLevels returns the level rule table of l.
+// If l does not implement [NameLevelLogger], it returns nil.
+func GetNameLevels(l logger.Logger) NameLevels {
+	if nl, ok := l.(NameLevelLogger); ok {
+		return nl.NameLevels()
+	}
+	return nil
+}
+
+// NewNameLevels adapts the level rule table of a driver to [NameLevels],
+// by converting levels with the given functions.
+// Levels which cannot be converted by to are ignored by SetLevel.
+// The stamp records the changes of the atomic level of the logger, it may be nil.
+func NewNameLevels[L any](rules *LevelRules[L], stamp *LevelStamp, to func(Level) (L, bool), from func(L) Level) NameLevels {
+	return &nameLevels[L]{rules: rules, stamp: stamp, to: to, from: from}
+}
+
+type nameLevels[L any] struct {
+	rules *LevelRules[L]
+	stamp *LevelStamp
+	to    func(Level) (L, bool)
+	from  func(L) Level
+}
+
+func (n *nameLevels[L]) Levels() map[string]Level {
+	rules := n.rules.Rules()
+	levels := make(map[string]Level, len(rules))
+	for pattern, level := range rules {
+		levels[pattern] = n.from(level)
+	}
+	return levels
+}
+
+func (n *nameLevels[L]) Match(name string) (Level, bool) {
+	rule, ok := n.rules.match(name)
+	if !ok || (n.stamp != nil && rule.seq < n.stamp.seq.Load()) {
+		return 0, false
+	}
+	return n.from(rule.level), true
+}
+
+// activeLevels returns the rules not overridden by a later change of the atomic level.
+func (n *nameLevels[L]) activeLevels() map[string]Level {
+	n.rules.mu.RLock()
+	defer n.rules.mu.RUnlock()
+	levels := make(map[string]Level, len(n.rules.rules))
+	for pattern, rule := range n.rules.rules {
+		if n.stamp == nil || rule.seq >= n.stamp.seq.Load() {
+			levels[pattern] = n.from(rule.level)
+		}
+	}
+	return levels
+}
+
+func (n *nameLevels[L]) SetLevel(pattern string, level Level) {
+	if lvl, ok := n.to(level); ok {
+		n.rules.Set(pattern, lvl)
+	}
+}
+
+func (n *nameLevels[L]) RemoveLevel(pattern string) {
+	n.rules.Remove(pattern)
+}
+
+// stackNameLevels is the level rule table of a [StackLogger].
+// It changes the rules of all channels, and reports the rules of the first channel defining a pattern.
+type stackNameLevels []NameLevels
+
+func (s stackNameLevels) Levels() map[string]Level {
+	levels := make(map[string]Level)
+	for _, l := range s {
+		for pattern, level := range l.Levels() {
+			if _, ok := levels[pattern]; !ok {
+				levels[pattern] = level
+			}
+		}
+	}
+	return levels
+}
+
+func (s stackNameLevels) Match(name string) (Level, bool) {
+	for _, l := range s {
+		if level, ok := l.Match(name); ok {
+			return level, true
+		}
+	}
+	return 0, false
+}
+
+func (s stackNameLevels) SetLevel(pattern string, level Level) {
+	for _, l := range s {
+		l.SetLevel(pattern, level)
+	}
+}
+
+func (s stackNameLevels) RemoveLevel(pattern string) {
+	for _, l := range s {
+		l.RemoveLevel(pattern)
+	}
+}
@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevelRules_Match(t *testing.T) {
+	rules := NewLevelRules(map[string]Level{
+		"*":               LevelWarn,
+		"billing.*":       LevelDebug,
+		"billing.invoice": LevelError,
+		"gorm":            LevelError,
+	})
+	cases := []struct {
+		name  string
+		level Level
+	}{
+		{"", LevelWarn},
+		{"http", LevelWarn},
+		{"billing", LevelDebug},
+		{"billing.payment", LevelDebug},
+		{"billing.invoice", LevelError},
+		{"billing.invoice.pdf", LevelDebug},
+		{"billingx", LevelWarn},
+		{"gorm", LevelError},
+		{"gorm.query", LevelWarn},
+	}
+	for _, c := range cases {
+		level, ok := rules.Match(c.name)
+		assert.True(t, ok, c.name)
+		assert.Equal(t, c.level, level, c.name)
+	}
+
+	t.Run("without wildcard", func(t *testing.T) {
+		rules := NewLevelRules(map[string]Level{"billing.*": LevelDebug})
+		_, ok := rules.Match("http")
+		assert.False(t, ok)
+		rules.Set("*", LevelInfo)
+		level, ok := rules.Match("http")
+		assert.True(t, ok)
+		assert.Equal(t, LevelInfo, level)
+		rules.Remove("billing.*")
+		level, _ = rules.Match("billing")
+		assert.Equal(t, LevelInfo, level)
+		assert.Equal(t, map[string]Level{"*": LevelInfo}, rules.Rules())
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		var rules *LevelRules[Level]
+		_, ok := rules.Match("billing")
+		assert.False(t, ok)
+	})
+}
+
+func TestNameLevel(t *testing.T) {
+	rules := NewLevelRules(map[string]Level{"*": LevelWarn, "gorm": LevelError})
+	stamp := new(LevelStamp)
+	http := NewNameLevel(rules, "http", stamp)
+	gorm := NewNameLevel(rules, "gorm", stamp)
+
+	level, ok := http.Level()
+	assert.True(t, ok)
+	assert.Equal(t, LevelWarn, level)
+
+	stamp.Touch()
+	_, ok = http.Level()
+	assert.False(t, ok, "the atomic level changed after the rule")
+	_, ok = gorm.Level()
+	assert.False(t, ok, "the atomic level changed after the rule")
+
+	rules.Set("gorm", LevelFatal)
+	_, ok = http.Level()
+	assert.False(t, ok)
+	level, ok = gorm.Level()
+	assert.True(t, ok, "the rule changed after the atomic level")
+	assert.Equal(t, LevelFatal, level)
+
+	rules.Remove("gorm")
+	_, ok = gorm.Level()
+	assert.False(t, ok, "the remaining rule is older than the atomic level")
+
+	rules.Set("*", LevelInfo)
+	level, ok = gorm.Level()
+	assert.True(t, ok)
+	assert.Equal(t, LevelInfo, level)
+}
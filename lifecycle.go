@@ -0,0 +1,170 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gopi-frame/contract/logger"
+)
+
+// SyncHandler flushes the buffered data of w if it has a Sync method.
+// The standard streams are skipped, syncing them fails on terminals and pipes.
+func SyncHandler(w io.Writer) error {
+	if isStdStream(w) {
+		return nil
+	}
+	if s, ok := w.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// CloseHandler closes w if it implements [io.Closer].
+// The standard streams are never closed.
+func CloseHandler(w io.Writer) error {
+	if isStdStream(w) {
+		return nil
+	}
+	if c, ok := w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// ShutdownHandler flushes and closes w, waiting until ctx is done.
+// If w has a Shutdown(context.Context) error method, e.g. an asynchronous handler draining its queue, it is called,
+// otherwise w is synced and closed in the background, and the error of ctx is returned if it is done first.
+func ShutdownHandler(ctx context.Context, w io.Writer) error {
+	if s, ok := w.(interface {
+		Shutdown(ctx context.Context) error
+	}); ok && !isStdStream(w) {
+		return s.Shutdown(ctx)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- errors.Join(SyncHandler(w), CloseHandler(w))
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func isStdStream(w io.Writer) bool {
+	return w == io.Writer(os.Stdout) || w == io.Writer(os.Stderr)
+}
+
+// SharedHandler is a handler shared by a logger and the loggers derived from it.
+// It is closed at most once, no matter how many of the loggers are closed,
+// and it is neither synced nor reopened after being closed.
+type SharedHandler struct {
+	io.Writer
+
+	once   sync.Once
+	closed atomic.Bool
+}
+
+// NewSharedHandler wraps w to be shared by derived loggers.
+func NewSharedHandler(w io.Writer) *SharedHandler {
+	return &SharedHandler{Writer: w}
+}
+
+// Sync flushes the buffered data of the handler, see [SyncHandler].
+func (h *SharedHandler) Sync() error {
+	if h.closed.Load() {
+		return nil
+	}
+	return SyncHandler(h.Writer)
+}
+
+// Reopen reopens the handler, see [ReopenHandler].
+func (h *SharedHandler) Reopen() error {
+	if h.closed.Load() {
+		return nil
+	}
+	return ReopenHandler(h.Writer)
+}
+
+// Close syncs and closes the handler on the first call, later calls do nothing.
+func (h *SharedHandler) Close() error {
+	var err error
+	h.once.Do(func() {
+		err = errors.Join(SyncHandler(h.Writer), CloseHandler(h.Writer))
+		h.closed.Store(true)
+	})
+	return err
+}
+
+// Shutdown flushes and closes the handler on the first call waiting until ctx is done, see [ShutdownHandler].
+// Later calls and calls of Close do nothing.
+func (h *SharedHandler) Shutdown(ctx context.Context) error {
+	var err error
+	h.once.Do(func() {
+		err = ShutdownHandler(ctx, h.Writer)
+		h.closed.Store(true)
+	})
+	return err
+}
+
+// CloseableLogger is a logger whose handlers can be flushed and closed.
+type CloseableLogger interface {
+	logger.Logger
+
+	// Sync flushes the buffered data of the handlers.
+	Sync() error
+
+	// Close flushes and closes the handlers.
+	// The handlers are shared by the loggers derived from the same root, closing any of them closes all.
+	Close() error
+}
+
+// Sync flushes the handlers of l.
+// If l does not implement [CloseableLogger], it does nothing.
+func Sync(l logger.Logger) error {
+	if cl, ok := l.(CloseableLogger); ok {
+		return cl.Sync()
+	}
+	return nil
+}
+
+// Close flushes and closes the handlers of l.
+// If l does not implement [CloseableLogger], it does nothing.
+func Close(l logger.Logger) error {
+	if cl, ok := l.(CloseableLogger); ok {
+		return cl.Close()
+	}
+	return nil
+}
+
+// ShutdownLogger is a logger whose handlers can be drained until a deadline.
+type ShutdownLogger interface {
+	logger.Logger
+
+	// Shutdown flushes and closes the handlers, waiting for buffered and in-flight writes until ctx is done.
+	Shutdown(ctx context.Context) error
+}
+
+// Shutdown flushes and closes the handlers of l, waiting until ctx is done.
+// If l does not implement [ShutdownLogger], it is closed in the background by [Close],
+// and the error of ctx is returned if it is done first.
+func Shutdown(ctx context.Context, l logger.Logger) error {
+	if sl, ok := l.(ShutdownLogger); ok {
+		return sl.Shutdown(ctx)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- Close(l)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,117 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockHandler struct {
+	bytes.Buffer
+	synced int
+	closed int
+}
+
+func (h *mockHandler) Sync() error {
+	h.synced++
+	return nil
+}
+
+func (h *mockHandler) Close() error {
+	h.closed++
+	return errors.New("closed")
+}
+
+// drainHandler blocks Shutdown until drained is closed or ctx is done.
+type drainHandler struct {
+	bytes.Buffer
+	drained chan struct{}
+}
+
+func (h *drainHandler) Shutdown(ctx context.Context) error {
+	select {
+	case <-h.drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestSharedHandler(t *testing.T) {
+	t.Run("close once", func(t *testing.T) {
+		w := new(mockHandler)
+		h := NewSharedHandler(w)
+		_, err := h.Write([]byte("test"))
+		assert.NoError(t, err)
+		assert.NoError(t, h.Sync())
+		assert.EqualError(t, h.Close(), "closed")
+		assert.NoError(t, h.Close())
+		assert.NoError(t, h.Sync())
+		assert.Equal(t, "test", w.String())
+		assert.Equal(t, 2, w.synced)
+		assert.Equal(t, 1, w.closed)
+	})
+
+	t.Run("shutdown", func(t *testing.T) {
+		w := &drainHandler{drained: make(chan struct{})}
+		h := NewSharedHandler(w)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, h.Shutdown(ctx), context.DeadlineExceeded)
+		assert.NoError(t, h.Close())
+		assert.NoError(t, h.Shutdown(context.Background()))
+	})
+
+	t.Run("standard streams", func(t *testing.T) {
+		assert.NoError(t, NewSharedHandler(os.Stdout).Close())
+		assert.NoError(t, NewSharedHandler(os.Stderr).Close())
+		_, err := os.Stdout.Stat()
+		assert.NoError(t, err)
+	})
+}
+
+func TestLoggerManager_Close(t *testing.T) {
+	app := &mockLogger{}
+	audit := &mockLogger{closeErr: errors.New("disk full")}
+	m := NewLoggerManager()
+	m.SetChannel("app", app)
+	m.SetChannel("default", app)
+	m.SetChannel("audit", audit)
+	m.SetChannel("deferred", NewDeferLogger("mock", nil))
+
+	assert.EqualError(t, m.Sync(), "channel [audit]: disk full")
+	assert.Equal(t, 2, app.synced)
+	assert.Equal(t, 1, audit.synced)
+
+	assert.EqualError(t, m.Close(), "channel [audit]: disk full")
+	assert.Equal(t, 1, app.closed)
+	assert.Equal(t, 1, audit.closed)
+	assert.Nil(t, m.GetChannel("deferred").(*DeferLogger).Logger)
+}
+
+func TestLoggerManager_Shutdown(t *testing.T) {
+	app := &mockLogger{}
+	audit := &mockLogger{closeErr: errors.New("disk full")}
+	slow := &mockLogger{closing: make(chan struct{})}
+	defer close(slow.closing)
+	m := NewLoggerManager()
+	m.SetChannel("app", app)
+	m.SetChannel("default", app)
+	m.SetChannel("audit", audit)
+	m.SetChannel("slow", slow)
+	m.SetChannel("deferred", NewDeferLogger("mock", nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	failed, err := m.Shutdown(ctx)
+	assert.Equal(t, []string{"audit", "slow"}, failed)
+	assert.ErrorContains(t, err, "channel [audit]: disk full")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, app.closed)
+	assert.Equal(t, 1, audit.closed)
+}
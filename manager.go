@@ -1,6 +1,12 @@
 package logger
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
 	"github.com/gopi-frame/collection/kv"
 	"github.com/gopi-frame/contract/logger"
 )
@@ -19,6 +25,36 @@ func NewLoggerManager() *LoggerManager {
 	}
 }
 
+// NewLoggerManagerFromConfig creates a new logger manager from the given configuration.
+//
+// The configuration contains the name of the default channel and a map of channels,
+// each channel is configured with a registered driver name and the options passed to it:
+//
+//	{
+//		"default": "app",
+//		"channels": {
+//			"app": {"driver": "zap", "options": {"level": "info"}},
+//			"audit": {"driver": "slog", "options": {"handler": "file"}},
+//		},
+//	}
+//
+// Channels are opened lazily by [DeferLogger] on the first use,
+// their options are checked beforehand by the drivers which implement [Validator].
+// All misconfigured channels are reported together in the returned error.
+func NewLoggerManagerFromConfig(config map[string]any) (*LoggerManager, error) {
+	cfg, err := UnmarshalManagerConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	m := NewLoggerManager()
+	m.SetDefault(cfg.Default)
+	for _, name := range sortedKeys(cfg.Channels) {
+		channel := cfg.Channels[name]
+		m.SetChannel(name, NewDeferLogger(channel.Driver, channel.Options))
+	}
+	return m, nil
+}
+
 func (m *LoggerManager) init() {
 	if m.Logger == nil {
 		m.Logger = m.getConnectedChannel(m.defaultChannel)
@@ -74,6 +110,24 @@ func (m *LoggerManager) GetChannels() map[string]logger.Logger {
 	return m.channels.ToMap()
 }
 
+// GetChannelLevel returns the atomic level of the channel with the given name.
+// It returns nil if the channel does not exist or does not support [AtomicLevel].
+func (m *LoggerManager) GetChannelLevel(name string) AtomicLevel {
+	if channel := m.getConnectedChannel(name); channel != nil {
+		return GetAtomicLevel(channel)
+	}
+	return nil
+}
+
+// GetChannelNameLevels returns the level rule table by logger name of the channel with the given name.
+// It returns nil if the channel does not exist or does not support [NameLevels].
+func (m *LoggerManager) GetChannelNameLevels(name string) NameLevels {
+	if channel := m.getConnectedChannel(name); channel != nil {
+		return GetNameLevels(channel)
+	}
+	return nil
+}
+
 // GetChannelBundle returns a stack of the given channels.
 // If the channel is not found, it skips the channel.
 func (m *LoggerManager) GetChannelBundle(names ...string) logger.Logger {
@@ -88,6 +142,123 @@ func (m *LoggerManager) GetChannelBundle(names ...string) logger.Logger {
 	return NewStackLogger(channels...)
 }
 
+// With returns a child logger of the default channel with the given fields attached.
+func (m *LoggerManager) With(fields ...Field) logger.Logger {
+	m.init()
+	return With(m.Logger, fields...)
+}
+
+// WithFields returns a child logger of the default channel with the given fields attached.
+func (m *LoggerManager) WithFields(fields map[string]any) logger.Logger {
+	m.init()
+	return With(m.Logger, Fields(fields)...)
+}
+
+// Named returns a child logger of the default channel with the given name.
+func (m *LoggerManager) Named(name string) logger.Logger {
+	m.init()
+	return Named(m.Logger, name)
+}
+
+// AtomicLevel returns the atomic level of the default channel.
+func (m *LoggerManager) AtomicLevel() AtomicLevel {
+	m.init()
+	return GetAtomicLevel(m.Logger)
+}
+
+// NameLevels returns the level rule table by logger name of the default channel.
+func (m *LoggerManager) NameLevels() NameLevels {
+	m.init()
+	return GetNameLevels(m.Logger)
+}
+
+// Reopen reopens the handlers of all channels, errors are joined.
+// Channels which have not been opened yet are skipped.
+func (m *LoggerManager) Reopen() error {
+	channels := m.GetChannels()
+	var errs []error
+	for _, name := range sortedKeys(channels) {
+		if err := Reopen(channels[name]); err != nil {
+			errs = append(errs, fmt.Errorf("channel [%s]: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Sync flushes the handlers of all channels, errors are joined.
+// Channels which have not been opened yet are skipped.
+func (m *LoggerManager) Sync() error {
+	channels := m.GetChannels()
+	var errs []error
+	for _, name := range sortedKeys(channels) {
+		if err := Sync(channels[name]); err != nil {
+			errs = append(errs, fmt.Errorf("channel [%s]: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes the handlers of all channels, errors are joined.
+// A logger registered as several channels is closed once,
+// and handlers shared by derived loggers are closed once by the drivers, see [SharedHandler].
+// Channels which have not been opened yet are skipped.
+func (m *LoggerManager) Close() error {
+	channels := m.GetChannels()
+	var errs []error
+	for _, name := range uniqueChannels(channels) {
+		if err := Close(channels[name]); err != nil {
+			errs = append(errs, fmt.Errorf("channel [%s]: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Shutdown drains and closes the handlers of all channels concurrently,
+// waiting for buffered and in-flight writes until ctx is done.
+// It returns the sorted names of the channels which failed to flush before the deadline or failed to close,
+// and their errors joined.
+// Channels are closed once as [LoggerManager.Close] does.
+func (m *LoggerManager) Shutdown(ctx context.Context) ([]string, error) {
+	channels := m.GetChannels()
+	names := uniqueChannels(channels)
+	errs := make([]error, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := Shutdown(ctx, channels[name]); err != nil {
+				errs[i] = fmt.Errorf("channel [%s]: %w", name, err)
+			}
+		}()
+	}
+	wg.Wait()
+	var failed []string
+	for i, name := range names {
+		if errs[i] != nil {
+			failed = append(failed, name)
+		}
+	}
+	return failed, errors.Join(errs...)
+}
+
+// uniqueChannels returns the sorted channel names, skipping the names of a logger registered before.
+func uniqueChannels(channels map[string]logger.Logger) []string {
+	seen := make(map[logger.Logger]struct{}, len(channels))
+	var names []string
+	for _, name := range sortedKeys(channels) {
+		channel := channels[name]
+		if reflect.TypeOf(channel).Comparable() {
+			if _, ok := seen[channel]; ok {
+				continue
+			}
+			seen[channel] = struct{}{}
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
 func (m *LoggerManager) Debug(message string) {
 	m.init()
 	m.Logger.Debug(message)
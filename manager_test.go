@@ -0,0 +1,257 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/gopi-frame/contract/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockLevel struct {
+	level Level
+	// stamp records the changes of the level if it is not nil.
+	stamp *LevelStamp
+}
+
+func (m *mockLevel) Level() Level { return m.level }
+
+func (m *mockLevel) SetLevel(level Level) {
+	m.level = level
+	if m.stamp != nil {
+		m.stamp.Touch()
+	}
+}
+
+type mockLogger struct {
+	options  map[string]any
+	level    *mockLevel
+	levels   NameLevels
+	name     string
+	fields   []Field
+	messages []string
+	// reopened counts the calls of Reopen, which returns reopenErr.
+	reopened  int
+	reopenErr error
+	// synced and closed count the calls of Sync and Close, which return closeErr.
+	synced   int
+	closed   int
+	closeErr error
+	// closing blocks Close until it is closed if it is not nil.
+	closing chan struct{}
+}
+
+func (m *mockLogger) Sync() error {
+	m.synced++
+	return m.closeErr
+}
+
+func (m *mockLogger) Close() error {
+	if m.closing != nil {
+		<-m.closing
+	}
+	m.closed++
+	return m.closeErr
+}
+
+func (m *mockLogger) Reopen() error {
+	m.reopened++
+	return m.reopenErr
+}
+
+func (m *mockLogger) AtomicLevel() AtomicLevel {
+	if m.level == nil {
+		return nil
+	}
+	return m.level
+}
+
+func (m *mockLogger) NameLevels() NameLevels {
+	return m.levels
+}
+
+func (m *mockLogger) Named(name string) logger.Logger {
+	if m.name != "" {
+		name = m.name + "." + name
+	}
+	return &mockLogger{options: m.options, name: name, fields: m.fields}
+}
+
+func (m *mockLogger) With(fields ...Field) logger.Logger {
+	return &mockLogger{options: m.options, name: m.name, fields: append(append([]Field{}, m.fields...), fields...)}
+}
+
+func (m *mockLogger) WithFields(fields map[string]any) logger.Logger {
+	return m.With(Fields(fields)...)
+}
+
+func (m *mockLogger) WithLevel(level logger.Level) logger.Logger    { return m }
+func (m *mockLogger) WithContext(ctx context.Context) logger.Logger { return m }
+func (m *mockLogger) Debug(message string)                          { m.messages = append(m.messages, message) }
+func (m *mockLogger) Debugf(format string, args ...any)             {}
+func (m *mockLogger) Info(message string)                           { m.messages = append(m.messages, message) }
+func (m *mockLogger) Infof(format string, args ...any)              {}
+func (m *mockLogger) Warn(message string)                           { m.messages = append(m.messages, message) }
+func (m *mockLogger) Warnf(format string, args ...any)              {}
+func (m *mockLogger) Error(message string)                          { m.messages = append(m.messages, message) }
+func (m *mockLogger) Errorf(format string, args ...any)             {}
+func (m *mockLogger) Panic(message string)                          { m.messages = append(m.messages, message) }
+func (m *mockLogger) Panicf(format string, args ...any)             {}
+func (m *mockLogger) Fatal(message string)                          { m.messages = append(m.messages, message) }
+func (m *mockLogger) Fatalf(format string, args ...any)             {}
+
+type mockDriver struct {
+	opened int
+}
+
+func (d *mockDriver) Open(options map[string]any) (logger.Logger, error) {
+	d.opened++
+	return &mockLogger{options: options}, nil
+}
+
+func (d *mockDriver) Validate(options map[string]any) error {
+	if level, ok := options["level"].(string); ok {
+		var l Level
+		if err := l.UnmarshalText([]byte(level)); err != nil {
+			return err
+		}
+	}
+	if handler, ok := options["handler"].(string); ok {
+		return ValidateHandler(handler, nil)
+	}
+	return nil
+}
+
+var driver = new(mockDriver)
+
+func init() {
+	Register("mock", driver)
+}
+
+func TestNewLoggerManagerFromConfig(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		driver.opened = 0
+		m, err := NewLoggerManagerFromConfig(map[string]any{
+			"default": "app",
+			"channels": map[string]any{
+				"app": map[string]any{
+					"driver":  "mock",
+					"options": map[string]any{"level": "info"},
+				},
+				"audit": map[string]any{
+					"driver": "mock",
+				},
+			},
+		})
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		assert.True(t, m.HasChannel("app"))
+		assert.True(t, m.HasChannel("audit"))
+		assert.Equal(t, 0, driver.opened)
+		m.Info("info")
+		assert.Equal(t, 1, driver.opened)
+		app := m.GetChannel("app").(*DeferLogger).Logger.(*mockLogger)
+		assert.Equal(t, []string{"info"}, app.messages)
+		assert.Equal(t, map[string]any{"level": "info"}, app.options)
+	})
+
+	t.Run("concurrent first use", func(t *testing.T) {
+		driver.opened = 0
+		m, err := NewLoggerManagerFromConfig(map[string]any{
+			"default":  "app",
+			"channels": map[string]any{"app": map[string]any{"driver": "mock"}},
+		})
+		if !assert.NoError(t, err) {
+			assert.FailNow(t, err.Error())
+		}
+		channel := m.GetChannel("app").(*DeferLogger)
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				channel.Named("worker")
+			}()
+			// the admin handler, the signals and Shutdown check the channel concurrently
+			go func() {
+				defer wg.Done()
+				_ = Opened(channel)
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, 1, driver.opened)
+		assert.True(t, channel.Opened())
+	})
+
+	t.Run("invalid channels", func(t *testing.T) {
+		driver.opened = 0
+		_, err := NewLoggerManagerFromConfig(map[string]any{
+			"default": "missing",
+			"channels": map[string]any{
+				"app": map[string]any{
+					"driver": "mock",
+				},
+				"no-driver": map[string]any{
+					"options": map[string]any{},
+				},
+				"unknown-driver": map[string]any{
+					"driver": "unknown",
+				},
+				"invalid-level": map[string]any{
+					"driver":  "mock",
+					"options": map[string]any{"level": "verbose"},
+				},
+				"unknown-handler": map[string]any{
+					"driver":  "mock",
+					"options": map[string]any{"handler": "unknown"},
+				},
+				"empty": nil,
+			},
+		})
+		if !assert.Error(t, err) {
+			assert.FailNow(t, "expected error")
+		}
+		var invalidChannel *InvalidChannelException
+		assert.True(t, errors.As(err, &invalidChannel))
+		var notConfigured *NotConfiguredChannelException
+		assert.True(t, errors.As(err, &notConfigured))
+		assert.Contains(t, err.Error(), "channel [no-driver] is invalid: driver is missing")
+		assert.Contains(t, err.Error(), "channel [unknown-driver] is invalid: unknown driver [unknown]")
+		assert.Contains(t, err.Error(), "channel [empty] is invalid: configuration is empty")
+		assert.Contains(t, err.Error(), "channel [invalid-level] is invalid: ")
+		assert.Contains(t, err.Error(), "channel [unknown-handler] is invalid: unknown handler [unknown]")
+		assert.Contains(t, err.Error(), "channel [missing] not configured")
+		assert.NotContains(t, err.Error(), "channel [app]")
+		assert.Equal(t, 0, driver.opened)
+	})
+
+	t.Run("missing default", func(t *testing.T) {
+		_, err := NewLoggerManagerFromConfig(map[string]any{
+			"channels": map[string]any{"app": map[string]any{"driver": "mock"}},
+		})
+		var missingDefault *MissingDefaultChannelException
+		assert.True(t, errors.As(err, &missingDefault))
+		m, err := NewLoggerManagerFromConfig(map[string]any{})
+		assert.NoError(t, err)
+		assert.NotNil(t, m)
+	})
+}
+
+func TestLoggerManager_GetChannelLevel(t *testing.T) {
+	m := NewLoggerManager()
+	m.SetDefault("app")
+	m.SetChannel("app", &mockLogger{level: &mockLevel{level: LevelInfo}})
+	m.SetChannel("plain", &mockLogger{})
+	level := m.GetChannelLevel("app")
+	if !assert.NotNil(t, level) {
+		assert.FailNow(t, "level is nil")
+	}
+	assert.Equal(t, LevelInfo, level.Level())
+	m.AtomicLevel().SetLevel(LevelDebug)
+	assert.Equal(t, LevelDebug, level.Level())
+	assert.Nil(t, m.GetChannelLevel("plain"))
+	assert.Nil(t, m.GetChannelLevel("missing"))
+}
@@ -0,0 +1,23 @@
+package logger
+
+import (
+	"github.com/gopi-frame/contract/logger"
+)
+
+// NamedLogger is a logger which supports hierarchical names.
+type NamedLogger interface {
+	logger.Logger
+
+	// Named returns a child logger with the given name appended to the logger's name.
+	// Names are joined by dots, e.g. log.Named("billing").Named("invoice") is named "billing.invoice".
+	Named(name string) logger.Logger
+}
+
+// Named returns a child logger of l with the given name appended.
+// If l does not implement [NamedLogger], l is returned unchanged.
+func Named(l logger.Logger, name string) logger.Logger {
+	if nl, ok := l.(NamedLogger); ok {
+		return nl.Named(name)
+	}
+	return l
+}
@@ -0,0 +1,39 @@
+package logger
+
+import (
+	"io"
+
+	"github.com/gopi-frame/contract/logger"
+)
+
+// Reopener is a handler whose underlying file can be reopened,
+// e.g. after the file has been moved by an external log rotation tool like logrotate.
+type Reopener interface {
+	// Reopen closes the current file and opens it again by its name.
+	Reopen() error
+}
+
+// ReopenHandler reopens w if it implements [Reopener], otherwise it does nothing.
+func ReopenHandler(w io.Writer) error {
+	if r, ok := w.(Reopener); ok {
+		return r.Reopen()
+	}
+	return nil
+}
+
+// ReopenableLogger is a logger whose handlers can be reopened.
+type ReopenableLogger interface {
+	logger.Logger
+
+	// Reopen reopens the handlers of the logger, see [Reopener].
+	Reopen() error
+}
+
+// Reopen reopens the handlers of l.
+// If l does not implement [ReopenableLogger], it does nothing.
+func Reopen(l logger.Logger) error {
+	if rl, ok := l.(ReopenableLogger); ok {
+		return rl.Reopen()
+	}
+	return nil
+}
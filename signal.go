@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"reflect"
+
+	"github.com/gopi-frame/contract/logger"
+)
+
+// stepLevels changes the level of every channel of m supporting [AtomicLevel] by delta steps,
+// a negative delta increases the verbosity. The level is kept between [LevelDebug] and [LevelFatal].
+// The level rules by logger name in effect are stepped as well, so they keep overriding the level,
+// while the rules overridden by a later change of the level are left alone, see [LevelRules].
+// Channels which have not been opened yet are skipped, and the channels of a [StackLogger]
+// are stepped once even if they are registered as channels too.
+func stepLevels(m *LoggerManager, delta int) {
+	for _, channel := range leafChannels(m.GetChannels()) {
+		levels := GetNameLevels(channel)
+		var rules map[string]Level
+		if active, ok := levels.(interface{ activeLevels() map[string]Level }); ok {
+			rules = active.activeLevels()
+		}
+		if level := GetAtomicLevel(channel); level != nil {
+			level.SetLevel(stepLevel(level.Level(), delta))
+		}
+		// the rules are set after the level, so they keep overriding it
+		for pattern, level := range rules {
+			levels.SetLevel(pattern, stepLevel(level, delta))
+		}
+	}
+}
+
+// stepLevel returns the level delta steps from level, kept between [LevelDebug] and [LevelFatal].
+func stepLevel(level Level, delta int) Level {
+	return Level(min(max(int(level)+delta, int(LevelDebug)), int(LevelFatal)))
+}
+
+// leafChannels returns the opened channels, with the channels of the stack loggers flattened
+// and the loggers registered several times returned once.
+func leafChannels(channels map[string]logger.Logger) []logger.Logger {
+	var leaves []logger.Logger
+	seen := make(map[logger.Logger]struct{})
+	var visit func(channel logger.Logger)
+	visit = func(channel logger.Logger) {
+		switch l := channel.(type) {
+		case *StackLogger:
+			for _, c := range l.channels {
+				visit(c)
+			}
+			return
+		case *DeferLogger:
+			if !l.Opened() {
+				return
+			}
+			visit(l.Logger)
+			return
+		}
+		if reflect.TypeOf(channel).Comparable() {
+			if _, ok := seen[channel]; ok {
+				return
+			}
+			seen[channel] = struct{}{}
+		}
+		leaves = append(leaves, channel)
+	}
+	for _, name := range uniqueChannels(channels) {
+		visit(channels[name])
+	}
+	return leaves
+}
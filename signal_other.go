@@ -0,0 +1,8 @@
+//go:build !unix
+
+package logger
+
+// HandleSignals does nothing on platforms without SIGUSR1, SIGUSR2 and SIGHUP.
+func HandleSignals(*LoggerManager, func(error)) (stop func()) {
+	return func() {}
+}
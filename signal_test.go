@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStepLevels(t *testing.T) {
+	app := &mockLogger{level: &mockLevel{level: LevelInfo}}
+	audit := &mockLogger{level: &mockLevel{level: LevelFatal}}
+	m := NewLoggerManager()
+	m.SetChannel("app", app)
+	m.SetChannel("audit", audit)
+	m.SetChannel("plain", &mockLogger{})
+
+	stepLevels(m, -1)
+	assert.Equal(t, LevelDebug, app.level.Level())
+	assert.Equal(t, LevelPanic, audit.level.Level())
+
+	stepLevels(m, -1)
+	assert.Equal(t, LevelDebug, app.level.Level())
+
+	stepLevels(m, 2)
+	assert.Equal(t, LevelWarn, app.level.Level())
+	assert.Equal(t, LevelFatal, audit.level.Level())
+}
+
+func TestStepLevels_Rules(t *testing.T) {
+	identity := func(level Level) Level { return level }
+	newLogger := func(level Level, rules map[string]Level) *mockLogger {
+		stamp := new(LevelStamp)
+		return &mockLogger{
+			level: &mockLevel{level: level, stamp: stamp},
+			levels: NewNameLevels(NewLevelRules(rules), stamp,
+				func(level Level) (Level, bool) { return level, true }, identity),
+		}
+	}
+	app := newLogger(LevelInfo, map[string]Level{"*": LevelWarn})
+	audit := newLogger(LevelInfo, map[string]Level{"*": LevelWarn, "gorm": LevelError})
+	// a channel with an unknown driver panics when it is opened
+	lazy := NewDeferLogger("missing", nil)
+	m := NewLoggerManager()
+	m.SetChannel("app", app)
+	m.SetChannel("audit", audit)
+	m.SetChannel("lazy", lazy)
+	m.SetChannel("stack", NewStackLogger(app, audit, lazy))
+
+	stepLevels(m, 1)
+	assert.Equal(t, LevelWarn, app.level.Level(), "stepped once")
+	assert.Equal(t, map[string]Level{"*": LevelError}, app.levels.Levels())
+	level, ok := app.levels.Match("http")
+	assert.True(t, ok, "the rule keeps overriding the level")
+	assert.Equal(t, LevelError, level)
+	assert.False(t, lazy.Opened())
+
+	// the level set after the rules overrides them, only the rule set later is stepped
+	audit.level.SetLevel(LevelDebug)
+	audit.levels.SetLevel("gorm", LevelError)
+	stepLevels(m, -1)
+	assert.Equal(t, LevelDebug, audit.level.Level())
+	assert.Equal(t, map[string]Level{"*": LevelError, "gorm": LevelWarn}, audit.levels.Levels())
+	_, ok = audit.levels.Match("http")
+	assert.False(t, ok)
+	level, _ = audit.levels.Match("gorm")
+	assert.Equal(t, LevelWarn, level)
+}
+
+func TestLoggerManager_Reopen(t *testing.T) {
+	app := &mockLogger{}
+	audit := &mockLogger{reopenErr: errors.New("permission denied")}
+	m := NewLoggerManager()
+	m.SetChannel("app", app)
+	m.SetChannel("audit", audit)
+	m.SetChannel("stack", NewStackLogger(app, audit))
+	m.SetChannel("deferred", NewDeferLogger("mock", nil))
+
+	err := m.Reopen()
+	assert.EqualError(t, err, "channel [audit]: permission denied\nchannel [stack]: permission denied")
+	assert.Equal(t, 2, app.reopened)
+	assert.Equal(t, 2, audit.reopened)
+	assert.Nil(t, m.GetChannel("deferred").(*DeferLogger).Logger)
+}
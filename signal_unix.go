@@ -0,0 +1,55 @@
+//go:build unix
+
+package logger
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// HandleSignals installs signal handlers for m, it is opt-in and lasts until stop is called:
+//   - SIGUSR1 increases the verbosity of every channel by one level, e.g. from info to debug.
+//   - SIGUSR2 decreases the verbosity of every channel by one level, e.g. from info to warn.
+//   - SIGHUP reopens the handlers of every channel, see [LoggerManager.Reopen].
+//
+// The level rules by logger name in effect are stepped with the level of their channel.
+// Reopen errors are passed to onError if it is not nil.
+// Only channels supporting [AtomicLevel] and [ReopenableLogger] are affected,
+// and channels which have not been opened yet are skipped.
+// On platforms without these signals, HandleSignals does nothing.
+func HandleSignals(m *LoggerManager, onError func(error)) (stop func()) {
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2, syscall.SIGHUP)
+	go func() {
+		defer close(exited)
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-signals:
+				switch sig {
+				case syscall.SIGUSR1:
+					stepLevels(m, -1)
+				case syscall.SIGUSR2:
+					stepLevels(m, 1)
+				case syscall.SIGHUP:
+					if err := m.Reopen(); err != nil && onError != nil {
+						onError(err)
+					}
+				}
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+			<-exited
+		})
+	}
+}
@@ -0,0 +1,37 @@
+//go:build unix
+
+package logger
+
+import (
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleSignals(t *testing.T) {
+	app := &mockLogger{reopenErr: errors.New("permission denied")}
+	m := NewLoggerManager()
+	m.SetChannel("app", app)
+	errs := make(chan error, 1)
+	stop := HandleSignals(m, func(err error) {
+		errs <- err
+	})
+	defer stop()
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGHUP); err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	select {
+	case err := <-errs:
+		assert.EqualError(t, err, "channel [app]: permission denied")
+		assert.Equal(t, 1, app.reopened)
+	case <-time.After(time.Second):
+		assert.FailNow(t, "SIGHUP is not handled")
+	}
+
+	stop()
+	stop()
+}
@@ -2,6 +2,8 @@ package logger
 
 import (
 	"context"
+	"errors"
+	"sync"
 
 	"github.com/gopi-frame/contract/logger"
 )
@@ -30,6 +32,110 @@ func (s *StackLogger) WithContext(ctx context.Context) logger.Logger {
 	return l
 }
 
+// With returns a new stack logger with the given fields attached to every channel.
+func (s *StackLogger) With(fields ...Field) logger.Logger {
+	l := &StackLogger{}
+	for _, channel := range s.channels {
+		l.channels = append(l.channels, With(channel, fields...))
+	}
+	return l
+}
+
+// WithFields returns a new stack logger with the given fields attached to every channel.
+func (s *StackLogger) WithFields(fields map[string]any) logger.Logger {
+	return s.With(Fields(fields)...)
+}
+
+// Named returns a new stack logger with the given name appended to every channel.
+func (s *StackLogger) Named(name string) logger.Logger {
+	l := &StackLogger{}
+	for _, channel := range s.channels {
+		l.channels = append(l.channels, Named(channel, name))
+	}
+	return l
+}
+
+// AtomicLevel returns the atomic level of the stack logger.
+// Setting the level changes the level of every channel supporting [AtomicLevel],
+// and the reported level is the lowest level of them.
+// It returns nil if no channel supports [AtomicLevel].
+func (s *StackLogger) AtomicLevel() AtomicLevel {
+	var levels stackLevel
+	for _, channel := range s.channels {
+		if level := GetAtomicLevel(channel); level != nil {
+			levels = append(levels, level)
+		}
+	}
+	if len(levels) == 0 {
+		return nil
+	}
+	return levels
+}
+
+// NameLevels returns the level rule table of the stack logger.
+// Setting a rule changes the rules of every channel supporting [NameLevels].
+// It returns nil if no channel supports [NameLevels].
+func (s *StackLogger) NameLevels() NameLevels {
+	var levels stackNameLevels
+	for _, channel := range s.channels {
+		if level := GetNameLevels(channel); level != nil {
+			levels = append(levels, level)
+		}
+	}
+	if len(levels) == 0 {
+		return nil
+	}
+	return levels
+}
+
+// Reopen reopens the handlers of every channel, errors are joined.
+func (s *StackLogger) Reopen() error {
+	var errs []error
+	for _, channel := range s.channels {
+		if err := Reopen(channel); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Sync flushes the handlers of every channel, errors are joined.
+func (s *StackLogger) Sync() error {
+	var errs []error
+	for _, channel := range s.channels {
+		if err := Sync(channel); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes the handlers of every channel, errors are joined.
+func (s *StackLogger) Close() error {
+	var errs []error
+	for _, channel := range s.channels {
+		if err := Close(channel); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Shutdown shuts down every channel concurrently, waiting until ctx is done, errors are joined.
+func (s *StackLogger) Shutdown(ctx context.Context) error {
+	errs := make([]error, len(s.channels))
+	var wg sync.WaitGroup
+	for i, channel := range s.channels {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = Shutdown(ctx, channel)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
 func (s *StackLogger) Debug(message string) {
 	for _, channel := range s.channels {
 		channel.Debug(message)
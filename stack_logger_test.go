@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStackLogger_With(t *testing.T) {
+	first, second := new(mockLogger), new(mockLogger)
+	stack := NewStackLogger(first, second)
+	child := stack.With(Any("user", "alice")).(*StackLogger).WithFields(map[string]any{"id": 1, "order": "A1"}).(*StackLogger)
+	if !assert.Len(t, child.channels, 2) {
+		assert.FailNow(t, "unexpected channels")
+	}
+	for _, channel := range child.channels {
+		assert.Equal(t, []Field{Any("user", "alice"), Any("id", 1), Any("order", "A1")}, channel.(*mockLogger).fields)
+	}
+	assert.Empty(t, first.fields)
+	assert.Empty(t, second.fields)
+}
+
+func TestStackLogger_Named(t *testing.T) {
+	first, second := new(mockLogger), new(mockLogger)
+	child := NewStackLogger(first, second).Named("billing").(*StackLogger).Named("invoice").(*StackLogger)
+	if !assert.Len(t, child.channels, 2) {
+		assert.FailNow(t, "unexpected channels")
+	}
+	for _, channel := range child.channels {
+		assert.Equal(t, "billing.invoice", channel.(*mockLogger).name)
+	}
+	assert.Empty(t, first.name)
+	assert.Empty(t, second.name)
+}
+
+func TestStackLogger_AtomicLevel(t *testing.T) {
+	first := &mockLogger{level: &mockLevel{level: LevelWarn}}
+	second := &mockLogger{level: &mockLevel{level: LevelInfo}}
+	stack := NewStackLogger(first, second, new(mockLogger))
+	level := stack.AtomicLevel()
+	assert.Equal(t, LevelInfo, level.Level())
+	level.SetLevel(LevelError)
+	assert.Equal(t, LevelError, first.level.Level())
+	assert.Equal(t, LevelError, second.level.Level())
+	assert.Nil(t, NewStackLogger(new(mockLogger)).AtomicLevel())
+}